@@ -11,10 +11,10 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
-	"github.com/zgpcy/azure-cost-exporter/internal/azure"
 	"github.com/zgpcy/azure-cost-exporter/internal/collector"
 	"github.com/zgpcy/azure-cost-exporter/internal/config"
 	"github.com/zgpcy/azure-cost-exporter/internal/logger"
+	"github.com/zgpcy/azure-cost-exporter/internal/registry"
 	"github.com/zgpcy/azure-cost-exporter/internal/server"
 )
 
@@ -49,32 +49,57 @@ func main() {
 	}
 
 	logger.Info("Configuration loaded successfully",
+		"providers", len(cfg.Providers),
 		"subscriptions", len(cfg.Subscriptions),
+		"scopes", len(cfg.Scopes),
+		"discovery_enabled", cfg.Discovery.Enabled,
+		"tenants", len(cfg.TenantList()),
+		"usage_enabled", cfg.Usage.Enabled,
+		"month_totals_enabled", cfg.MonthTotals.Enabled,
+		"forecast_enabled", cfg.Forecast.Enabled,
+		"budgets_enabled", cfg.Budgets.Enabled,
+		"utilization_enabled", cfg.Utilization.Enabled,
+		"cloud", cfg.Cloud.Name,
 		"refresh_interval_seconds", cfg.RefreshInterval,
 		"http_port", cfg.HTTPPort,
 		"days_to_query", cfg.DateRange.DaysToQuery,
 		"end_date_offset", endDateOffset,
 		"currency", cfg.Currency,
+		"cost_type", cfg.CostType,
 		"grouping_enabled", cfg.GroupBy.Enabled,
-		"api_timeout_seconds", cfg.APITimeout)
+		"api_timeout_seconds", cfg.APITimeout,
+		"max_query_pages", cfg.MaxQueryPages,
+		"max_concurrent_queries", cfg.MaxConcurrentQueries)
 
 	if cfg.GroupBy.Enabled {
 		logger.Info("Grouping configuration",
 			"dimensions", len(cfg.GroupBy.Groups))
 	}
 
-	// Create Azure client
-	logger.Info("Initializing Azure Cost Management client")
-	azureClient, err := azure.NewClient(cfg, logger)
+	// Create the configured cost providers
+	logger.Info("Initializing cost providers", "providers", len(cfg.Providers))
+	providers, err := registry.New(context.Background(), cfg, logger)
 	if err != nil {
-		logger.Error("Failed to create Azure client", "error", err)
+		logger.Error("Failed to create cost providers", "error", err)
 		os.Exit(1)
 	}
-	logger.Info("Azure client initialized successfully")
+
+	schedules := make([]collector.ProviderSchedule, len(providers))
+	for i, p := range providers {
+		interval := cfg.ProviderRefreshInterval(p.Config)
+		schedules[i] = collector.ProviderSchedule{
+			Provider:        p.Provider,
+			RefreshInterval: time.Duration(interval) * time.Second,
+		}
+		logger.Info("Provider initialized successfully",
+			"provider", p.Provider.Name(),
+			"accounts", p.Provider.AccountCount(),
+			"refresh_interval_seconds", interval)
+	}
 
 	// Create cost collector
 	logger.Info("Creating Prometheus collector")
-	costCollector := collector.NewCostCollector(azureClient, cfg, logger)
+	costCollector := collector.NewMultiCostCollector(schedules, cfg, logger)
 
 	// Register collector with Prometheus
 	if err := prometheus.Register(costCollector); err != nil {
@@ -83,6 +108,56 @@ func main() {
 	}
 	logger.Info("Collector registered with Prometheus")
 
+	// Create budget collector (optional, refreshed on its own schedule)
+	// Budgets are read from the first provider that supports them
+	var budgetCollector *collector.BudgetCollector
+	if cfg.Budgets.Enabled {
+		for _, p := range providers {
+			if budgetCollector = collector.NewBudgetCollector(p.Provider, cfg, logger); budgetCollector != nil {
+				break
+			}
+		}
+		if budgetCollector == nil {
+			logger.Warn("Budgets are enabled but no configured provider supports them")
+		} else {
+			if err := prometheus.Register(budgetCollector); err != nil {
+				logger.Error("Failed to register budget collector", "error", err)
+				os.Exit(1)
+			}
+			logger.Info("Budget collector registered with Prometheus")
+		}
+	}
+
+	// Create commitment utilization collector (optional, refreshed on its own schedule)
+	// Utilization is read from the first provider that supports it
+	var utilizationCollector *collector.UtilizationCollector
+	if cfg.Utilization.Enabled {
+		for _, p := range providers {
+			if utilizationCollector = collector.NewUtilizationCollector(p.Provider, cfg, logger); utilizationCollector != nil {
+				break
+			}
+		}
+		if utilizationCollector == nil {
+			logger.Warn("Utilization is enabled but no configured provider supports it")
+		} else {
+			if err := prometheus.Register(utilizationCollector); err != nil {
+				logger.Error("Failed to register utilization collector", "error", err)
+				os.Exit(1)
+			}
+			logger.Info("Utilization collector registered with Prometheus")
+		}
+	}
+
+	// Register provider client metrics (e.g. Azure query pagination)
+	for _, p := range providers {
+		if c, ok := p.Provider.(prometheus.Collector); ok {
+			if err := prometheus.Register(c); err != nil {
+				logger.Error("Failed to register provider client metrics", "provider", p.Provider.Name(), "error", err)
+				os.Exit(1)
+			}
+		}
+	}
+
 	// Register Go runtime metrics (memory, goroutines, GC stats)
 	if err := prometheus.Register(collectors.NewGoCollector()); err != nil {
 		logger.Warn("Failed to register Go collector", "error", err)
@@ -103,6 +178,12 @@ func main() {
 	// Start background refresh
 	logger.Info("Starting background cost data refresh")
 	costCollector.StartBackgroundRefresh(ctx)
+	if budgetCollector != nil {
+		budgetCollector.StartBackgroundRefresh(ctx)
+	}
+	if utilizationCollector != nil {
+		utilizationCollector.StartBackgroundRefresh(ctx)
+	}
 
 	// Create and start HTTP server
 	logger.Info("Creating HTTP server", "port", cfg.HTTPPort)
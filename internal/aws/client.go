@@ -0,0 +1,362 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/zgpcy/azure-cost-exporter/internal/clock"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/logger"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// Cost Explorer metrics queried per cost type
+var costMetrics = map[string]string{
+	config.CostTypeActual:    "UnblendedCost",
+	config.CostTypeAmortized: "AmortizedCost",
+}
+
+// costExplorerAPI is the subset of the Cost Explorer client used by Client
+type costExplorerAPI interface {
+	GetCostAndUsage(ctx context.Context, params *costexplorer.GetCostAndUsageInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostAndUsageOutput, error)
+}
+
+// Client queries AWS Cost Explorer and implements provider.CloudProvider
+type Client struct {
+	api    costExplorerAPI
+	cfg    *config.Config // Date range, API timeout and page cap
+	awsCfg config.AWSConfig
+	logger *logger.Logger
+	clock  clock.Clock // Time provider for testing
+}
+
+// Verify that Client implements provider.CloudProvider
+var _ provider.CloudProvider = (*Client)(nil)
+
+// NewClient creates a Cost Explorer client
+// Credentials are resolved by the SDK default chain (or the configured profile) and
+// exchanged for the configured role, if any. Retries are handled by the client itself,
+// so the SDK retryer is disabled
+func NewClient(ctx context.Context, cfg *config.Config, awsCfg config.AWSConfig, log *logger.Logger) (*Client, error) {
+	opts := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithRegion(awsCfg.Region),
+		awsconfig.WithRetryer(func() awssdk.Retryer { return awssdk.NopRetryer{} }),
+	}
+	if awsCfg.Profile != "" {
+		opts = append(opts, awsconfig.WithSharedConfigProfile(awsCfg.Profile))
+	}
+
+	sdkCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	if awsCfg.RoleARN != "" {
+		sdkCfg.Credentials = awssdk.NewCredentialsCache(stscreds.NewAssumeRoleProvider(sts.NewFromConfig(sdkCfg), awsCfg.RoleARN))
+	}
+
+	log.Info("Using AWS credentials", describeAuth(awsCfg)...)
+	return newClient(cfg, awsCfg, log, sdkCfg), nil
+}
+
+// newClient creates a client from a resolved SDK configuration
+func newClient(cfg *config.Config, awsCfg config.AWSConfig, log *logger.Logger, sdkCfg awssdk.Config) *Client {
+	api := costexplorer.NewFromConfig(sdkCfg, func(o *costexplorer.Options) {
+		if awsCfg.Endpoint != "" {
+			o.BaseEndpoint = awssdk.String(awsCfg.Endpoint)
+		}
+	})
+
+	return &Client{
+		api:    api,
+		cfg:    cfg,
+		awsCfg: awsCfg,
+		logger: log,
+		clock:  clock.RealClock{},
+	}
+}
+
+// describeAuth returns log attributes identifying the credential source
+func describeAuth(awsCfg config.AWSConfig) []any {
+	attrs := []any{"region", awsCfg.Region}
+	if awsCfg.Profile != "" {
+		attrs = append(attrs, "profile", awsCfg.Profile)
+	}
+	if awsCfg.RoleARN != "" {
+		attrs = append(attrs, "role_arn", awsCfg.RoleARN)
+	}
+	if awsCfg.Endpoint != "" {
+		attrs = append(attrs, "endpoint", awsCfg.Endpoint)
+	}
+	return attrs
+}
+
+// Name returns the provider type
+func (c *Client) Name() provider.ProviderType {
+	return provider.ProviderAWS
+}
+
+// AccountCount returns the number of queried accounts
+// All member accounts are covered by the single query of the configured account
+func (c *Client) AccountCount() int {
+	return 1
+}
+
+// QueryCosts retrieves the daily costs of the configured date range
+// The result carries a single AccountResult for the queried account
+func (c *Client) QueryCosts(ctx context.Context) (provider.QueryResult, error) {
+	account := provider.AccountResult{
+		AccountID:   c.awsCfg.AccountID,
+		AccountName: c.awsCfg.AccountName,
+	}
+
+	start := time.Now()
+	records, retries, err := c.queryCosts(ctx)
+	account.Duration = time.Since(start)
+	account.Retries = retries
+	account.Rows = len(records)
+	account.Err = err
+
+	if err != nil {
+		c.logger.Warn("Failed to query AWS Cost Explorer",
+			"account_name", account.AccountName,
+			"account_id", account.AccountID,
+			"reason", provider.ErrorReason(err),
+			"error", err)
+		return provider.QueryResult{Accounts: []provider.AccountResult{account}}, fmt.Errorf("cost explorer query failed (check AWS credentials and ce:GetCostAndUsage permission): %w", err)
+	}
+
+	return provider.QueryResult{Records: records, Accounts: []provider.AccountResult{account}}, nil
+}
+
+// queryCosts queries all result pages, retrying each page on its own
+// Returns the records and the number of retried API calls
+func (c *Client) queryCosts(ctx context.Context) ([]provider.CostRecord, int, error) {
+	startDate, endDate := c.cfg.DateRange.Days(c.clock.Now())
+	input := c.costInput(startDate, endDate)
+
+	c.logger.Debug("Querying AWS Cost Explorer",
+		"account_name", c.awsCfg.AccountName,
+		"group_by", strings.Join(c.awsCfg.GroupBy, ","),
+		"cost_type", c.awsCfg.CostType,
+		"start_date", startDate.Format(time.DateOnly),
+		"end_date", endDate.Format(time.DateOnly))
+
+	var (
+		records []provider.CostRecord
+		retries int
+		pages   int
+	)
+	for {
+		if pages >= c.maxQueryPages() {
+			c.logger.Warn("Query page limit reached, cost data is truncated",
+				"account_name", c.awsCfg.AccountName,
+				"account_id", c.awsCfg.AccountID,
+				"pages", pages,
+				"max_query_pages", c.maxQueryPages())
+			break
+		}
+
+		var out *costexplorer.GetCostAndUsageOutput
+		err := c.retryQuery(ctx, &retries, func() error {
+			var err error
+			out, err = c.getCostAndUsage(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, retries, fmt.Errorf("cost query for %s to %s failed on page %d: %w",
+				startDate.Format(time.DateOnly), endDate.Format(time.DateOnly), pages+1, err)
+		}
+		pages++
+
+		records = append(records, c.parseOutput(out)...)
+
+		if out.NextPageToken == nil || *out.NextPageToken == "" {
+			break
+		}
+		input.NextPageToken = out.NextPageToken
+	}
+
+	c.logger.Debug("AWS Cost Explorer query completed", "pages", pages, "records", len(records))
+	return records, retries, nil
+}
+
+// getCostAndUsage performs a single API call bounded by the API timeout
+func (c *Client) getCostAndUsage(ctx context.Context, input *costexplorer.GetCostAndUsageInput) (*costexplorer.GetCostAndUsageOutput, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.cfg.APITimeout)*time.Second)
+	defer cancel()
+	return c.api.GetCostAndUsage(ctx, input)
+}
+
+// retryQuery runs a single API call with exponential backoff, counting retried calls in retries
+// Errors that retrying can't fix end the retries immediately
+func (c *Client) retryQuery(ctx context.Context, retries *int, query func() error) error {
+	retrier := provider.Retrier{
+		Classify: classifyError,
+		OnRetry: func(err *provider.Error, _ time.Duration) {
+			c.logger.Debug("AWS API call failed, will retry",
+				"account_name", c.awsCfg.AccountName,
+				"reason", err.Reason(),
+				"error", err.Err)
+		},
+	}
+	return retrier.Retry(ctx, retries, query)
+}
+
+// costInput builds the GetCostAndUsage request for a date range
+// Cost Explorer treats the end date as exclusive, so the day after endDate is sent
+func (c *Client) costInput(startDate, endDate time.Time) *costexplorer.GetCostAndUsageInput {
+	metrics := make([]string, 0, len(costMetrics))
+	for _, costType := range c.awsCfg.CostTypes() {
+		metrics = append(metrics, costMetrics[costType])
+	}
+
+	groupBy := make([]types.GroupDefinition, 0, len(c.awsCfg.GroupBy))
+	for _, g := range c.awsCfg.GroupBy {
+		if key, ok := strings.CutPrefix(g, config.AWSTagPrefix); ok {
+			groupBy = append(groupBy, types.GroupDefinition{Type: types.GroupDefinitionTypeTag, Key: awssdk.String(key)})
+			continue
+		}
+		groupBy = append(groupBy, types.GroupDefinition{Type: types.GroupDefinitionTypeDimension, Key: awssdk.String(g)})
+	}
+
+	return &costexplorer.GetCostAndUsageInput{
+		Granularity: types.GranularityDaily,
+		Metrics:     metrics,
+		GroupBy:     groupBy,
+		TimePeriod: &types.DateInterval{
+			Start: awssdk.String(startDate.Format(time.DateOnly)),
+			End:   awssdk.String(endDate.AddDate(0, 0, 1).Format(time.DateOnly)),
+		},
+	}
+}
+
+// maxQueryPages returns the configured page cap, falling back to the default
+func (c *Client) maxQueryPages() int {
+	if c.cfg.MaxQueryPages > 0 {
+		return c.cfg.MaxQueryPages
+	}
+	return config.DefaultMaxQueryPages
+}
+
+// parseOutput converts a result page into cost records, one per day, group and cost type
+// Results without groups (no group_by) are read from the day's total
+func (c *Client) parseOutput(out *costexplorer.GetCostAndUsageOutput) []provider.CostRecord {
+	accountNames := make(map[string]string, len(out.DimensionValueAttributes))
+	for _, attr := range out.DimensionValueAttributes {
+		if attr.Value != nil && attr.Attributes["description"] != "" {
+			accountNames[*attr.Value] = attr.Attributes["description"]
+		}
+	}
+
+	var records []provider.CostRecord
+	for _, result := range out.ResultsByTime {
+		date := ""
+		if result.TimePeriod != nil && result.TimePeriod.Start != nil {
+			date = *result.TimePeriod.Start
+		}
+
+		if len(result.Groups) == 0 {
+			records = append(records, c.groupRecords(date, nil, result.Total, accountNames)...)
+			continue
+		}
+		for _, group := range result.Groups {
+			records = append(records, c.groupRecords(date, group.Keys, group.Metrics, accountNames)...)
+		}
+	}
+	return records
+}
+
+// groupRecords builds the records of a single group, one per queried cost type
+// keys holds one value per group_by entry, in configuration order
+func (c *Client) groupRecords(date string, keys []string, metrics map[string]types.MetricValue, accountNames map[string]string) []provider.CostRecord {
+	base := provider.CostRecord{
+		Date:        date,
+		Provider:    string(provider.ProviderAWS),
+		AccountID:   c.awsCfg.AccountID,
+		AccountName: c.awsCfg.AccountName,
+		Service:     "Unknown",
+	}
+	if tagKeys := c.awsCfg.TagKeys(); len(tagKeys) > 0 {
+		base.Tags = make(map[string]string, len(tagKeys))
+		for _, key := range tagKeys {
+			base.Tags[key] = ""
+		}
+	}
+
+	for i, g := range c.awsCfg.GroupBy {
+		if i >= len(keys) {
+			break
+		}
+		applyGroupKey(&base, g, keys[i], accountNames)
+	}
+
+	var records []provider.CostRecord
+	for _, costType := range c.awsCfg.CostTypes() {
+		value, ok := metrics[costMetrics[costType]]
+		if !ok {
+			continue
+		}
+		record := base
+		record.Tags = maps.Clone(base.Tags)
+		record.Cost = parseAmount(value.Amount)
+		record.Currency = awssdk.ToString(value.Unit)
+		if record.Currency == "" {
+			record.Currency = c.cfg.Currency
+		}
+		record.CostType = costType
+		records = append(records, record)
+	}
+	return records
+}
+
+// applyGroupKey sets the record field of a group_by entry to the group's key
+func applyGroupKey(record *provider.CostRecord, groupBy, key string, accountNames map[string]string) {
+	if tagKey, ok := strings.CutPrefix(groupBy, config.AWSTagPrefix); ok {
+		// Tag keys are returned as "<key>$<value>", with an empty value for untagged costs
+		_, value, _ := strings.Cut(key, "$")
+		record.Tags[tagKey] = value
+		return
+	}
+
+	switch groupBy {
+	case config.AWSDimensionLinkedAccount:
+		record.AccountID = key
+		record.AccountName = key
+		if name, ok := accountNames[key]; ok {
+			record.AccountName = name
+		}
+	case config.AWSDimensionService:
+		record.Service = key
+	case config.AWSDimensionRegion:
+		record.ResourceLocation = key
+	case config.AWSDimensionUsageType:
+		record.Meter = key
+	case config.AWSDimensionRecordType:
+		record.ChargeType = key
+	case config.AWSDimensionPurchaseType:
+		record.PricingModel = key
+	}
+}
+
+// parseAmount converts a Cost Explorer amount, returned as a decimal string
+func parseAmount(amount *string) float64 {
+	if amount == nil {
+		return 0
+	}
+	value, err := strconv.ParseFloat(*amount, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
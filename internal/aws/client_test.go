@@ -0,0 +1,295 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/zgpcy/azure-cost-exporter/internal/clock"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/logger"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// ceStandIn is an httptest stand-in for the Cost Explorer JSON endpoint
+// Responses are served in order; the decoded request bodies are recorded
+type ceStandIn struct {
+	t         *testing.T
+	mu        sync.Mutex
+	responses []func(w http.ResponseWriter)
+	requests  []map[string]any
+}
+
+// fixture returns a response serving a testdata file
+func fixture(t *testing.T, name string) func(w http.ResponseWriter) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		_, _ = w.Write(data)
+	}
+}
+
+// apiError returns a response carrying a Cost Explorer error
+func apiError(status int, code string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"__type":"` + code + `","message":"test error"}`))
+	}
+}
+
+func (s *ceStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if target := r.Header.Get("X-Amz-Target"); target != "AWSInsightsIndexService.GetCostAndUsage" {
+		s.t.Errorf("X-Amz-Target = %q, want GetCostAndUsage", target)
+	}
+
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		s.t.Errorf("Failed to decode request: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.requests) >= len(s.responses) {
+		s.t.Errorf("Unexpected request %d", len(s.requests)+1)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	s.requests = append(s.requests, body)
+	s.responses[len(s.requests)-1](w)
+}
+
+// testConfig returns the shared settings used by the AWS client
+func testConfig() *config.Config {
+	offset := 1
+	return &config.Config{
+		Currency:   "USD",
+		APITimeout: 30,
+		DateRange:  config.DateRange{DaysToQuery: 2, EndDateOffset: &offset},
+	}
+}
+
+// newTestClient creates a client sending its requests to a Cost Explorer stand-in
+func newTestClient(t *testing.T, awsCfg config.AWSConfig, responses ...func(w http.ResponseWriter)) (*Client, *ceStandIn) {
+	t.Helper()
+
+	stand := &ceStandIn{t: t, responses: responses}
+	srv := httptest.NewServer(stand)
+	t.Cleanup(srv.Close)
+
+	awsCfg.Endpoint = srv.URL
+	sdkCfg := awssdk.Config{
+		Region:      config.DefaultAWSRegion,
+		Credentials: credentials.NewStaticCredentialsProvider("AKIDTEST", "secret", ""),
+		Retryer:     func() awssdk.Retryer { return awssdk.NopRetryer{} },
+	}
+
+	client := newClient(testConfig(), awsCfg, logger.New("error"), sdkCfg)
+	client.clock = clock.Fixed(time.Date(2026, 1, 16, 10, 0, 0, 0, time.UTC))
+	return client, stand
+}
+
+// TestQueryCosts tests that all pages are fetched and groups are mapped onto records
+func TestQueryCosts(t *testing.T) {
+	awsCfg := config.AWSConfig{
+		AccountID:   "999999999999",
+		AccountName: "management",
+		CostType:    config.CostTypeActual,
+		GroupBy:     config.DefaultAWSGroupBy,
+	}
+	client, stand := newTestClient(t, awsCfg,
+		fixture(t, "cost_and_usage_page1.json"),
+		fixture(t, "cost_and_usage_page2.json"))
+
+	result, err := client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+
+	want := []provider.CostRecord{
+		{Date: "2026-01-14", Provider: "aws", AccountID: "111111111111", AccountName: "production", Service: "Amazon Elastic Compute Cloud - Compute", Cost: 12.5, Currency: "USD", CostType: "actual"},
+		{Date: "2026-01-14", Provider: "aws", AccountID: "222222222222", AccountName: "staging", Service: "Amazon Simple Storage Service", Cost: 0.75, Currency: "USD", CostType: "actual"},
+		{Date: "2026-01-15", Provider: "aws", AccountID: "111111111111", AccountName: "production", Service: "Amazon Elastic Compute Cloud - Compute", Cost: 3.25, Currency: "USD", CostType: "actual"},
+	}
+	if len(result.Records) != len(want) {
+		t.Fatalf("Got %d records, want %d: %+v", len(result.Records), len(want), result.Records)
+	}
+	for i, r := range result.Records {
+		if r.Date != want[i].Date || r.AccountID != want[i].AccountID || r.AccountName != want[i].AccountName ||
+			r.Service != want[i].Service || r.Cost != want[i].Cost || r.Currency != want[i].Currency || r.CostType != want[i].CostType {
+			t.Errorf("Record %d = %+v, want %+v", i, r, want[i])
+		}
+	}
+
+	if len(result.Accounts) != 1 {
+		t.Fatalf("Got %d account results, want 1", len(result.Accounts))
+	}
+	account := result.Accounts[0]
+	if account.AccountID != "999999999999" || account.AccountName != "management" || account.Err != nil || account.Rows != 3 {
+		t.Errorf("Account result = %+v", account)
+	}
+
+	if len(stand.requests) != 2 {
+		t.Fatalf("Got %d requests, want 2", len(stand.requests))
+	}
+	first := stand.requests[0]
+	if first["Granularity"] != "DAILY" {
+		t.Errorf("Granularity = %v, want DAILY", first["Granularity"])
+	}
+	period, _ := first["TimePeriod"].(map[string]any)
+	if period["Start"] != "2026-01-14" || period["End"] != "2026-01-16" {
+		t.Errorf("TimePeriod = %v, want 2026-01-14 to 2026-01-16 (exclusive)", period)
+	}
+	if metrics, _ := first["Metrics"].([]any); len(metrics) != 1 || metrics[0] != "UnblendedCost" {
+		t.Errorf("Metrics = %v, want [UnblendedCost]", first["Metrics"])
+	}
+	groups, _ := first["GroupBy"].([]any)
+	if len(groups) != 2 {
+		t.Fatalf("GroupBy = %v, want 2 groups", first["GroupBy"])
+	}
+	if g, _ := groups[0].(map[string]any); g["Type"] != "DIMENSION" || g["Key"] != "LINKED_ACCOUNT" {
+		t.Errorf("GroupBy[0] = %v, want DIMENSION LINKED_ACCOUNT", g)
+	}
+	if _, ok := first["NextPageToken"]; ok {
+		t.Errorf("First request carries a NextPageToken")
+	}
+	if token := stand.requests[1]["NextPageToken"]; token != "page-2" {
+		t.Errorf("Second request NextPageToken = %v, want page-2", token)
+	}
+}
+
+// TestQueryCosts_TagsAndCostTypes tests tag groups and querying both cost metrics at once
+func TestQueryCosts_TagsAndCostTypes(t *testing.T) {
+	awsCfg := config.AWSConfig{
+		AccountID:   "999999999999",
+		AccountName: "management",
+		CostType:    config.CostTypeBoth,
+		GroupBy:     []string{config.AWSDimensionService, "tag:team"},
+	}
+	client, stand := newTestClient(t, awsCfg, fixture(t, "cost_and_usage_tags.json"))
+
+	result, err := client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+
+	if metrics, _ := stand.requests[0]["Metrics"].([]any); len(metrics) != 2 || metrics[0] != "UnblendedCost" || metrics[1] != "AmortizedCost" {
+		t.Errorf("Metrics = %v, want [UnblendedCost AmortizedCost]", stand.requests[0]["Metrics"])
+	}
+	groups, _ := stand.requests[0]["GroupBy"].([]any)
+	if g, _ := groups[1].(map[string]any); g["Type"] != "TAG" || g["Key"] != "team" {
+		t.Errorf("GroupBy[1] = %v, want TAG team", g)
+	}
+
+	type key struct{ costType, team string }
+	want := map[key]float64{
+		{"actual", "platform"}:    4,
+		{"amortized", "platform"}: 4.5,
+		{"actual", ""}:            1,
+		{"amortized", ""}:         1,
+	}
+	if len(result.Records) != len(want) {
+		t.Fatalf("Got %d records, want %d: %+v", len(result.Records), len(want), result.Records)
+	}
+	for _, r := range result.Records {
+		if r.AccountID != "999999999999" || r.AccountName != "management" {
+			t.Errorf("Record account = %s/%s, want the configured account", r.AccountID, r.AccountName)
+		}
+		if r.Service != "AWS Lambda" {
+			t.Errorf("Record service = %q, want AWS Lambda", r.Service)
+		}
+		team, ok := r.Tags["team"]
+		if !ok {
+			t.Errorf("Record has no team tag: %+v", r)
+		}
+		if cost, ok := want[key{r.CostType, team}]; !ok || cost != r.Cost {
+			t.Errorf("Unexpected record %s team=%q cost=%v", r.CostType, team, r.Cost)
+		}
+	}
+}
+
+// TestQueryCosts_PermissionDenied tests that permission errors fail without retries
+func TestQueryCosts_PermissionDenied(t *testing.T) {
+	awsCfg := config.AWSConfig{
+		AccountID: "999999999999",
+		CostType:  config.CostTypeActual,
+		GroupBy:   config.DefaultAWSGroupBy,
+	}
+	client, stand := newTestClient(t, awsCfg, apiError(http.StatusBadRequest, "AccessDeniedException"))
+
+	result, err := client.QueryCosts(context.Background())
+	if err == nil {
+		t.Fatal("QueryCosts() error = nil, want permission error")
+	}
+	if reason := provider.ErrorReason(err); reason != provider.ReasonPermission {
+		t.Errorf("ErrorReason() = %q, want %q", reason, provider.ReasonPermission)
+	}
+	if len(stand.requests) != 1 {
+		t.Errorf("Got %d requests, want 1 (permission errors aren't retried)", len(stand.requests))
+	}
+	if len(result.Accounts) != 1 || result.Accounts[0].Err == nil || result.Accounts[0].Retries != 0 {
+		t.Errorf("Account results = %+v, want one failed account without retries", result.Accounts)
+	}
+}
+
+// TestQueryCosts_RetriesThrottled tests that throttled requests are retried
+func TestQueryCosts_RetriesThrottled(t *testing.T) {
+	awsCfg := config.AWSConfig{
+		AccountID: "999999999999",
+		CostType:  config.CostTypeActual,
+		GroupBy:   config.DefaultAWSGroupBy,
+	}
+	client, _ := newTestClient(t, awsCfg,
+		apiError(http.StatusBadRequest, "LimitExceededException"),
+		fixture(t, "cost_and_usage_page2.json"))
+
+	result, err := client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+	if len(result.Records) != 1 {
+		t.Errorf("Got %d records, want 1", len(result.Records))
+	}
+	if retries := result.Accounts[0].Retries; retries != 1 {
+		t.Errorf("Retries = %d, want 1", retries)
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		status int
+		want   string
+	}{
+		{"access denied", "AccessDeniedException", http.StatusBadRequest, provider.ReasonPermission},
+		{"invalid token", "UnrecognizedClientException", http.StatusBadRequest, provider.ReasonAuth},
+		{"limit exceeded", "LimitExceededException", http.StatusBadRequest, provider.ReasonThrottled},
+		{"validation", "ValidationException", http.StatusBadRequest, provider.ReasonBadRequest},
+		{"unknown code", "SomethingElse", http.StatusServiceUnavailable, provider.ReasonTransient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			awsCfg := config.AWSConfig{CostType: config.CostTypeActual}
+			client, _ := newTestClient(t, awsCfg, apiError(tt.status, tt.code))
+
+			_, err := client.getCostAndUsage(context.Background(), client.costInput(time.Now(), time.Now()))
+			if got := classifyError(err).Reason(); got != tt.want {
+				t.Errorf("classifyError() reason = %q, want %q (error: %v)", got, tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+// Package aws provides the AWS Cost Explorer cost provider.
+//
+// The Client queries GetCostAndUsage with daily granularity for the configured
+// date range and maps every returned group onto a provider.CostRecord:
+//   - LINKED_ACCOUNT groups become the record's account, named after the
+//     account description returned by Cost Explorer
+//   - SERVICE, REGION, USAGE_TYPE, RECORD_TYPE and PURCHASE_TYPE groups fill
+//     Service, ResourceLocation, Meter, ChargeType and PricingModel
+//   - tag:<key> groups fill Tags[key]
+//
+// Unblended costs are exported as actual and amortized costs as amortized
+// costs; with cost_type both, a single query requests both metrics. Result
+// pages are followed through NextPageToken.
+//
+// Credentials are resolved by the AWS SDK default chain, optionally assuming
+// a role. The principal needs the ce:GetCostAndUsage permission.
+//
+// Example usage:
+//
+//	awsCfg := config.AWSConfig{AccountID: "123456789012", GroupBy: config.DefaultAWSGroupBy}
+//
+//	client, err := aws.NewClient(ctx, cfg, awsCfg, log)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	result, err := client.QueryCosts(ctx)
+package aws
@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// errorCodeReasons maps Cost Explorer error codes to failure categories
+// Codes not listed here are classified by their HTTP status
+var errorCodeReasons = map[string]string{
+	"UnrecognizedClientException":      provider.ReasonAuth,
+	"InvalidClientTokenId":             provider.ReasonAuth,
+	"ExpiredTokenException":            provider.ReasonAuth,
+	"InvalidSignatureException":        provider.ReasonAuth,
+	"AccessDeniedException":            provider.ReasonPermission,
+	"LimitExceededException":           provider.ReasonThrottled,
+	"ThrottlingException":              provider.ReasonThrottled,
+	"ValidationException":              provider.ReasonBadRequest,
+	"DataUnavailableException":         provider.ReasonBadRequest,
+	"BillExpirationException":          provider.ReasonBadRequest,
+	"InvalidNextTokenException":        provider.ReasonBadRequest,
+	"RequestChangedException":          provider.ReasonTransient,
+	"ServiceUnavailableException":      provider.ReasonTransient,
+	"InternalServerErrorException":     provider.ReasonTransient,
+	"UnresolvableUsageUnitException":   provider.ReasonBadRequest,
+	"BillingViewHealthStatusException": provider.ReasonBadRequest,
+}
+
+// classifyError wraps err in a *provider.Error describing its failure category
+func classifyError(err error) *provider.Error {
+	var classified *provider.Error
+	if errors.As(err, &classified) {
+		return classified
+	}
+
+	status := 0
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		status = respErr.HTTPStatusCode()
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		if reason, ok := errorCodeReasons[apiErr.ErrorCode()]; ok {
+			return &provider.Error{Category: reason, StatusCode: status, Err: err}
+		}
+	}
+	if status != 0 {
+		return &provider.Error{Category: provider.StatusReason(status), StatusCode: status, Err: err}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return &provider.Error{Category: provider.ReasonTimeout, Err: err}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return &provider.Error{Category: provider.ReasonTimeout, Err: err}
+	}
+
+	// Credentials that can't be resolved fail the request signing, before anything is sent
+	var signErr *v4.SigningError
+	if errors.As(err, &signErr) {
+		return &provider.Error{Category: provider.ReasonAuth, Err: err}
+	}
+
+	// Connection resets, DNS failures, malformed responses and the like
+	return &provider.Error{Category: provider.ReasonTransient, Err: err}
+}
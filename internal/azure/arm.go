@@ -0,0 +1,44 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// armList is a page of an ARM list API
+type armList[T any] struct {
+	Value    []T    `json:"value"`
+	NextLink string `json:"nextLink"`
+}
+
+// listAll GETs an ARM list API with the tenant's pipeline and follows nextLink until all pages are fetched
+func listAll[T any](ctx context.Context, t *tenant, link string) ([]T, error) {
+	var items []T
+
+	for link != "" {
+		req, err := runtime.NewRequest(ctx, http.MethodGet, link)
+		if err != nil {
+			return nil, err
+		}
+		req.Raw().Header["Accept"] = []string{"application/json"}
+
+		resp, err := t.pipeline.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if !runtime.HasStatusCode(resp, http.StatusOK) {
+			return nil, runtime.NewResponseError(resp)
+		}
+
+		var page armList[T]
+		if err := runtime.UnmarshalAsJSON(resp, &page); err != nil {
+			return nil, err
+		}
+		items = append(items, page.Value...)
+		link = page.NextLink
+	}
+
+	return items, nil
+}
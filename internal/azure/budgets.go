@@ -0,0 +1,172 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// budgetsAPIVersion is the Consumption API version used to list budgets
+const budgetsAPIVersion = "2023-05-01"
+
+// Verify that Client implements provider.BudgetReader
+var _ provider.BudgetReader = (*Client)(nil)
+
+// armBudget is a budget as returned by the Consumption Budgets API
+type armBudget struct {
+	Name       string `json:"name"`
+	Properties struct {
+		Amount        float64                          `json:"amount"`
+		TimeGrain     string                           `json:"timeGrain"`
+		CurrentSpend  *armBudgetSpend                  `json:"currentSpend"`
+		ForecastSpend *armBudgetSpend                  `json:"forecastSpend"`
+		Notifications map[string]armBudgetNotification `json:"notifications"`
+	} `json:"properties"`
+}
+
+// armBudgetSpend is an amount spent against a budget
+type armBudgetSpend struct {
+	Amount float64 `json:"amount"`
+	Unit   string  `json:"unit"` // Currency
+}
+
+// armBudgetNotification is an alert threshold of a budget
+type armBudgetNotification struct {
+	Enabled       bool    `json:"enabled"`
+	Operator      string  `json:"operator"`
+	Threshold     float64 `json:"threshold"`
+	ThresholdType string  `json:"thresholdType"`
+}
+
+// armBudgetList is a page of the Consumption Budgets API
+type armBudgetList = armList[armBudget]
+
+// QueryBudgets lists the budgets defined on all subscriptions and scopes
+// Returns partial data if some of them fail (best-effort approach)
+func (c *Client) QueryBudgets(ctx context.Context) (provider.BudgetResult, error) {
+	targets := c.targets()
+	results := c.queryTargets(ctx, targets, c.budgetsForTarget)
+
+	var (
+		budgets  []provider.Budget
+		failures []error
+	)
+	accounts := make([]provider.AccountResult, 0, len(targets))
+
+	for i, target := range targets {
+		accounts = append(accounts, results[i].account)
+		if err := results[i].account.Err; err != nil {
+			c.logger.Warn("Failed to list budgets, continuing with others",
+				"tenant_id", target.tenant.cfg.ID,
+				"subscription_name", target.account.Name,
+				"subscription_id", target.account.ID,
+				"scope", target.scope,
+				"reason", provider.ErrorReason(err),
+				"error", err)
+			failures = append(failures, fmt.Errorf("subscription %s: %w", target.account.Name, err))
+			continue
+		}
+		budgets = append(budgets, results[i].budgets...)
+	}
+
+	if len(failures) == len(targets) && len(failures) > 0 {
+		return provider.BudgetResult{Accounts: accounts}, fmt.Errorf("listing budgets failed for all %d subscriptions: %w",
+			len(targets), errors.Join(failures...))
+	}
+
+	return provider.BudgetResult{Budgets: budgets, Accounts: accounts}, nil
+}
+
+// budgetsForTarget lists the budgets of a single target with retry logic
+func (c *Client) budgetsForTarget(ctx context.Context, target queryTarget) targetResult {
+	account := provider.AccountResult{
+		AccountID:   target.account.ID,
+		AccountName: target.account.Name,
+	}
+
+	var (
+		budgets []armBudget
+		retries int
+	)
+	start := time.Now()
+	err := c.retryQuery(ctx, target, "budgets", &retries, func() error {
+		var err error
+		budgets, err = c.listBudgets(ctx, target)
+		return err
+	})
+	account.Duration = time.Since(start)
+	account.Retries = retries
+	if err != nil {
+		account.Err = fmt.Errorf("subscription %s (ID: %s) budget list failed after retries: %w",
+			target.account.Name, target.account.ID, err)
+		return targetResult{account: account}
+	}
+
+	result := targetResult{account: account}
+	for _, b := range budgets {
+		result.budgets = append(result.budgets, c.convertBudget(b, target))
+	}
+	result.account.Rows = len(result.budgets)
+	return result
+}
+
+// listBudgets lists the budgets defined on a target's scope, following nextLink
+func (c *Client) listBudgets(ctx context.Context, target queryTarget) ([]armBudget, error) {
+	apiTimeout := time.Duration(c.cfg.APITimeout) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, apiTimeout)
+	defer cancel()
+
+	link := runtime.JoinPaths(target.tenant.endpoint, target.scope, "/providers/Microsoft.Consumption/budgets") +
+		"?api-version=" + budgetsAPIVersion
+	return listAll[armBudget](ctx, target.tenant, link)
+}
+
+// convertBudget converts an ARM budget into a provider budget
+// Disabled notifications are skipped; the currency falls back to the configured one
+func (c *Client) convertBudget(b armBudget, target queryTarget) provider.Budget {
+	budget := provider.Budget{
+		Provider:    string(provider.ProviderAzure),
+		AccountID:   target.account.ID,
+		AccountName: target.account.Name,
+		Scope:       target.scope,
+		Name:        b.Name,
+		TimeGrain:   b.Properties.TimeGrain,
+		Currency:    c.cfg.Currency,
+		Amount:      b.Properties.Amount,
+	}
+
+	if spend := b.Properties.CurrentSpend; spend != nil {
+		budget.CurrentSpend = spend.Amount
+		if spend.Unit != "" {
+			budget.Currency = spend.Unit
+		}
+	}
+	if spend := b.Properties.ForecastSpend; spend != nil {
+		amount := spend.Amount
+		budget.ForecastSpend = &amount
+	}
+
+	names := make([]string, 0, len(b.Properties.Notifications))
+	for name, n := range b.Properties.Notifications {
+		if n.Enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		n := b.Properties.Notifications[name]
+		budget.Notifications = append(budget.Notifications, provider.BudgetNotification{
+			Name:          name,
+			Threshold:     n.Threshold,
+			Operator:      n.Operator,
+			ThresholdType: n.ThresholdType,
+		})
+	}
+
+	return budget
+}
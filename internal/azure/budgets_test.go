@@ -0,0 +1,110 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+)
+
+// TestQueryBudgets tests that budgets are listed per scope across pages and converted
+func TestQueryBudgets(t *testing.T) {
+	cfg := testQueryConfig()
+	cfg.Scopes = []config.Scope{{Type: config.ScopeTypeManagementGroup, ID: "mg-root", Name: "root"}}
+
+	var (
+		serverURL string
+		requests  atomic.Int32
+	)
+	pages := map[string]string{
+		"/subscriptions/test-sub-1/providers/Microsoft.Consumption/budgets": `{"value":[{"name":"monthly",` +
+			`"properties":{"amount":1000,"timeGrain":"Monthly","currentSpend":{"amount":420.5,"unit":"EUR"},"forecastSpend":{"amount":980,"unit":"EUR"},` +
+			`"notifications":{"Forecasted_GreaterThan_100":{"enabled":true,"operator":"GreaterThan","threshold":100,"thresholdType":"Forecasted"},` +
+			`"Actual_GreaterThan_80":{"enabled":true,"operator":"GreaterThan","threshold":80,"thresholdType":"Actual"},` +
+			`"Actual_GreaterThan_50":{"enabled":false,"operator":"GreaterThan","threshold":50,"thresholdType":"Actual"}}}}],` +
+			`"nextLink":"NEXT"}`,
+		"/subscriptions/test-sub-1/providers/Microsoft.Consumption/budgets/page2": `{"value":[{"name":"quarterly",` +
+			`"properties":{"amount":2500,"timeGrain":"Quarterly"}}]}`,
+		"/providers/Microsoft.Management/managementGroups/mg-root/providers/Microsoft.Consumption/budgets": `{"value":[]}`,
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Query().Get("api-version") != budgetsAPIVersion {
+			t.Errorf("Unexpected api-version %q", r.URL.Query().Get("api-version"))
+		}
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var page armBudgetList
+		_ = json.Unmarshal([]byte(body), &page)
+		if page.NextLink != "" {
+			page.NextLink = serverURL + "/subscriptions/test-sub-1/providers/Microsoft.Consumption/budgets/page2?api-version=" + budgetsAPIVersion
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(page)
+	})
+	client, url := newTestServerClient(t, cfg, handler)
+	serverURL = url
+
+	result, err := client.QueryBudgets(context.Background())
+	if err != nil {
+		t.Fatalf("QueryBudgets() error = %v", err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("Expected 3 requests (2 subscription pages + management group), got %d", got)
+	}
+	if len(result.Accounts) != 2 {
+		t.Errorf("Expected 2 account results, got %d", len(result.Accounts))
+	}
+	if len(result.Budgets) != 2 {
+		t.Fatalf("Expected 2 budgets, got %d", len(result.Budgets))
+	}
+
+	monthly := result.Budgets[0]
+	if monthly.Name != "monthly" || monthly.TimeGrain != "Monthly" || monthly.Scope != "/subscriptions/test-sub-1" {
+		t.Errorf("Unexpected budget %+v", monthly)
+	}
+	if monthly.Amount != 1000 || monthly.CurrentSpend != 420.5 || monthly.Currency != "EUR" {
+		t.Errorf("Budget amounts = %v/%v %s, want 1000/420.5 EUR", monthly.Amount, monthly.CurrentSpend, monthly.Currency)
+	}
+	if monthly.ForecastSpend == nil || *monthly.ForecastSpend != 980 {
+		t.Errorf("ForecastSpend = %v, want 980", monthly.ForecastSpend)
+	}
+	if len(monthly.Notifications) != 2 || monthly.Notifications[0].Name != "Actual_GreaterThan_80" || monthly.Notifications[0].Threshold != 80 {
+		t.Errorf("Notifications = %+v, want enabled notifications sorted by name", monthly.Notifications)
+	}
+
+	quarterly := result.Budgets[1]
+	if quarterly.ForecastSpend != nil || quarterly.Currency != cfg.Currency {
+		t.Errorf("Budget without spend = %+v, want no forecast and configured currency", quarterly)
+	}
+}
+
+// TestQueryBudgets_PermissionDenied tests that a permanent failure is reported per account
+func TestQueryBudgets_PermissionDenied(t *testing.T) {
+	var requests atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":{"code":"AuthorizationFailed","message":"no access"}}`))
+	})
+	client, _ := newTestServerClient(t, testQueryConfig(), handler)
+
+	result, err := client.QueryBudgets(context.Background())
+	if err == nil {
+		t.Fatal("Expected error when all budget lists fail")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Expected permission errors not to be retried, got %d requests", got)
+	}
+	if len(result.Accounts) != 1 || result.Accounts[0].Err == nil {
+		t.Errorf("Accounts = %+v, want one failed account", result.Accounts)
+	}
+}
@@ -0,0 +1,45 @@
+package azure
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+)
+
+// cloudConfiguration maps the configured cloud to the SDK cloud configuration
+func cloudConfiguration(c config.CloudConfig) cloud.Configuration {
+	switch c.Name {
+	case config.CloudAzureChina:
+		return cloud.AzureChina
+	case config.CloudAzureGovernment:
+		return cloud.AzureGovernment
+	case config.CloudCustom:
+		audience := c.ResourceManagerAudience
+		if audience == "" {
+			audience = c.ResourceManagerEndpoint
+		}
+		return cloud.Configuration{
+			ActiveDirectoryAuthorityHost: c.AuthorityHost,
+			Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+				cloud.ResourceManager: {Endpoint: c.ResourceManagerEndpoint, Audience: audience},
+			},
+		}
+	default:
+		return cloud.AzurePublic
+	}
+}
+
+// isWellKnownAuthority reports whether an authority host belongs to a built-in cloud
+// Instance discovery only works against these; custom authorities must skip it
+func isWellKnownAuthority(host string) bool {
+	if host == "" {
+		return true // SDK default (public cloud)
+	}
+	for _, known := range []cloud.Configuration{cloud.AzurePublic, cloud.AzureChina, cloud.AzureGovernment} {
+		if strings.EqualFold(strings.TrimSuffix(host, "/"), strings.TrimSuffix(known.ActiveDirectoryAuthorityHost, "/")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/logger"
+)
+
+// TestCloudConfiguration tests the mapping of built-in clouds
+func TestCloudConfiguration(t *testing.T) {
+	tests := []struct {
+		name          string
+		wantAuthority string
+		wantEndpoint  string
+	}{
+		{config.CloudAzurePublic, "https://login.microsoftonline.com/", "https://management.azure.com"},
+		{config.CloudAzureChina, "https://login.chinacloudapi.cn/", "https://management.chinacloudapi.cn"},
+		{config.CloudAzureGovernment, "https://login.microsoftonline.us/", "https://management.usgovcloudapi.net"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cloudConfiguration(config.CloudConfig{Name: tt.name})
+			if got.ActiveDirectoryAuthorityHost != tt.wantAuthority {
+				t.Errorf("authority = %q, want %q", got.ActiveDirectoryAuthorityHost, tt.wantAuthority)
+			}
+			if endpoint := got.Services[cloud.ResourceManager].Endpoint; endpoint != tt.wantEndpoint {
+				t.Errorf("resource manager endpoint = %q, want %q", endpoint, tt.wantEndpoint)
+			}
+			if !isWellKnownAuthority(got.ActiveDirectoryAuthorityHost) {
+				t.Error("Built-in cloud authority should be well known")
+			}
+		})
+	}
+}
+
+// hostRecorder is a stand-in cloud that records the paths requested on it
+type hostRecorder struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (h *hostRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.paths = append(h.paths, r.URL.Path)
+	h.mu.Unlock()
+
+	if strings.Contains(r.URL.Path, "Microsoft.CostManagement/query") {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"UsageDate","type":"Number"}],"rows":[[1.0,20260115]]}}`))
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func (h *hostRecorder) requested(fragment string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, p := range h.paths {
+		if strings.Contains(p, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// TestCustomCloud_ResourceManager tests that cost queries go to the custom endpoint
+func TestCustomCloud_ResourceManager(t *testing.T) {
+	stand := &hostRecorder{}
+	srv := httptest.NewTLSServer(stand)
+	t.Cleanup(srv.Close)
+
+	custom := config.CloudConfig{
+		Name:                    config.CloudCustom,
+		ResourceManagerEndpoint: srv.URL,
+		ResourceManagerAudience: "https://management.contoso.local",
+		AuthorityHost:           srv.URL,
+	}
+	opts := &arm.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Cloud:     cloudConfiguration(custom),
+			Transport: srv.Client(),
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+	}
+
+	client, err := newClient(testQueryConfig(), logger.New("error"), fakeCredentials, opts)
+	if err != nil {
+		t.Fatalf("newClient() error = %v", err)
+	}
+
+	if _, err := client.QueryCosts(context.Background()); err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+	if !stand.requested("/subscriptions/test-sub-1/providers/Microsoft.CostManagement/query") {
+		t.Errorf("Cost query was not sent to the custom endpoint, requests: %v", stand.paths)
+	}
+}
+
+// TestCustomCloud_Authority tests that the credential authenticates against the custom authority
+func TestCustomCloud_Authority(t *testing.T) {
+	stand := &hostRecorder{}
+	srv := httptest.NewTLSServer(stand)
+	t.Cleanup(srv.Close)
+
+	custom := config.CloudConfig{Name: config.CloudCustom, ResourceManagerEndpoint: srv.URL, AuthorityHost: srv.URL}
+	opts := policy.ClientOptions{
+		Cloud:     cloudConfiguration(custom),
+		Transport: srv.Client(),
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	}
+	if isWellKnownAuthority(opts.Cloud.ActiveDirectoryAuthorityHost) {
+		t.Fatal("Custom authority must not be treated as well known")
+	}
+
+	cred, err := newCredential(config.AuthConfig{
+		Type:         config.AuthTypeClientSecret,
+		TenantID:     "contoso-tenant",
+		ClientID:     "client",
+		ClientSecret: "secret",
+	}, opts)
+	if err != nil {
+		t.Fatalf("newCredential() error = %v", err)
+	}
+
+	// The stand-in doesn't issue tokens; only the requested host matters
+	_, _ = cred.GetToken(context.Background(), policy.TokenRequestOptions{Scopes: []string{srv.URL + "/.default"}})
+
+	if !stand.requested("/contoso-tenant/") {
+		t.Errorf("Credential did not contact the custom authority, requests: %v", stand.paths)
+	}
+}
@@ -2,60 +2,147 @@ package azure
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
-	"github.com/cenkalti/backoff/v4"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/zgpcy/azure-cost-exporter/internal/clock"
 	"github.com/zgpcy/azure-cost-exporter/internal/config"
 	"github.com/zgpcy/azure-cost-exporter/internal/logger"
 	"github.com/zgpcy/azure-cost-exporter/internal/provider"
 )
 
-// Azure API retry constants
+// Telemetry identifiers for raw ARM requests (the SDK requires a semver module version)
 const (
-	// MaxRetryElapsedTime is the maximum time to spend retrying a failed API call
-	MaxRetryElapsedTime = 2 * time.Minute
-
-	// InitialRetryInterval is the initial backoff interval for retries
-	InitialRetryInterval = 1 * time.Second
+	pipelineModuleName    = "azure-cost-exporter"
+	pipelineModuleVersion = "v0.0.0-dev" // Used for builds without a release version
+)
 
-	// MaxRetryInterval is the maximum backoff interval between retries
-	MaxRetryInterval = 30 * time.Second
+// Currency column of Cost Management responses and the currency of the USD cost columns
+const (
+	currencyColumn = "Currency"
+	currencyUSD    = "USD"
 )
 
 // Client wraps the Azure Cost Management client and implements provider.CloudProvider
 type Client struct {
-	client *armcostmanagement.QueryClient
-	cfg    *config.Config
-	logger *logger.Logger
-	clock  clock.Clock // Time provider for testing
+	tenants []*tenant // One set of API clients per tenant, in configuration order
+	cfg     *config.Config
+	logger  *logger.Logger
+	clock   clock.Clock // Time provider for testing
+
+	// Metrics
+	queryPagesTotal   *prometheus.CounterVec
+	qpuRemaining      *prometheus.GaugeVec
+	throttledRequests *prometheus.CounterVec
 }
 
-// Verify that Client implements provider.CloudProvider
-var _ provider.CloudProvider = (*Client)(nil)
+// Verify that Client implements provider.CloudProvider and prometheus.Collector
+var (
+	_ provider.CloudProvider = (*Client)(nil)
+	_ prometheus.Collector   = (*Client)(nil)
+)
 
 // NewClient creates a new Azure Cost Management client
+// Each tenant's credential is selected by its auth configuration (DefaultAzureCredential
+// if unset) and both the credentials and the API clients target the configured cloud
 func NewClient(cfg *config.Config, log *logger.Logger) (*Client, error) {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Azure credential: %w", err)
-	}
+	clientOpts := policy.ClientOptions{Cloud: cloudConfiguration(cfg.Cloud)}
 
-	client, err := armcostmanagement.NewQueryClient(cred, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create cost management client: %w", err)
+	newCred := func(auth config.AuthConfig) (azcore.TokenCredential, error) {
+		cred, err := newCredential(auth, clientOpts)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("Using Azure credential", describeAuth(auth)...)
+		return cred, nil
 	}
 
-	return &Client{
-		client: client,
+	return newClient(cfg, log, newCred, &arm.ClientOptions{ClientOptions: clientOpts})
+}
+
+// newClient creates a client from a credential factory and ARM client options
+func newClient(cfg *config.Config, log *logger.Logger, newCred credentialFactory, opts *arm.ClientOptions) (*Client, error) {
+	c := &Client{
 		cfg:    cfg,
 		logger: log,
 		clock:  clock.RealClock{}, // Use real system time by default
-	}, nil
+		queryPagesTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "cloud_cost_exporter_query_pages_total",
+				Help: "Total number of cost query result pages fetched",
+			},
+			[]string{"provider", "account_name", "account_id"},
+		),
+		qpuRemaining: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "cloud_cost_exporter_qpu_remaining",
+				Help: "Remaining Cost Management query processing units as reported by the last response",
+			},
+			[]string{"provider", "quota"},
+		),
+		throttledRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "cloud_cost_exporter_throttled_requests_total",
+				Help: "Total number of API requests rejected with 429 Too Many Requests, by exhausted limit",
+			},
+			[]string{"provider", "limit"},
+		),
+	}
+
+	for _, tc := range cfg.TenantList() {
+		t, err := c.newTenant(tc, newCred, opts)
+		if err != nil {
+			if tc.ID != "" {
+				return nil, fmt.Errorf("tenant %s: %w", tc.ID, err)
+			}
+			return nil, err
+		}
+		c.tenants = append(c.tenants, t)
+	}
+
+	return c, nil
+}
+
+// pipelineOptions returns a copy of opts with the rate-limit policy installed
+// and 429 removed from the SDK's retried status codes
+func (c *Client) pipelineOptions(opts *arm.ClientOptions, state *rateLimitState) *arm.ClientOptions {
+	var o arm.ClientOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	if o.Retry.StatusCodes == nil {
+		o.Retry.StatusCodes = retryableStatusCodes
+	}
+	o.PerRetryPolicies = append(append([]policy.Policy{}, o.PerRetryPolicies...), &rateLimitPolicy{
+		state:        state,
+		qpuRemaining: c.qpuRemaining,
+		throttled:    c.throttledRequests,
+	})
+
+	return &o
+}
+
+// Describe implements prometheus.Collector
+func (c *Client) Describe(ch chan<- *prometheus.Desc) {
+	c.queryPagesTotal.Describe(ch)
+	c.qpuRemaining.Describe(ch)
+	c.throttledRequests.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *Client) Collect(ch chan<- prometheus.Metric) {
+	c.queryPagesTotal.Collect(ch)
+	c.qpuRemaining.Collect(ch)
+	c.throttledRequests.Collect(ch)
 }
 
 // Name returns the provider type
@@ -63,94 +150,234 @@ func (c *Client) Name() provider.ProviderType {
 	return provider.ProviderAzure
 }
 
-// AccountCount returns the number of Azure subscriptions being monitored
+// AccountCount returns the number of Azure subscriptions and scopes being monitored
 func (c *Client) AccountCount() int {
-	return len(c.cfg.Subscriptions)
+	return len(c.targets())
 }
 
-// QueryCosts retrieves cost data for all configured subscriptions
-// Returns partial data if some subscriptions fail (best-effort approach)
-func (c *Client) QueryCosts(ctx context.Context) ([]provider.CostRecord, error) {
+// targetResult holds the outcome of querying a single target
+type targetResult struct {
+	records       []provider.CostRecord
+	usage         []provider.CostRecord
+	monthToDate   []provider.CostRecord
+	previousMonth []provider.CostRecord
+	forecasts     []provider.ForecastRecord
+	budgets       []provider.Budget
+	commitments   []provider.CommitmentUtilization
+	account       provider.AccountResult
+}
+
+// QueryCosts retrieves cost data for all configured subscriptions and scopes
+// Targets are queried concurrently (bounded by max_concurrent_queries) and merged
+// in configuration order. Returns partial data if some of them fail (best-effort approach)
+func (c *Client) QueryCosts(ctx context.Context) (provider.QueryResult, error) {
 	var (
 		allRecords []provider.CostRecord
-		errors     []error
+		allUsage   []provider.CostRecord
+		failures   []error
 	)
 
+	// A tenant whose discovery failed before finding any subscription has nothing to
+	// query; its error must not be hidden behind an empty but successful result
+	for _, t := range c.tenants {
+		if err := c.refreshDiscovery(ctx, t); err != nil && len(t.targets()) == 0 {
+			failures = append(failures, fmt.Errorf("tenant %s: subscription discovery failed: %w", t.cfg.ID, err))
+		}
+	}
+	targets := c.targets()
+	if len(targets) == 0 && len(failures) > 0 {
+		return provider.QueryResult{}, fmt.Errorf("no subscriptions to query: %w", errors.Join(failures...))
+	}
+	results := c.queryTargets(ctx, targets, c.queryTarget)
+
 	// Pre-allocate with estimated capacity
 	estimatedRecordsPerSub := 1000
-	allRecords = make([]provider.CostRecord, 0, len(c.cfg.Subscriptions)*estimatedRecordsPerSub)
+	allRecords = make([]provider.CostRecord, 0, len(targets)*estimatedRecordsPerSub)
+	if c.queriesUsage() {
+		// Non-nil, so the collector takes usage from these records rather than the cost records
+		allUsage = []provider.CostRecord{}
+	}
+	accounts := make([]provider.AccountResult, 0, len(targets))
 
-	for _, sub := range c.cfg.Subscriptions {
-		records, err := c.queryCostsForSubscription(ctx, sub)
-		if err != nil {
+	for i, target := range targets {
+		accounts = append(accounts, results[i].account)
+		if err := results[i].account.Err; err != nil {
 			// Log the error but continue with other subscriptions
 			c.logger.Warn("Failed to query subscription, continuing with others",
-				"subscription_name", sub.Name,
-				"subscription_id", sub.ID,
+				"tenant_id", target.tenant.cfg.ID,
+				"subscription_name", target.account.Name,
+				"subscription_id", target.account.ID,
+				"scope", target.scope,
+				"reason", provider.ErrorReason(err),
+				"records_kept", len(results[i].records),
 				"error", err)
-			errors = append(errors, fmt.Errorf("subscription %s: %w", sub.Name, err))
-			continue
+			failures = append(failures, fmt.Errorf("subscription %s: %w", target.account.Name, err))
 		}
-		allRecords = append(allRecords, records...)
+		// Records of cost types that succeeded are kept when another cost type failed
+		allRecords = append(allRecords, results[i].records...)
+		allUsage = append(allUsage, results[i].usage...)
 	}
 
 	// Only return error if ALL subscriptions failed
-	if len(errors) > 0 && len(allRecords) == 0 {
-		return nil, fmt.Errorf("all %d subscriptions failed (check Azure credentials and permissions): %v",
-			len(c.cfg.Subscriptions), errors)
+	// The joined error keeps the classification of each failure
+	if len(failures) > 0 && len(allRecords) == 0 {
+		return provider.QueryResult{Accounts: accounts}, fmt.Errorf("all %d subscriptions failed (check Azure credentials and permissions): %w",
+			len(targets), errors.Join(failures...))
 	}
 
 	// Log warning if some subscriptions failed but we have partial data
-	if len(errors) > 0 {
+	if len(failures) > 0 {
 		c.logger.Warn("Some subscriptions failed, returning partial data",
-			"failed_count", len(errors),
-			"total_subscriptions", len(c.cfg.Subscriptions),
+			"failed_count", len(failures),
+			"total_subscriptions", len(targets),
 			"records_returned", len(allRecords))
 	}
 
 	// Return partial data with success (Prometheus best practice: partial data > no data)
-	return allRecords, nil
+	return provider.QueryResult{Records: allRecords, Usage: allUsage, Accounts: accounts}, nil
 }
 
-// queryCostsForSubscription queries costs for a single subscription with retry logic
-func (c *Client) queryCostsForSubscription(ctx context.Context, sub config.Subscription) ([]provider.CostRecord, error) {
-	var result []provider.CostRecord
+// queryTargets queries all targets with a bounded worker pool
+// Results are returned in the same order as targets, regardless of completion order
+func (c *Client) queryTargets(ctx context.Context, targets []queryTarget, query func(context.Context, queryTarget) targetResult) []targetResult {
+	results := make([]targetResult, len(targets))
+	jobs := make(chan int)
+
+	workers := min(c.maxConcurrentQueries(), len(targets))
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = query(ctx, targets[i])
+			}
+		}()
+	}
 
-	// Configure exponential backoff
-	bo := backoff.NewExponentialBackOff()
-	bo.InitialInterval = InitialRetryInterval
-	bo.MaxInterval = MaxRetryInterval
-	bo.MaxElapsedTime = MaxRetryElapsedTime
+	for i := range targets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
 
-	operation := func() error {
-		records, err := c.queryCostsForSubscriptionInternal(ctx, sub)
+	return results
+}
+
+// queryTarget queries a single target in its own context
+// The deadline covers the retry budget and the page requests of every cost and
+// usage query, so a stuck target can't hold a worker indefinitely
+func (c *Client) queryTarget(ctx context.Context, target queryTarget) targetResult {
+	account := provider.AccountResult{
+		AccountID:   target.account.ID,
+		AccountName: target.account.Name,
+	}
+	if err := ctx.Err(); err != nil {
+		account.Err = classifyError(err)
+		return targetResult{account: account}
+	}
+
+	queries := len(c.cfg.CostTypes())
+	if c.queriesUsage() {
+		queries *= 2
+	}
+	targetCtx, cancel := context.WithTimeout(ctx, time.Duration(queries)*c.queryTimeout())
+	defer cancel()
+
+	start := time.Now()
+	records, usage, retries, err := c.queryCostsForTarget(targetCtx, target)
+	account.Duration = time.Since(start)
+	account.Retries = retries
+	account.Rows = len(records)
+	account.Err = err
+
+	return targetResult{records: records, usage: usage, account: account}
+}
+
+// maxConcurrentQueries returns the configured query concurrency, falling back to the default
+func (c *Client) maxConcurrentQueries() int {
+	if c.cfg.MaxConcurrentQueries > 0 {
+		return c.cfg.MaxConcurrentQueries
+	}
+	return config.DefaultMaxConcurrent
+}
+
+// queryCostsForTarget queries costs for a single subscription or scope with retry logic
+// Each configured cost type (actual, amortized) is a separate query with its own retries,
+// followed by its usage query if usage metrics are enabled
+// Returns the cost and usage records and the number of retried API calls. A failing query
+// doesn't discard the records of the others: they are returned together with its error
+func (c *Client) queryCostsForTarget(ctx context.Context, target queryTarget) (costs, usage []provider.CostRecord, retries int, err error) {
+	var failures []error
+	sub := target.account
+
+	for _, costType := range c.cfg.CostTypes() {
+		err := c.retryQuery(ctx, target, costType+" cost", &retries, func() error {
+			records, err := c.queryCostsForTargetInternal(ctx, target, costType)
+			if err != nil {
+				return err
+			}
+			costs = append(costs, records...)
+			return nil
+		})
+		if err != nil {
+			failures = append(failures, fmt.Errorf("subscription %s (ID: %s) %s cost query failed after retries: %w", sub.Name, sub.ID, costType, err))
+		}
+
+		if !c.queriesUsage() {
+			continue
+		}
+		err = c.retryQuery(ctx, target, costType+" usage", &retries, func() error {
+			records, err := c.queryUsageForTargetInternal(ctx, target, costType)
+			if err != nil {
+				return err
+			}
+			usage = append(usage, records...)
+			return nil
+		})
 		if err != nil {
-			// Log retry attempt with context
+			failures = append(failures, fmt.Errorf("subscription %s (ID: %s) %s usage query failed after retries: %w", sub.Name, sub.ID, costType, err))
+		}
+	}
+
+	return costs, usage, retries, errors.Join(failures...)
+}
+
+// retryQuery runs a single API query with exponential backoff, counting retried calls in retries
+// Throttled attempts wait for the tenant's shared rate-limit delay; errors that
+// retrying can't fix end the retries immediately. name describes the query in logs
+func (c *Client) retryQuery(ctx context.Context, target queryTarget, name string, retries *int, query func() error) error {
+	sub := target.account
+
+	retrier := provider.Retrier{
+		Classify:   classifyError,
+		Wait:       target.tenant.rateLimit.wait, // Wait while any query of this tenant is throttled
+		RetryAfter: errorRetryAfter,
+		OnRetry: func(err *provider.Error, delay time.Duration) {
 			c.logger.Debug("Azure API call failed, will retry",
 				"subscription_name", sub.Name,
 				"subscription_id", sub.ID,
-				"error", err)
-			return err
-		}
-		result = records
-		return nil
+				"scope", target.scope,
+				"query", name,
+				"reason", err.Reason(),
+				"retry_after", delay,
+				"error", err.Err)
+		},
 	}
+	return retrier.Retry(ctx, retries, query)
+}
 
-	// Retry with exponential backoff
-	if err := backoff.Retry(operation, backoff.WithContext(bo, ctx)); err != nil {
-		return nil, fmt.Errorf("subscription %s (ID: %s) failed after retries: %w", sub.Name, sub.ID, err)
+// exportType maps a configured cost type to the Cost Management query type
+func exportType(costType string) armcostmanagement.ExportType {
+	if costType == config.CostTypeAmortized {
+		return armcostmanagement.ExportTypeAmortizedCost
 	}
-
-	return result, nil
+	return armcostmanagement.ExportTypeActualCost
 }
 
-// queryCostsForSubscriptionInternal performs the actual API call without retry logic
-func (c *Client) queryCostsForSubscriptionInternal(ctx context.Context, sub config.Subscription) ([]provider.CostRecord, error) {
-	// Create context with timeout for API call (from config)
-	apiTimeout := time.Duration(c.cfg.APITimeout) * time.Second
-	ctx, cancel := context.WithTimeout(ctx, apiTimeout)
-	defer cancel()
-
+// queryCostsForTargetInternal performs the actual API call without retry logic
+func (c *Client) queryCostsForTargetInternal(ctx context.Context, target queryTarget, costType string) ([]provider.CostRecord, error) {
 	// Calculate date range
 	endDateOffset := 0
 	if c.cfg.DateRange.EndDateOffset != nil {
@@ -163,60 +390,156 @@ func (c *Client) queryCostsForSubscriptionInternal(ctx context.Context, sub conf
 	startDate = time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC)
 	endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, time.UTC)
 
+	dataset, excludes := c.costDataset(target)
+	return c.queryDaily(ctx, target, costType, dataset, excludes, startDate, endDate)
+}
+
+// queryUsageForTargetInternal queries today's usage quantities per meter and unit without retry logic
+// Usage metrics only cover the current day, so the extra groupings don't multiply the rows
+// of the whole date range, and the cost queries keep the configured grouping alone
+func (c *Client) queryUsageForTargetInternal(ctx context.Context, target queryTarget, costType string) ([]provider.CostRecord, error) {
+	now := c.clock.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	dataset, excludes := c.usageDataset(target)
+	return c.queryDaily(ctx, target, costType, dataset, excludes, today, today)
+}
+
+// queryDaily runs a daily query of the dataset over a date range and parses its records
+func (c *Client) queryDaily(ctx context.Context, target queryTarget, costType string, dataset *armcostmanagement.QueryDataset, excludes []config.Filter, startDate, endDate time.Time) ([]provider.CostRecord, error) {
+	sub := target.account
+
 	c.logger.Debug("Querying Azure Cost Management API",
 		"subscription", sub.Name,
+		"scope", target.scope,
+		"cost_type", costType,
 		"start_date", startDate.Format("2006-01-02"),
 		"end_date", endDate.Format("2006-01-02"),
 		"current_time", c.clock.Now().Format("2006-01-02 15:04:05 MST"))
 
+	// Build query definition
+	queryType := exportType(costType)
+	timeframe := armcostmanagement.TimeframeTypeCustom
+	granularity := armcostmanagement.GranularityTypeDaily
+	dataset.Granularity = &granularity
+
+	queryDef := armcostmanagement.QueryDefinition{
+		Type:      &queryType,
+		Timeframe: &timeframe,
+		TimePeriod: &armcostmanagement.QueryTimePeriod{
+			From: &startDate,
+			To:   &endDate,
+		},
+		Dataset: dataset,
+	}
+
+	// Execute query (following NextLink pagination)
+	result, err := c.queryAllPages(ctx, target.tenant, target.scope, queryDef, sub)
+	if err != nil {
+		return nil, fmt.Errorf("cost query failed for date range %s to %s: %w",
+			startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), err)
+	}
+
+	// Parse response and tag records with the cost basis and tenant they were queried with
+	records := excludeRecords(c.parseResponse(result, sub), excludes)
+	for i := range records {
+		records[i].CostType = costType
+		records[i].TenantID = target.tenant.cfg.ID
+	}
+	return records, nil
+}
+
+// costDataset builds the aggregation, grouping and server-side filter of a cost query for a target
+// Negated filters can't be sent to the API and are returned to be applied to the parsed records
+func (c *Client) costDataset(target queryTarget) (*armcostmanagement.QueryDataset, []config.Filter) {
 	// Build grouping
 	var grouping []*armcostmanagement.QueryGrouping
 	if c.cfg.GroupBy.Enabled {
+		seen := make(map[string]bool, len(c.cfg.GroupBy.Groups))
 		for _, g := range c.cfg.GroupBy.Groups {
+			// A derived dimension and its source share one grouping
+			name := groupingName(g)
+			if seen[g.Type+"/"+name] {
+				continue
+			}
+			seen[g.Type+"/"+name] = true
+
 			groupType := armcostmanagement.QueryColumnType(g.Type)
 			grouping = append(grouping, &armcostmanagement.QueryGrouping{
 				Type: &groupType,
-				Name: &g.Name,
+				Name: stringPtr(name),
 			})
 		}
 	}
 
-	// Build query definition
-	scope := fmt.Sprintf("/subscriptions/%s", sub.ID)
-	queryType := armcostmanagement.ExportTypeActualCost
-	timeframe := armcostmanagement.TimeframeTypeCustom
-	granularity := armcostmanagement.GranularityTypeDaily
+	// Scopes spanning several subscriptions are split per subscription
+	// so rows can be attributed to their account
+	if target.aggregate {
+		grouping = append(grouping, subscriptionGrouping()...)
+	}
+
+	filter, excludes := c.cfg.FilterFor(target.filter).Split()
 
 	aggregation := map[string]*armcostmanagement.QueryAggregation{
 		"totalCost": {
-			Name:     stringPtr("Cost"),
+			Name:     stringPtr(c.costColumn()),
 			Function: functionPtr(armcostmanagement.FunctionTypeSum),
 		},
 	}
 
-	queryDef := armcostmanagement.QueryDefinition{
-		Type:      &queryType,
-		Timeframe: &timeframe,
-		TimePeriod: &armcostmanagement.QueryTimePeriod{
-			From: &startDate,
-			To:   &endDate,
-		},
-		Dataset: &armcostmanagement.QueryDataset{
-			Granularity: &granularity,
-			Aggregation: aggregation,
-			Grouping:    grouping,
-		},
+	return &armcostmanagement.QueryDataset{
+		Aggregation: aggregation,
+		Grouping:    grouping,
+		Filter:      compileFilter(filter),
+	}, excludes
+}
+
+// usageDataset builds the dataset of a usage query: the cost dataset additionally
+// grouped by meter and unit, since usage quantities are only comparable per meter and unit
+func (c *Client) usageDataset(target queryTarget) (*armcostmanagement.QueryDataset, []config.Filter) {
+	dataset, excludes := c.costDataset(target)
+	dataset.Grouping = append(dataset.Grouping, usageGrouping()...)
+	dataset.Aggregation["totalUsageQuantity"] = &armcostmanagement.QueryAggregation{
+		Name:     stringPtr("UsageQuantity"),
+		Function: functionPtr(armcostmanagement.FunctionTypeSum),
 	}
+	return dataset, excludes
+}
 
-	// Execute query
-	resp, err := c.client.Usage(ctx, scope, queryDef, nil)
-	if err != nil {
-		return nil, fmt.Errorf("cost query failed for date range %s to %s: %w",
-			startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), err)
+// queriesUsage reports whether usage is queried: usage metrics only cover the
+// current day, which is only part of the date range without end date offset
+func (c *Client) queriesUsage() bool {
+	return c.cfg.Usage.Enabled && (c.cfg.DateRange.EndDateOffset == nil || *c.cfg.DateRange.EndDateOffset == 0)
+}
+
+// subscriptionGrouping returns the groupings that attribute rows of aggregate scopes to subscriptions
+func subscriptionGrouping() []*armcostmanagement.QueryGrouping {
+	dimension := armcostmanagement.QueryColumnTypeDimension
+	return []*armcostmanagement.QueryGrouping{
+		{Type: &dimension, Name: stringPtr("SubscriptionId")},
+		{Type: &dimension, Name: stringPtr("SubscriptionName")},
 	}
+}
 
-	// Parse response
-	return c.parseResponse(resp.QueryResult, sub), nil
+// usageGrouping returns the groupings that split usage quantities by meter and unit of measure
+func usageGrouping() []*armcostmanagement.QueryGrouping {
+	dimension := armcostmanagement.QueryColumnTypeDimension
+	return []*armcostmanagement.QueryGrouping{
+		{Type: &dimension, Name: stringPtr("Meter")},
+		{Type: &dimension, Name: stringPtr("UnitOfMeasure")},
+	}
+}
+
+// groupingName returns the name sent to the API for a group_by entry
+// Derived dimensions are grouped by the dimension they are derived from
+// Dimension aliases are resolved to their canonical Azure dimension name
+func groupingName(g config.GroupBy) string {
+	if g.Type == config.GroupTypeDimension {
+		if dim, ok := provider.LookupDimension(g.Name); ok {
+			return dim.QueryName()
+		}
+	}
+	return g.Name
 }
 
 // buildColumnMap creates a map of column names to their indices
@@ -230,6 +553,46 @@ func buildColumnMap(columns []*armcostmanagement.QueryColumn) map[string]int {
 	return columnMap
 }
 
+// costColumn returns the cost column aggregated by queries
+func (c *Client) costColumn() string {
+	if c.cfg.CostInUSD {
+		return "CostUSD"
+	}
+	return "Cost"
+}
+
+// costColumns returns the response columns holding the cost, in order of preference
+// Pay-as-you-go subscriptions report pre-tax costs instead
+func (c *Client) costColumns() []string {
+	if c.cfg.CostInUSD {
+		return []string{"CostUSD", "PreTaxCostUSD"}
+	}
+	return []string{"Cost", "PreTaxCost"}
+}
+
+// findColumn returns the index of the first of the given columns present in the response
+func findColumn(columnMap map[string]int, names []string) (int, bool) {
+	for _, name := range names {
+		if idx, ok := columnMap[name]; ok {
+			return idx, true
+		}
+	}
+	return -1, false
+}
+
+// rowCurrency returns the currency of a row's cost
+// USD costs are always in US dollars; otherwise the response's Currency column is
+// used, falling back to the configured currency if the response carries none
+func (c *Client) rowCurrency(row []interface{}, columnMap map[string]int) string {
+	if c.cfg.CostInUSD {
+		return currencyUSD
+	}
+	if currency := getStringFromRow(row, columnMap, currencyColumn); currency != "" {
+		return currency
+	}
+	return c.cfg.Currency
+}
+
 // getStringFromRow extracts a string value from a row by column name
 func getStringFromRow(row []interface{}, columnMap map[string]int, columnName string) string {
 	if idx, ok := columnMap[columnName]; ok && len(row) > idx {
@@ -255,6 +618,14 @@ func parseCost(value interface{}) float64 {
 	}
 }
 
+// parseUsageQuantity extracts the aggregated usage quantity of a row (0 if not queried)
+func parseUsageQuantity(row []interface{}, columnMap map[string]int) float64 {
+	if idx, ok := columnMap["UsageQuantity"]; ok && len(row) > idx {
+		return parseCost(row[idx])
+	}
+	return 0
+}
+
 // formatDateValue converts various date types to string
 func formatDateValue(value interface{}) string {
 	switch v := value.(type) {
@@ -342,19 +713,70 @@ func extractResourceGroup(row []interface{}, columnMap map[string]int) string {
 	return getStringFromRow(row, columnMap, "ResourceGroupName")
 }
 
+// extractAccount returns the account a row belongs to
+// Rows from scopes above subscription level carry SubscriptionId/SubscriptionName
+// columns, which take precedence over the queried account
+func extractAccount(row []interface{}, columnMap map[string]int, sub config.Subscription) (string, string) {
+	accountID := sub.ID
+	accountName := sub.Name
+
+	if id := getStringFromRow(row, columnMap, "SubscriptionId"); id != "" {
+		accountID = id
+		// The scope name doesn't describe an individual subscription
+		accountName = id
+	}
+	if name := getStringFromRow(row, columnMap, "SubscriptionName"); name != "" {
+		accountName = name
+	}
+
+	return accountID, accountName
+}
+
+// extractTags extracts configured tag values from a row
+// Azure returns tag groupings either as a column named after the tag key or
+// as a TagKey/TagValue column pair, so both layouts are supported
+func extractTags(row []interface{}, columnMap map[string]int, tagKeys []string) map[string]string {
+	if len(tagKeys) == 0 {
+		return nil
+	}
+
+	tags := make(map[string]string, len(tagKeys))
+	rowTagKey := getStringFromRow(row, columnMap, "TagKey")
+	rowTagValue := getStringFromRow(row, columnMap, "TagValue")
+
+	for _, key := range tagKeys {
+		if _, ok := columnMap[key]; ok {
+			tags[key] = getStringFromRow(row, columnMap, key)
+			continue
+		}
+		value := ""
+		if strings.EqualFold(rowTagKey, key) {
+			value = rowTagValue
+		}
+		tags[key] = value
+	}
+
+	return tags
+}
+
 // parseRow parses a single row from the Azure API response
-func (c *Client) parseRow(row []interface{}, columnMap map[string]int, costIdx, dateIdx int, sub config.Subscription) provider.CostRecord {
+// dateIdx is negative for responses without daily granularity, whose records carry no date
+func (c *Client) parseRow(row []interface{}, columnMap map[string]int, costIdx, dateIdx int, sub config.Subscription, tagKeys []string) provider.CostRecord {
 	cost := parseCost(row[costIdx])
-	date := parseDate(row[dateIdx])
+	date := ""
+	if dateIdx >= 0 {
+		date = parseDate(row[dateIdx])
+	}
 
 	service := extractService(row, columnMap)
 	resourceId, resourceName := extractResourceInfo(row, columnMap)
+	accountID, accountName := extractAccount(row, columnMap, sub)
 
 	return provider.CostRecord{
 		Date:             date,
 		Provider:         string(provider.ProviderAzure),
-		AccountID:        sub.ID,
-		AccountName:      sub.Name,
+		AccountID:        accountID,
+		AccountName:      accountName,
 		Service:          service,
 		ResourceType:     getStringFromRow(row, columnMap, "ResourceType"),
 		ResourceGroup:    extractResourceGroup(row, columnMap),
@@ -365,13 +787,23 @@ func (c *Client) parseRow(row []interface{}, columnMap map[string]int, costIdx,
 		MeterSubCategory: getStringFromRow(row, columnMap, "MeterSubCategory"),
 		ChargeType:       getStringFromRow(row, columnMap, "ChargeType"),
 		PricingModel:     getStringFromRow(row, columnMap, "PricingModel"),
+		Meter:            getStringFromRow(row, columnMap, "Meter"),
+		UnitOfMeasure:    getStringFromRow(row, columnMap, "UnitOfMeasure"),
+		UsageQuantity:    parseUsageQuantity(row, columnMap),
+		Tags:             extractTags(row, columnMap, tagKeys),
 		Cost:             cost,
-		Currency:         c.cfg.Currency,
+		Currency:         c.rowCurrency(row, columnMap),
 	}
 }
 
 // parseResponse converts Azure API response to CostRecords
 func (c *Client) parseResponse(result armcostmanagement.QueryResult, sub config.Subscription) []provider.CostRecord {
+	return c.parseRows(result, sub, true)
+}
+
+// parseRows converts the rows of a query response to CostRecords
+// Daily responses must carry a UsageDate column; totals over a timeframe carry none
+func (c *Client) parseRows(result armcostmanagement.QueryResult, sub config.Subscription, daily bool) []provider.CostRecord {
 	var records []provider.CostRecord
 
 	if result.Properties == nil || result.Properties.Rows == nil {
@@ -382,12 +814,17 @@ func (c *Client) parseResponse(result armcostmanagement.QueryResult, sub config.
 	columnMap := buildColumnMap(result.Properties.Columns)
 
 	// Verify required columns exist
-	costIdx, hasCost := columnMap["Cost"]
+	costIdx, hasCost := findColumn(columnMap, c.costColumns())
 	dateIdx, hasDate := columnMap["UsageDate"]
 
-	if !hasCost || !hasDate {
+	if !hasCost || (daily && !hasDate) {
 		return records
 	}
+	if !hasDate {
+		dateIdx = -1
+	}
+
+	tagKeys := c.cfg.GroupBy.TagKeys()
 
 	// Parse each row
 	for _, row := range result.Properties.Rows {
@@ -395,7 +832,7 @@ func (c *Client) parseResponse(result armcostmanagement.QueryResult, sub config.
 			continue
 		}
 
-		record := c.parseRow(row, columnMap, costIdx, dateIdx, sub)
+		record := c.parseRow(row, columnMap, costIdx, dateIdx, sub, tagKeys)
 		records = append(records, record)
 	}
 
@@ -1,13 +1,27 @@
 package azure
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
 	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/logger"
 )
 
 // TestParseResponse_FullResponse tests parsing a complete Azure API response with all dimensions
@@ -723,6 +737,305 @@ func TestParseResponse_ShortRow(t *testing.T) {
 	}
 }
 
+// TestParseResponse_TagGrouping tests extraction of tag values for TagKey groupings
+func TestParseResponse_TagGrouping(t *testing.T) {
+	client, sub := setupTestClient(t)
+	client.cfg.GroupBy = config.GroupByConfig{
+		Enabled: true,
+		Groups: []config.GroupBy{
+			{Type: config.GroupTypeTagKey, Name: "team"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		columns  []*armcostmanagement.QueryColumn
+		row      []interface{}
+		expected string
+	}{
+		{
+			name: "TagKey/TagValue columns",
+			columns: []*armcostmanagement.QueryColumn{
+				{Name: stringPtr("Cost"), Type: stringPtr("Number")},
+				{Name: stringPtr("UsageDate"), Type: stringPtr("Number")},
+				{Name: stringPtr("TagKey"), Type: stringPtr("String")},
+				{Name: stringPtr("TagValue"), Type: stringPtr("String")},
+			},
+			row:      []interface{}{10.0, 20260115, "Team", "platform"},
+			expected: "platform",
+		},
+		{
+			name: "untagged resource",
+			columns: []*armcostmanagement.QueryColumn{
+				{Name: stringPtr("Cost"), Type: stringPtr("Number")},
+				{Name: stringPtr("UsageDate"), Type: stringPtr("Number")},
+				{Name: stringPtr("TagKey"), Type: stringPtr("String")},
+				{Name: stringPtr("TagValue"), Type: stringPtr("String")},
+			},
+			row:      []interface{}{10.0, 20260115, "", ""},
+			expected: "",
+		},
+		{
+			name: "column named after tag key",
+			columns: []*armcostmanagement.QueryColumn{
+				{Name: stringPtr("Cost"), Type: stringPtr("Number")},
+				{Name: stringPtr("UsageDate"), Type: stringPtr("Number")},
+				{Name: stringPtr("team"), Type: stringPtr("String")},
+			},
+			row:      []interface{}{10.0, 20260115, "data"},
+			expected: "data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := armcostmanagement.QueryResult{
+				Properties: &armcostmanagement.QueryProperties{
+					Columns: tt.columns,
+					Rows:    [][]interface{}{tt.row},
+				},
+			}
+
+			records := client.parseResponse(result, sub)
+
+			if len(records) != 1 {
+				t.Fatalf("Expected 1 record, got %d", len(records))
+			}
+
+			value, ok := records[0].Tags["team"]
+			if !ok {
+				t.Fatalf("Tags should contain configured key 'team', got %v", records[0].Tags)
+			}
+			if value != tt.expected {
+				t.Errorf("Tag value: got %q, want %q", value, tt.expected)
+			}
+		})
+	}
+}
+
+// TestGroupingName tests that dimension aliases are resolved to canonical Azure names
+func TestGroupingName(t *testing.T) {
+	tests := []struct {
+		group config.GroupBy
+		want  string
+	}{
+		{config.GroupBy{Type: config.GroupTypeDimension, Name: "ResourceGroupName"}, "ResourceGroup"},
+		{config.GroupBy{Type: config.GroupTypeDimension, Name: "meter_category"}, "MeterCategory"},
+		{config.GroupBy{Type: config.GroupTypeDimension, Name: "ServiceName"}, "ServiceName"},
+		{config.GroupBy{Type: config.GroupTypeDimension, Name: "ResourceName"}, "ResourceId"},
+		{config.GroupBy{Type: config.GroupTypeTagKey, Name: "cost-center"}, "cost-center"},
+	}
+
+	for _, tt := range tests {
+		if got := groupingName(tt.group); got != tt.want {
+			t.Errorf("groupingName(%q) = %q, want %q", tt.group.Name, got, tt.want)
+		}
+	}
+}
+
+// TestCostDataset_DerivedDimension tests that a derived dimension and its source share one grouping
+func TestCostDataset_DerivedDimension(t *testing.T) {
+	cfg := testQueryConfig()
+	cfg.GroupBy = config.GroupByConfig{Enabled: true, Groups: []config.GroupBy{
+		{Type: config.GroupTypeDimension, Name: "ResourceId"},
+		{Type: config.GroupTypeDimension, Name: "ResourceName"},
+	}}
+
+	dataset, _ := (&Client{cfg: cfg}).costDataset(queryTarget{})
+	if len(dataset.Grouping) != 1 || *dataset.Grouping[0].Name != "ResourceId" {
+		t.Errorf("Grouping = %+v, want ResourceId once", dataset.Grouping)
+	}
+}
+
+// TestParseResponse_Currency tests that the currency is taken from each row,
+// falling back to the configured currency, and that USD costs are reported in USD
+func TestParseResponse_Currency(t *testing.T) {
+	tests := []struct {
+		name      string
+		costInUSD bool
+		columns   []string
+		row       []interface{}
+		wantCost  float64
+		want      string
+	}{
+		{"currency column", false, []string{"Cost", "UsageDate", "Currency"}, []interface{}{1.5, 20260115, "USD"}, 1.5, "USD"},
+		{"no currency column", false, []string{"Cost", "UsageDate"}, []interface{}{1.5, 20260115}, 1.5, "€"},
+		{"empty currency", false, []string{"Cost", "UsageDate", "Currency"}, []interface{}{1.5, 20260115, ""}, 1.5, "€"},
+		{"pre-tax cost", false, []string{"PreTaxCost", "UsageDate", "Currency"}, []interface{}{2.5, 20260115, "EUR"}, 2.5, "EUR"},
+		{"usd cost", true, []string{"CostUSD", "UsageDate", "Currency"}, []interface{}{3.5, 20260115, "EUR"}, 3.5, "USD"},
+		{"usd pre-tax cost", true, []string{"PreTaxCostUSD", "UsageDate"}, []interface{}{4.5, 20260115}, 4.5, "USD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, sub := setupTestClient(t)
+			client.cfg.CostInUSD = tt.costInUSD
+
+			result := armcostmanagement.QueryResult{
+				Properties: &armcostmanagement.QueryProperties{Rows: [][]interface{}{tt.row}},
+			}
+			for _, name := range tt.columns {
+				result.Properties.Columns = append(result.Properties.Columns, &armcostmanagement.QueryColumn{Name: stringPtr(name)})
+			}
+
+			records := client.parseResponse(result, sub)
+			if len(records) != 1 {
+				t.Fatalf("Expected 1 record, got %d", len(records))
+			}
+			if records[0].Cost != tt.wantCost || records[0].Currency != tt.want {
+				t.Errorf("Cost/Currency = %v/%q, want %v/%q", records[0].Cost, records[0].Currency, tt.wantCost, tt.want)
+			}
+		})
+	}
+}
+
+// TestQueryCosts_CostTypeFailure tests that a failing amortized query keeps the actual records of the subscription
+func TestQueryCosts_CostTypeFailure(t *testing.T) {
+	cfg := testQueryConfig()
+	cfg.CostType = config.CostTypeBoth
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var def armcostmanagement.QueryDefinition
+		if err := json.NewDecoder(r.Body).Decode(&def); err != nil {
+			t.Errorf("Failed to decode query: %v", err)
+		}
+		if *def.Type == armcostmanagement.ExportTypeAmortizedCost {
+			http.Error(w, `{"error":{"code":"BadRequest","message":"amortized cost not supported"}}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"UsageDate","type":"Number"}],"rows":[[1.0,20260115]]}}`))
+	})
+	client, _ := newTestServerClient(t, cfg, handler)
+
+	result, err := client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v, want partial data", err)
+	}
+
+	if len(result.Records) != 1 || result.Records[0].CostType != config.CostTypeActual {
+		t.Errorf("Records = %+v, want the actual record", result.Records)
+	}
+	if len(result.Accounts) != 1 {
+		t.Fatalf("Got %d account results, want 1", len(result.Accounts))
+	}
+	account := result.Accounts[0]
+	if account.Err == nil || !strings.Contains(account.Err.Error(), "amortized cost query failed") || account.Rows != 1 {
+		t.Errorf("Account result = %+v, want amortized failure with 1 row", account)
+	}
+}
+
+// TestQueryCosts_CostInUSD tests that USD reporting aggregates the USD cost column
+func TestQueryCosts_CostInUSD(t *testing.T) {
+	cfg := testQueryConfig()
+	cfg.CostInUSD = true
+
+	var def armcostmanagement.QueryDefinition
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&def); err != nil {
+			t.Errorf("Failed to decode query: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"properties":{"columns":[{"name":"CostUSD","type":"Number"},{"name":"UsageDate","type":"Number"}],"rows":[[1.0,20260115]]}}`))
+	})
+	client, _ := newTestServerClient(t, cfg, handler)
+
+	result, err := client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+
+	if agg := def.Dataset.Aggregation["totalCost"]; agg == nil || *agg.Name != "CostUSD" {
+		t.Errorf("Cost aggregation = %+v, want CostUSD", agg)
+	}
+	if len(result.Records) != 1 || result.Records[0].Currency != "USD" {
+		t.Errorf("Records = %+v, want one USD record", result.Records)
+	}
+}
+
+// TestQueryCosts_Concurrent tests that subscriptions are queried in parallel up to
+// max_concurrent_queries and that results keep the configured order
+func TestQueryCosts_Concurrent(t *testing.T) {
+	const subscriptions = 6
+
+	var (
+		inFlight    atomic.Int32
+		maxInFlight atomic.Int32
+	)
+
+	cfg := testQueryConfig()
+	cfg.MaxConcurrentQueries = 2
+	cfg.Subscriptions = nil
+	for i := range subscriptions {
+		cfg.Subscriptions = append(cfg.Subscriptions, config.Subscription{
+			ID:   fmt.Sprintf("sub-%d", i),
+			Name: fmt.Sprintf("subscription-%d", i),
+		})
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			peak := maxInFlight.Load()
+			if n <= peak || maxInFlight.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+
+		// Earlier subscriptions answer slower, so completion order differs from configured order
+		id := strings.Split(r.URL.Path, "/")[2]
+		idx, _ := strconv.Atoi(strings.TrimPrefix(id, "sub-"))
+		time.Sleep(time.Duration(subscriptions-idx) * 10 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"UsageDate","type":"Number"}],"rows":[[1.0,20260115]]}}`))
+	})
+
+	client, _ := newTestServerClient(t, cfg, handler)
+
+	result, err := client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+	records := result.Records
+
+	if len(records) != subscriptions {
+		t.Fatalf("Expected %d records, got %d", subscriptions, len(records))
+	}
+	for i, r := range records {
+		if want := fmt.Sprintf("sub-%d", i); r.AccountID != want {
+			t.Errorf("Record %d AccountID = %s, want %s (configured order)", i, r.AccountID, want)
+		}
+	}
+
+	if peak := maxInFlight.Load(); peak > 2 {
+		t.Errorf("Expected at most 2 concurrent queries, got %d", peak)
+	} else if peak < 2 {
+		t.Errorf("Expected queries to run concurrently, peak was %d", peak)
+	}
+}
+
+// TestQueryCosts_CancelledContext tests that no queries are sent once the refresh is cancelled
+func TestQueryCosts_CancelledContext(t *testing.T) {
+	var requests atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	})
+
+	client, _ := newTestServerClient(t, testQueryConfig(), handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.QueryCosts(ctx); err == nil {
+		t.Error("Expected error for cancelled context")
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("Expected no requests after cancellation, got %d", n)
+	}
+}
+
 // Helper functions
 
 func setupTestClient(t *testing.T) (*Client, config.Subscription) {
@@ -736,8 +1049,7 @@ func setupTestClient(t *testing.T) (*Client, config.Subscription) {
 	}
 
 	client := &Client{
-		client: nil, // We don't need actual Azure client for parseResponse tests
-		cfg:    cfg,
+		cfg: cfg,
 	}
 
 	sub := cfg.Subscriptions[0]
@@ -745,6 +1057,46 @@ func setupTestClient(t *testing.T) (*Client, config.Subscription) {
 	return client, sub
 }
 
+// fakeCredentials is a credentialFactory returning a static test credential for every tenant
+func fakeCredentials(config.AuthConfig) (azcore.TokenCredential, error) {
+	return &fake.TokenCredential{}, nil
+}
+
+// newTestServerClient creates a Client whose ARM requests are served by handler
+// The returned server URL stands in for the Azure Resource Manager endpoint
+func newTestServerClient(t *testing.T, cfg *config.Config, handler http.Handler) (*Client, string) {
+	t.Helper()
+	return newTestServerClientWithCredentials(t, cfg, fakeCredentials, handler)
+}
+
+// newTestServerClientWithCredentials is newTestServerClient with a custom credential factory
+func newTestServerClientWithCredentials(t *testing.T, cfg *config.Config, newCred credentialFactory, handler http.Handler) (*Client, string) {
+	t.Helper()
+
+	srv := httptest.NewTLSServer(handler)
+	t.Cleanup(srv.Close)
+
+	opts := &arm.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Cloud: cloud.Configuration{
+				ActiveDirectoryAuthorityHost: srv.URL,
+				Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+					cloud.ResourceManager: {Endpoint: srv.URL, Audience: "https://management.azure.com"},
+				},
+			},
+			Transport: srv.Client(),
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+	}
+
+	client, err := newClient(cfg, logger.New("error"), newCred, opts)
+	if err != nil {
+		t.Fatalf("Failed to create test client: %v", err)
+	}
+
+	return client, srv.URL
+}
+
 func loadMockResponse(t *testing.T, filename string) armcostmanagement.QueryResult {
 	t.Helper()
 
@@ -0,0 +1,114 @@
+package azure
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+)
+
+// newCredential creates the Azure credential selected by the auth configuration
+// opts.Cloud selects the authority host the credential authenticates against
+func newCredential(auth config.AuthConfig, opts policy.ClientOptions) (azcore.TokenCredential, error) {
+	disableDiscovery := !isWellKnownAuthority(opts.Cloud.ActiveDirectoryAuthorityHost)
+
+	switch auth.Type {
+	case "", config.AuthTypeDefault:
+		return azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+			ClientOptions:            opts,
+			TenantID:                 auth.TenantID,
+			DisableInstanceDiscovery: disableDiscovery,
+		})
+
+	case config.AuthTypeClientSecret:
+		return azidentity.NewClientSecretCredential(auth.TenantID, auth.ClientID, auth.ClientSecret,
+			&azidentity.ClientSecretCredentialOptions{ClientOptions: opts, DisableInstanceDiscovery: disableDiscovery})
+
+	case config.AuthTypeClientCertificate:
+		// #nosec G304 -- Certificate path is provided by administrator via config file
+		data, err := os.ReadFile(auth.CertificatePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client certificate: %w", err)
+		}
+		var password []byte
+		if auth.CertificatePassword != "" {
+			password = []byte(auth.CertificatePassword)
+		}
+		certs, key, err := azidentity.ParseCertificates(data, password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse client certificate %s: %w", auth.CertificatePath, err)
+		}
+		return azidentity.NewClientCertificateCredential(auth.TenantID, auth.ClientID, certs, key,
+			&azidentity.ClientCertificateCredentialOptions{ClientOptions: opts, DisableInstanceDiscovery: disableDiscovery})
+
+	case config.AuthTypeWorkloadIdentity:
+		return azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions:            opts,
+			ClientID:                 auth.ClientID,
+			TenantID:                 auth.TenantID,
+			TokenFilePath:            auth.TokenFilePath,
+			DisableInstanceDiscovery: disableDiscovery,
+		})
+
+	case config.AuthTypeManagedIdentity:
+		miOpts := &azidentity.ManagedIdentityCredentialOptions{ClientOptions: opts}
+		switch {
+		case auth.ClientID != "":
+			miOpts.ID = azidentity.ClientID(auth.ClientID)
+		case auth.ResourceID != "":
+			miOpts.ID = azidentity.ResourceID(auth.ResourceID)
+		}
+		return azidentity.NewManagedIdentityCredential(miOpts)
+
+	case config.AuthTypeAzureCLI:
+		return azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{TenantID: auth.TenantID})
+
+	case config.AuthTypeChained:
+		sources := make([]azcore.TokenCredential, 0, len(auth.Chain))
+		for i, entry := range auth.Chain {
+			cred, err := newCredential(entry, opts)
+			if err != nil {
+				return nil, fmt.Errorf("auth chain entry %d (%s): %w", i, entry.Type, err)
+			}
+			sources = append(sources, cred)
+		}
+		return azidentity.NewChainedTokenCredential(sources, nil)
+
+	default:
+		return nil, fmt.Errorf("unsupported auth type %q", auth.Type)
+	}
+}
+
+// describeAuth returns log attributes identifying the selected credential
+// Secrets and certificate passwords are never included
+func describeAuth(auth config.AuthConfig) []any {
+	if auth.Type == config.AuthTypeChained {
+		types := make([]string, len(auth.Chain))
+		for i, entry := range auth.Chain {
+			types[i] = entry.Type
+		}
+		return []any{"auth_type", auth.Type, "chain", strings.Join(types, ",")}
+	}
+
+	attrs := []any{"auth_type", auth.Type}
+	if auth.Type == "" {
+		attrs[1] = config.AuthTypeDefault
+	}
+	if auth.TenantID != "" {
+		attrs = append(attrs, "tenant_id", auth.TenantID)
+	}
+	if auth.ClientID != "" {
+		attrs = append(attrs, "client_id", auth.ClientID)
+	}
+	if auth.ResourceID != "" {
+		attrs = append(attrs, "resource_id", auth.ResourceID)
+	}
+	if auth.CertificatePath != "" {
+		attrs = append(attrs, "certificate_path", auth.CertificatePath)
+	}
+	return attrs
+}
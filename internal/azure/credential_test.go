@@ -0,0 +1,121 @@
+package azure
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+)
+
+// writeTestCertificate writes a self-signed certificate and its key as PEM
+func writeTestCertificate(t *testing.T) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "azure-cost-exporter-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})...)
+
+	path := filepath.Join(t.TempDir(), "client.pem")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	return path
+}
+
+// TestNewCredential tests that every auth type builds a credential
+func TestNewCredential(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("federated-token"), 0600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+	certPath := writeTestCertificate(t)
+
+	const (
+		tenantID = "00000000-0000-0000-0000-000000000001"
+		clientID = "00000000-0000-0000-0000-000000000002"
+	)
+
+	tests := []struct {
+		name    string
+		auth    config.AuthConfig
+		wantErr bool
+	}{
+		{"default", config.AuthConfig{Type: config.AuthTypeDefault}, false},
+		{"client secret", config.AuthConfig{Type: config.AuthTypeClientSecret, TenantID: tenantID, ClientID: clientID, ClientSecret: "secret"}, false},
+		{"client certificate", config.AuthConfig{Type: config.AuthTypeClientCertificate, TenantID: tenantID, ClientID: clientID, CertificatePath: certPath}, false},
+		{"missing certificate", config.AuthConfig{Type: config.AuthTypeClientCertificate, TenantID: tenantID, ClientID: clientID, CertificatePath: "/nonexistent.pem"}, true},
+		{"workload identity", config.AuthConfig{Type: config.AuthTypeWorkloadIdentity, TenantID: tenantID, ClientID: clientID, TokenFilePath: tokenFile}, false},
+		{"managed identity client ID", config.AuthConfig{Type: config.AuthTypeManagedIdentity, ClientID: clientID}, false},
+		{"managed identity resource ID", config.AuthConfig{Type: config.AuthTypeManagedIdentity, ResourceID: "/subscriptions/x/resourceGroups/rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/exporter"}, false},
+		{"azure cli", config.AuthConfig{Type: config.AuthTypeAzureCLI}, false},
+		{"chained", config.AuthConfig{Type: config.AuthTypeChained, Chain: []config.AuthConfig{
+			{Type: config.AuthTypeManagedIdentity, ClientID: clientID},
+			{Type: config.AuthTypeAzureCLI},
+		}}, false},
+		{"chained with broken entry", config.AuthConfig{Type: config.AuthTypeChained, Chain: []config.AuthConfig{
+			{Type: config.AuthTypeClientCertificate, TenantID: tenantID, ClientID: clientID, CertificatePath: "/nonexistent.pem"},
+		}}, true},
+		{"unknown", config.AuthConfig{Type: "password"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cred, err := newCredential(tt.auth, policy.ClientOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newCredential() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cred == nil {
+				t.Error("newCredential() returned nil credential")
+			}
+		})
+	}
+}
+
+// TestDescribeAuth tests the startup log attributes of the selected credential
+func TestDescribeAuth(t *testing.T) {
+	attrs := describeAuth(config.AuthConfig{
+		Type:         config.AuthTypeClientSecret,
+		TenantID:     "tenant",
+		ClientID:     "client",
+		ClientSecret: "super-secret",
+	})
+	if got := fmt.Sprint(attrs...); got != fmt.Sprint("auth_type", "client_secret", "tenant_id", "tenant", "client_id", "client") {
+		t.Errorf("describeAuth() = %v", attrs)
+	}
+
+	chained := describeAuth(config.AuthConfig{Type: config.AuthTypeChained, Chain: []config.AuthConfig{
+		{Type: config.AuthTypeWorkloadIdentity},
+		{Type: config.AuthTypeManagedIdentity},
+	}})
+	if got := fmt.Sprint(chained...); got != fmt.Sprint("auth_type", "chained", "chain", "workload_identity,managed_identity") {
+		t.Errorf("describeAuth() = %v", chained)
+	}
+}
@@ -0,0 +1,182 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+)
+
+// subscriptionsAPIVersion is the ARM API version used to list subscriptions
+const subscriptionsAPIVersion = "2022-12-01"
+
+// armSubscription is a subscription as returned by the ARM subscriptions API
+type armSubscription struct {
+	SubscriptionID string            `json:"subscriptionId"`
+	DisplayName    string            `json:"displayName"`
+	State          string            `json:"state"`
+	Tags           map[string]string `json:"tags"`
+}
+
+// subscriptionFilter selects discovered subscriptions
+type subscriptionFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+	states  map[string]bool
+	tags    map[string]string
+}
+
+// discoveryState holds the result of the last discovery run
+type discoveryState struct {
+	mu            sync.RWMutex
+	subscriptions []config.Subscription
+	lastRun       time.Time
+}
+
+// newSubscriptionFilter compiles the discovery filter settings
+func newSubscriptionFilter(d config.DiscoveryConfig) (*subscriptionFilter, error) {
+	f := &subscriptionFilter{
+		states: make(map[string]bool, len(d.States)),
+		tags:   d.Tags,
+	}
+
+	for _, pattern := range d.Include {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include regex %q: %w", pattern, err)
+		}
+		f.include = append(f.include, re)
+	}
+	for _, pattern := range d.Exclude {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude regex %q: %w", pattern, err)
+		}
+		f.exclude = append(f.exclude, re)
+	}
+	for _, state := range d.States {
+		f.states[state] = true
+	}
+
+	return f, nil
+}
+
+// matches reports whether a subscription passes the filter
+func (f *subscriptionFilter) matches(sub armSubscription) bool {
+	if len(f.states) > 0 && !f.states[sub.State] {
+		return false
+	}
+
+	for key, value := range f.tags {
+		if sub.Tags[key] != value {
+			return false
+		}
+	}
+
+	matchesAny := func(patterns []*regexp.Regexp) bool {
+		for _, re := range patterns {
+			if re.MatchString(sub.DisplayName) || re.MatchString(sub.SubscriptionID) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(f.include) > 0 && !matchesAny(f.include) {
+		return false
+	}
+	return !matchesAny(f.exclude)
+}
+
+// listSubscriptions lists all subscriptions visible to the tenant's credential, following nextLink
+func (c *Client) listSubscriptions(ctx context.Context, t *tenant) ([]armSubscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.apiTimeout())
+	defer cancel()
+
+	link := runtime.JoinPaths(t.endpoint, "/subscriptions") + "?api-version=" + subscriptionsAPIVersion
+	return listAll[armSubscription](ctx, t, link)
+}
+
+// refreshDiscovery re-runs subscription discovery of a tenant when the discovery interval has elapsed
+// On failure the previously discovered subscriptions are kept and the classified error is returned
+func (c *Client) refreshDiscovery(ctx context.Context, t *tenant) error {
+	if !t.cfg.Discovery.Enabled {
+		return nil
+	}
+
+	t.discovery.mu.RLock()
+	lastRun := t.discovery.lastRun
+	t.discovery.mu.RUnlock()
+
+	interval := time.Duration(t.cfg.Discovery.Interval) * time.Second
+	if !lastRun.IsZero() && c.clock.Now().Sub(lastRun) < interval {
+		return nil
+	}
+
+	all, err := c.listSubscriptions(ctx, t)
+	if err != nil {
+		c.logger.Warn("Subscription discovery failed, keeping previous results",
+			"tenant_id", t.cfg.ID,
+			"error", err)
+		return classifyError(err)
+	}
+
+	var discovered []config.Subscription
+	for _, sub := range all {
+		if t.discoveryFilter.matches(sub) {
+			name := sub.DisplayName
+			if name == "" {
+				name = sub.SubscriptionID
+			}
+			discovered = append(discovered, config.Subscription{ID: sub.SubscriptionID, Name: name})
+		}
+	}
+
+	t.discovery.mu.Lock()
+	t.discovery.subscriptions = discovered
+	t.discovery.lastRun = c.clock.Now()
+	t.discovery.mu.Unlock()
+
+	c.logger.Info("Subscription discovery completed",
+		"tenant_id", t.cfg.ID,
+		"visible_subscriptions", len(all),
+		"discovered_subscriptions", len(discovered))
+	return nil
+}
+
+// subscriptions returns the tenant's configured subscriptions merged with discovered ones
+// Configured entries take precedence; empty names are filled from discovered display names
+func (t *tenant) subscriptions() []config.Subscription {
+	t.discovery.mu.RLock()
+	defer t.discovery.mu.RUnlock()
+
+	displayNames := make(map[string]string, len(t.discovery.subscriptions))
+	for _, sub := range t.discovery.subscriptions {
+		displayNames[sub.ID] = sub.Name
+	}
+
+	subs := make([]config.Subscription, 0, len(t.cfg.Subscriptions)+len(t.discovery.subscriptions))
+	configured := make(map[string]bool, len(t.cfg.Subscriptions))
+	for _, sub := range t.cfg.Subscriptions {
+		if sub.Name == "" {
+			sub.Name = displayNames[sub.ID]
+		}
+		if sub.Name == "" {
+			sub.Name = sub.ID
+		}
+		configured[sub.ID] = true
+		subs = append(subs, sub)
+	}
+
+	for _, sub := range t.discovery.subscriptions {
+		if !configured[sub.ID] {
+			subs = append(subs, sub)
+		}
+	}
+
+	return subs
+}
@@ -0,0 +1,199 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/clock"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// fakeClock is a settable clock for testing interval behavior
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+// armStandIn serves the ARM subscriptions API (two pages) and cost queries
+func armStandIn(t *testing.T, listCalls *atomic.Int32, queried *[]string) http.Handler {
+	t.Helper()
+
+	pages := map[string]armList[armSubscription]{
+		"": {
+			Value: []armSubscription{
+				{SubscriptionID: "sub-prod", DisplayName: "Production", State: "Enabled", Tags: map[string]string{"costs": "on"}},
+				{SubscriptionID: "sub-dev", DisplayName: "Development", State: "Enabled", Tags: map[string]string{"costs": "on"}},
+				{SubscriptionID: "sub-old", DisplayName: "Legacy", State: "Disabled", Tags: map[string]string{"costs": "on"}},
+			},
+			NextLink: "PAGE2",
+		},
+		"2": {
+			Value: []armSubscription{
+				{SubscriptionID: "sub-sandbox", DisplayName: "Sandbox", State: "Enabled", Tags: map[string]string{"costs": "on"}},
+				{SubscriptionID: "sub-untagged", DisplayName: "Untagged", State: "Enabled"},
+			},
+		},
+	}
+
+	var serverURL string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Expected GET for subscription list, got %s", r.Method)
+		}
+		if r.URL.Query().Get("api-version") != subscriptionsAPIVersion {
+			t.Errorf("Unexpected api-version %q", r.URL.Query().Get("api-version"))
+		}
+		listCalls.Add(1)
+		if serverURL == "" {
+			serverURL = "https://" + r.Host
+		}
+
+		page := pages[r.URL.Query().Get("page")]
+		if page.NextLink != "" {
+			page.NextLink = serverURL + "/subscriptions?api-version=" + subscriptionsAPIVersion + "&page=2"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(page)
+	})
+	mux.HandleFunc("/subscriptions/", func(w http.ResponseWriter, r *http.Request) {
+		*queried = append(*queried, strings.Split(r.URL.Path, "/")[2])
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"UsageDate","type":"Number"}],"rows":[[1.0,20260115]]}}`))
+	})
+	return mux
+}
+
+// TestSubscriptionFilter tests regex, state and tag filtering
+func TestSubscriptionFilter(t *testing.T) {
+	filter, err := newSubscriptionFilter(config.DiscoveryConfig{
+		Include: []string{"^Prod", "^sub-shared$"},
+		Exclude: []string{"(?i)legacy"},
+		States:  config.DefaultDiscoveryStates,
+		Tags:    map[string]string{"team": "platform"},
+	})
+	if err != nil {
+		t.Fatalf("newSubscriptionFilter() error = %v", err)
+	}
+
+	tags := map[string]string{"team": "platform"}
+	tests := []struct {
+		name string
+		sub  armSubscription
+		want bool
+	}{
+		{"name matches include", armSubscription{SubscriptionID: "a", DisplayName: "Production", State: "Enabled", Tags: tags}, true},
+		{"ID matches include", armSubscription{SubscriptionID: "sub-shared", DisplayName: "Shared", State: "Enabled", Tags: tags}, true},
+		{"no include match", armSubscription{SubscriptionID: "b", DisplayName: "Development", State: "Enabled", Tags: tags}, false},
+		{"excluded", armSubscription{SubscriptionID: "c", DisplayName: "Prod Legacy", State: "Enabled", Tags: tags}, false},
+		{"disabled", armSubscription{SubscriptionID: "d", DisplayName: "Production 2", State: "Disabled", Tags: tags}, false},
+		{"deleted", armSubscription{SubscriptionID: "e", DisplayName: "Production 3", State: "Deleted", Tags: tags}, false},
+		{"missing tag", armSubscription{SubscriptionID: "f", DisplayName: "Production 4", State: "Enabled"}, false},
+		{"wrong tag value", armSubscription{SubscriptionID: "g", DisplayName: "Production 5", State: "Enabled", Tags: map[string]string{"team": "data"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filter.matches(tt.sub); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestQueryCosts_Discovery tests that discovered subscriptions are queried with their display names
+func TestQueryCosts_Discovery(t *testing.T) {
+	var (
+		listCalls atomic.Int32
+		queried   []string
+	)
+
+	cfg := testQueryConfig()
+	cfg.Subscriptions = []config.Subscription{{ID: "sub-dev"}} // Name filled from discovery
+	cfg.Discovery = config.DiscoveryConfig{
+		Enabled:  true,
+		Interval: 3600,
+		Exclude:  []string{"^Sandbox$"},
+		States:   config.DefaultDiscoveryStates,
+		Tags:     map[string]string{"costs": "on"},
+	}
+	client, _ := newTestServerClient(t, cfg, armStandIn(t, &listCalls, &queried))
+	clk := &fakeClock{now: time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)}
+	client.clock = clk
+
+	result, err := client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+	records := result.Records
+
+	if got := strings.Join(queried, ","); got != "sub-dev,sub-prod" {
+		t.Errorf("Queried subscriptions = %q, want configured first, then discovered: sub-dev,sub-prod", got)
+	}
+	if got := listCalls.Load(); got != 2 {
+		t.Errorf("Expected 2 subscription list requests (two pages), got %d", got)
+	}
+
+	names := map[string]string{}
+	for _, r := range records {
+		names[r.AccountID] = r.AccountName
+	}
+	if names["sub-dev"] != "Development" || names["sub-prod"] != "Production" {
+		t.Errorf("Account names = %v, want display names from discovery", names)
+	}
+	if client.AccountCount() != 2 {
+		t.Errorf("AccountCount() = %d, want 2", client.AccountCount())
+	}
+
+	// Discovery is not repeated within the interval
+	if _, err := client.QueryCosts(context.Background()); err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+	if got := listCalls.Load(); got != 2 {
+		t.Errorf("Discovery should not re-run within interval, got %d list requests", got)
+	}
+
+	// ...but is once the interval has elapsed
+	clk.now = clk.now.Add(time.Hour)
+	if _, err := client.QueryCosts(context.Background()); err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+	if got := listCalls.Load(); got != 4 {
+		t.Errorf("Discovery should re-run after interval, got %d list requests", got)
+	}
+}
+
+// TestQueryCosts_DiscoveryFailure tests that a failed discovery without any subscription to query fails the query
+func TestQueryCosts_DiscoveryFailure(t *testing.T) {
+	var listCalls atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		listCalls.Add(1)
+		http.Error(w, `{"error":{"code":"AuthorizationFailed","message":"no access"}}`, http.StatusForbidden)
+	})
+
+	cfg := testQueryConfig()
+	cfg.Subscriptions = nil
+	cfg.Discovery = config.DiscoveryConfig{Enabled: true, Interval: 3600}
+	client, _ := newTestServerClient(t, cfg, handler)
+	client.clock = clock.Fixed(time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC))
+
+	_, err := client.QueryCosts(context.Background())
+	if err == nil {
+		t.Fatal("QueryCosts() error = nil, want discovery error")
+	}
+	if reason := provider.ErrorReason(err); reason != provider.ReasonPermission {
+		t.Errorf("ErrorReason() = %q, want %q", reason, provider.ReasonPermission)
+	}
+	if got := listCalls.Load(); got != 1 {
+		t.Errorf("Expected 1 subscription list request, got %d", got)
+	}
+}
@@ -2,7 +2,7 @@
 //
 // This package implements a client for querying Azure Cost Management data
 // and parsing the results into structured cost records. It handles:
-//   - Authentication using Azure Default Credentials
+//   - Authentication with a configurable credential per tenant
 //   - Cost queries with customizable date ranges and grouping dimensions
 //   - Response parsing with support for all Azure cost dimensions
 //   - Automatic timeout handling for API calls
@@ -11,6 +11,8 @@
 //   - Client: Azure Cost Management API client
 //   - CostRecord: Represents a single cost entry with all dimensions
 //   - CostQuerier: Interface for querying costs (useful for testing)
+//   - ExportClient: Reads Cost Management scheduled exports (CSV) instead of
+//     querying the API, parsing rows into the same cost records
 //
 // Example usage:
 //
@@ -29,12 +31,12 @@
 //		log.Fatal(err)
 //	}
 //
-//	records, err := client.QueryCosts(context.Background())
+//	result, err := client.QueryCosts(context.Background())
 //	if err != nil {
 //		log.Fatal(err)
 //	}
 //
-//	for _, record := range records {
+//	for _, record := range result.Records {
 //		fmt.Printf("Date: %s, Service: %s, Cost: %.2f %s\n",
 //			record.Date, record.Service, record.Cost, record.Currency)
 //	}
@@ -0,0 +1,44 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// classifyError wraps err in a *provider.Error describing its failure category
+func classifyError(err error) *provider.Error {
+	var classified *provider.Error
+	if errors.As(err, &classified) {
+		return classified
+	}
+
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return &provider.Error{Category: provider.StatusReason(respErr.StatusCode), StatusCode: respErr.StatusCode, Err: err}
+	}
+
+	// Credential errors are the only non-retriable errors raised without a response
+	// (e.g. no managed identity or environment credentials available)
+	var authErr *azidentity.AuthenticationFailedError
+	var nonRetriable interface{ NonRetriable() }
+	if errors.As(err, &authErr) || errors.As(err, &nonRetriable) {
+		return &provider.Error{Category: provider.ReasonAuth, Err: err}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return &provider.Error{Category: provider.ReasonTimeout, Err: err}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return &provider.Error{Category: provider.ReasonTimeout, Err: err}
+	}
+
+	// Connection resets, DNS failures, malformed responses and the like
+	return &provider.Error{Category: provider.ReasonTransient, Err: err}
+}
@@ -0,0 +1,87 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// TestClassifyError tests mapping of Azure failures to reasons and retryability
+func TestClassifyError(t *testing.T) {
+	responseErr := func(status int) error {
+		return fmt.Errorf("cost query failed: %w", &azcore.ResponseError{StatusCode: status})
+	}
+
+	tests := []struct {
+		name          string
+		err           error
+		wantReason    string
+		wantRetryable bool
+	}{
+		{"unauthorized", responseErr(http.StatusUnauthorized), provider.ReasonAuth, false},
+		{"forbidden", responseErr(http.StatusForbidden), provider.ReasonPermission, false},
+		{"bad request", responseErr(http.StatusBadRequest), provider.ReasonBadRequest, false},
+		{"scope not found", responseErr(http.StatusNotFound), provider.ReasonBadRequest, false},
+		{"throttled", responseErr(http.StatusTooManyRequests), provider.ReasonThrottled, true},
+		{"server error", responseErr(http.StatusInternalServerError), provider.ReasonTransient, true},
+		{"unavailable", responseErr(http.StatusServiceUnavailable), provider.ReasonTransient, true},
+		{"gateway timeout", responseErr(http.StatusGatewayTimeout), provider.ReasonTimeout, true},
+		{"credential", fmt.Errorf("token: %w", &azidentity.AuthenticationFailedError{}), provider.ReasonAuth, false},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), provider.ReasonTimeout, true},
+		{"cancelled", context.Canceled, provider.ReasonTimeout, false},
+		{"network", errors.New("connection reset by peer"), provider.ReasonTransient, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyError(tt.err)
+			if got.Reason() != tt.wantReason {
+				t.Errorf("Reason() = %q, want %q", got.Reason(), tt.wantReason)
+			}
+			if got.Retryable() != tt.wantRetryable {
+				t.Errorf("Retryable() = %v, want %v", got.Retryable(), tt.wantRetryable)
+			}
+			if !errors.Is(got, tt.err) {
+				t.Error("Classified error should wrap the original error")
+			}
+			if classifyError(got) != got {
+				t.Error("Classifying an already classified error should return it unchanged")
+			}
+		})
+	}
+}
+
+// TestQueryCosts_PermanentErrorNotRetried tests that a missing role assignment
+// fails immediately and reports the permission reason
+func TestQueryCosts_PermanentErrorNotRetried(t *testing.T) {
+	var requests atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":{"code":"AuthorizationFailed","message":"The client does not have authorization to perform action 'Microsoft.CostManagement/query/action'"}}`))
+	})
+
+	client, _ := newTestServerClient(t, testQueryConfig(), handler)
+
+	result, err := client.QueryCosts(context.Background())
+	if err == nil {
+		t.Fatal("Expected error when all subscriptions are forbidden")
+	}
+	if len(result.Accounts) != 1 || result.Accounts[0].Err == nil || result.Accounts[0].Retries != 0 {
+		t.Errorf("Account result = %+v, want one failed account without retries", result.Accounts)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Expected a single request for a permanent error, got %d", got)
+	}
+	if reason := provider.ErrorReason(err); reason != provider.ReasonPermission {
+		t.Errorf("ErrorReason() = %q, want %q", reason, provider.ReasonPermission)
+	}
+}
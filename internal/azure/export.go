@@ -0,0 +1,356 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/clock"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/filecache"
+	"github.com/zgpcy/azure-cost-exporter/internal/logger"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// Names of the manifests written by partitioned exports
+var exportManifestNames = []string{"manifest.json", "_manifest.json"}
+
+// exportManifest is the manifest a partitioned export writes once all parts of a run are complete
+// Only the fields needed to select and read the run are declared
+type exportManifest struct {
+	ExportConfig struct {
+		ExportName string `json:"exportName"`
+		Type       string `json:"type"` // ActualCost, AmortizedCost, ...
+	} `json:"exportConfig"`
+	RunInfo struct {
+		SubmittedTime string `json:"submittedTime"`
+	} `json:"runInfo"`
+	Blobs []struct {
+		BlobName string `json:"blobName"` // Path within the container
+	} `json:"blobs"`
+}
+
+// exportFile is an export CSV file selected for reading
+type exportFile struct {
+	path       string
+	exportName string // Fallback account of rows without subscription
+	costType   string // config.CostTypeActual or config.CostTypeAmortized
+}
+
+// cachedExport is the records of an export file read as a cost type
+type cachedExport struct {
+	costType string
+	records  []provider.CostRecord
+}
+
+// ExportClient reads Cost Management scheduled exports and implements provider.CloudProvider
+// Export rows are parsed like rows of query responses, so both modes yield the same records
+type ExportClient struct {
+	parser    *Client // Parses rows with the shared settings; makes no API calls
+	cfg       *config.Config
+	exportCfg config.AzureExportConfig
+	logger    *logger.Logger
+	clock     clock.Clock // Time provider for testing
+
+	files filecache.Cache[cachedExport] // Records of the files read so far, by path
+}
+
+// Verify that ExportClient implements provider.CloudProvider
+var _ provider.CloudProvider = (*ExportClient)(nil)
+
+// NewExportClient creates a client reading the scheduled exports below the configured path
+func NewExportClient(cfg *config.Config, exportCfg config.AzureExportConfig, log *logger.Logger) *ExportClient {
+	return &ExportClient{
+		parser:    &Client{cfg: cfg, logger: log},
+		cfg:       cfg,
+		exportCfg: exportCfg,
+		logger:    log,
+		clock:     clock.RealClock{},
+	}
+}
+
+// Name returns the provider type
+func (c *ExportClient) Name() provider.ProviderType {
+	return provider.ProviderAzure
+}
+
+// AccountCount returns the number of export roots read
+func (c *ExportClient) AccountCount() int {
+	return 1
+}
+
+// QueryCosts reads the export rows of the configured date range
+// Only new and changed export files are read; the health of the export root
+// is reported as a single account named after its path
+func (c *ExportClient) QueryCosts(ctx context.Context) (provider.QueryResult, error) {
+	account := provider.AccountResult{
+		AccountID:   c.exportCfg.Path,
+		AccountName: c.exportCfg.Path,
+	}
+
+	start := time.Now()
+	records, files, err := c.readExports(ctx)
+	account.Duration = time.Since(start)
+	if err != nil {
+		account.Err = err
+		c.logger.Warn("Failed to read Cost Management exports",
+			"path", c.exportCfg.Path,
+			"error", err)
+		return provider.QueryResult{Accounts: []provider.AccountResult{account}}, fmt.Errorf("export read failed: %w", err)
+	}
+
+	startDate, endDate := c.cfg.DateRange.Days(c.clock.Now())
+	first, last := startDate.Format(time.DateOnly), endDate.Format(time.DateOnly)
+
+	var inRange []provider.CostRecord
+	for _, record := range records {
+		if record.Date >= first && record.Date <= last {
+			inRange = append(inRange, record)
+		}
+	}
+
+	account.Rows = len(inRange)
+	c.logger.Debug("Read Cost Management exports",
+		"path", c.exportCfg.Path,
+		"files", files,
+		"records", len(inRange),
+		"start_date", first,
+		"end_date", last)
+
+	return provider.QueryResult{Records: inRange, Accounts: []provider.AccountResult{account}}, nil
+}
+
+// readExports returns the records of all selected export files and the number of files
+func (c *ExportClient) readExports(ctx context.Context) ([]provider.CostRecord, int, error) {
+	files, err := c.exportFiles()
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(files) == 0 {
+		return nil, 0, fmt.Errorf("no export files found at %s", c.exportCfg.Path)
+	}
+
+	paths := make([]string, len(files))
+	byPath := make(map[string]exportFile, len(files))
+	for i, file := range files {
+		paths[i] = file.path
+		byPath[file.path] = file
+	}
+
+	// Files of superseded runs are dropped together with their records
+	exports, err := c.files.Load(ctx, paths, func(path string, cached *cachedExport) (cachedExport, error) {
+		file := byPath[path]
+		if cached != nil && cached.costType == file.costType {
+			return *cached, nil
+		}
+		records, err := c.readExportFile(file)
+		return cachedExport{costType: file.costType, records: records}, err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var records []provider.CostRecord
+	for _, e := range exports {
+		records = append(records, e.records...)
+	}
+	return records, len(files), nil
+}
+
+// exportFiles selects the export files to read
+// Of every partitioned export only the latest complete run per period is read,
+// since each run contains all data of the period so far. CSV files without
+// manifest are read as non-partitioned exports, again only the latest file per
+// folder; part files without manifest belong to runs still being written.
+func (c *ExportClient) exportFiles() ([]exportFile, error) {
+	paths, err := c.listExportPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	var manifests, csvFiles []string
+	for _, p := range paths {
+		if isExportManifest(p) {
+			manifests = append(manifests, p)
+		} else if isExportCSV(p) {
+			csvFiles = append(csvFiles, p)
+		}
+	}
+
+	costTypes := c.exportCfg.CostTypes()
+	var files []exportFile
+
+	// Partitioned exports: latest run per export, type and period folder
+	type run struct {
+		dir       string
+		manifest  exportManifest
+		submitted time.Time
+	}
+	latest := make(map[string]run)
+	manifestDirs := make(map[string]bool)
+	for _, p := range manifests {
+		m, submitted, err := readExportManifest(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest %s: %w", p, err)
+		}
+		dir := filepath.Dir(p)
+		manifestDirs[dir] = true
+
+		key := filepath.Dir(dir) + "|" + m.ExportConfig.ExportName + "|" + m.ExportConfig.Type
+		if current, ok := latest[key]; !ok || submitted.After(current.submitted) {
+			latest[key] = run{dir: dir, manifest: m, submitted: submitted}
+		}
+	}
+
+	keys := make([]string, 0, len(latest))
+	for key := range latest {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		r := latest[key]
+		costType, ok := exportCostType(r.manifest.ExportConfig.Type)
+		if !ok {
+			c.logger.Debug("Skipping export of unsupported type", "dir", r.dir, "type", r.manifest.ExportConfig.Type)
+			continue
+		}
+		if !slices.Contains(costTypes, costType) {
+			continue
+		}
+		for _, blob := range r.manifest.Blobs {
+			files = append(files, exportFile{
+				path:       filepath.Join(r.dir, path.Base(blob.BlobName)),
+				exportName: r.manifest.ExportConfig.ExportName,
+				costType:   costType,
+			})
+		}
+	}
+
+	// Non-partitioned exports: latest file per folder
+	costType := config.CostTypeActual
+	if c.exportCfg.CostType == config.CostTypeAmortized {
+		costType = config.CostTypeAmortized
+	}
+	latestFiles := make(map[string]string)
+	latestTimes := make(map[string]time.Time)
+	for _, p := range csvFiles {
+		dir := filepath.Dir(p)
+		if manifestDirs[dir] || strings.HasPrefix(filepath.Base(p), "part_") {
+			continue
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := latestFiles[dir]; !ok || info.ModTime().After(latestTimes[dir]) {
+			latestFiles[dir] = p
+			latestTimes[dir] = info.ModTime()
+		}
+	}
+	dirs := make([]string, 0, len(latestFiles))
+	for dir := range latestFiles {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		p := latestFiles[dir]
+		files = append(files, exportFile{path: p, exportName: legacyExportName(p), costType: costType})
+	}
+
+	return files, nil
+}
+
+// listExportPaths returns the files below the export root
+// A glob pattern may match files and directories; directories are searched recursively
+func (c *ExportClient) listExportPaths() ([]string, error) {
+	roots := []string{c.exportCfg.Path}
+	if _, err := os.Stat(c.exportCfg.Path); errors.Is(err, os.ErrNotExist) {
+		if roots, err = filepath.Glob(c.exportCfg.Path); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				paths = append(paths, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// isExportManifest reports whether a file is the manifest of a partitioned export run
+func isExportManifest(p string) bool {
+	return slices.Contains(exportManifestNames, strings.ToLower(filepath.Base(p)))
+}
+
+// isExportCSV reports whether a file is an export CSV file (optionally gzip-compressed)
+func isExportCSV(p string) bool {
+	return strings.EqualFold(filepath.Ext(strings.TrimSuffix(p, ".gz")), ".csv")
+}
+
+// readExportManifest reads a manifest and the time its run was submitted
+// Manifests without submission time fall back to their modification time
+func readExportManifest(p string) (exportManifest, time.Time, error) {
+	// #nosec G304 -- Export path is provided by administrator via config file
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return exportManifest{}, time.Time{}, err
+	}
+	var m exportManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return exportManifest{}, time.Time{}, err
+	}
+
+	if submitted, err := time.Parse(time.RFC3339Nano, m.RunInfo.SubmittedTime); err == nil {
+		return m, submitted, nil
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return exportManifest{}, time.Time{}, err
+	}
+	return m, info.ModTime(), nil
+}
+
+// exportCostType maps an export type onto its cost type
+func exportCostType(exportType string) (string, bool) {
+	switch {
+	case strings.EqualFold(exportType, config.AzureExportTypeActual):
+		return config.CostTypeActual, true
+	case strings.EqualFold(exportType, config.AzureExportTypeAmortized):
+		return config.CostTypeAmortized, true
+	default:
+		return "", false
+	}
+}
+
+// legacyExportName returns the export name of a non-partitioned export file
+// Such files are named <export name>_<run id>.csv
+func legacyExportName(p string) string {
+	name := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(p), ".gz"), filepath.Ext(strings.TrimSuffix(p, ".gz")))
+	if i := strings.LastIndex(name, "_"); i > 0 {
+		return name[:i]
+	}
+	return name
+}
@@ -0,0 +1,276 @@
+package azure
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/filecache"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// exportTagsColumn is the export column holding the resource tags as JSON
+const exportTagsColumn = "tags"
+
+// exportColumns maps the query response columns parseRow reads onto their export
+// CSV names (lower case), in order of preference
+// ActualCost and AmortizedCost exports share the schema; EA and MCA billing
+// accounts differ in column casing and some names
+var exportColumns = map[string][]string{
+	"UsageDate":        {"date", "usagedate", "usagedatetime"},
+	"Cost":             {"costinbillingcurrency", "cost", "pretaxcost"},
+	"CostUSD":          {"costinusd", "costusd"},
+	currencyColumn:     {"billingcurrency", "billingcurrencycode", "currency"},
+	"SubscriptionId":   {"subscriptionid", "subscriptionguid"},
+	"SubscriptionName": {"subscriptionname"},
+	"ServiceName":      {"servicename", "metercategory"},
+	"ResourceType":     {"resourcetype"},
+	"ResourceGroup":    {"resourcegroup", "resourcegroupname"},
+	"ResourceLocation": {"resourcelocation"},
+	"ResourceId":       {"resourceid", "instanceid"},
+	"MeterCategory":    {"metercategory"},
+	"MeterSubCategory": {"metersubcategory"},
+	"ChargeType":       {"chargetype"},
+	"PricingModel":     {"pricingmodel"},
+	"Meter":            {"metername", "meter"},
+	"UnitOfMeasure":    {"unitofmeasure"},
+	"UsageQuantity":    {"quantity", "usagequantity"},
+}
+
+// exportDateLayouts are the date encodings of export files
+var exportDateLayouts = []string{
+	"01/02/2006",
+	time.DateOnly,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+}
+
+// exportLayout describes how the columns of an export file map onto a query response row
+type exportLayout struct {
+	columnMap map[string]int // Query column name -> index in the row
+	sources   []int          // Export column index of every row value
+	numeric   []bool         // Whether a row value is a number
+	costIdx   int
+	dateIdx   int
+	tagsIdx   int // Export column index of the tags, -1 if absent
+	tagKeys   []string
+}
+
+// queryColumns returns the query response columns an export row is reduced to
+// These are the columns a query with the configured groupings would return
+func (c *ExportClient) queryColumns() []string {
+	columns := []string{"UsageDate", c.parser.costColumn(), currencyColumn, "SubscriptionId", "SubscriptionName"}
+	if c.cfg.GroupBy.Enabled {
+		for _, g := range c.cfg.GroupBy.Groups {
+			// A derived dimension and its source share one column
+			if name := groupingName(g); g.Type == config.GroupTypeDimension && !slices.Contains(columns, name) {
+				columns = append(columns, name)
+			}
+		}
+	}
+	if c.cfg.Usage.Enabled {
+		columns = append(columns, "Meter", "UnitOfMeasure", "UsageQuantity")
+	}
+	return columns
+}
+
+// newExportLayout maps the header of an export file onto the query columns
+func (c *ExportClient) newExportLayout(header []string) (exportLayout, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if _, ok := index[name]; !ok {
+			index[name] = i
+		}
+	}
+
+	layout := exportLayout{columnMap: make(map[string]int), tagsIdx: -1, tagKeys: c.cfg.GroupBy.TagKeys()}
+	for _, column := range c.queryColumns() {
+		if _, ok := layout.columnMap[column]; ok {
+			continue
+		}
+		for _, name := range exportColumns[column] {
+			if i, ok := index[name]; ok {
+				layout.columnMap[column] = len(layout.sources)
+				layout.sources = append(layout.sources, i)
+				layout.numeric = append(layout.numeric, column == "UsageQuantity" || column == c.parser.costColumn())
+				break
+			}
+		}
+	}
+
+	var ok bool
+	if layout.costIdx, ok = layout.columnMap[c.parser.costColumn()]; !ok {
+		return exportLayout{}, fmt.Errorf("no cost column for %s", c.parser.costColumn())
+	}
+	if layout.dateIdx, ok = layout.columnMap["UsageDate"]; !ok {
+		return exportLayout{}, fmt.Errorf("no date column")
+	}
+
+	// Tag values are appended as columns named after the tag key, as returned by queries
+	if i, ok := index[exportTagsColumn]; ok && len(layout.tagKeys) > 0 {
+		layout.tagsIdx = i
+		for i, key := range layout.tagKeys {
+			layout.columnMap[key] = len(layout.sources) + i
+		}
+	}
+
+	return layout, nil
+}
+
+// readExportFile reads an export CSV file into cost records
+// Rows with identical label values are summed, as a query would aggregate them
+func (c *ExportClient) readExportFile(file exportFile) ([]provider.CostRecord, error) {
+	r, err := filecache.Open(file.path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	header, err := filecache.CSVHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	layout, err := c.newExportLayout(header)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows without subscription (e.g. billing profile purchases) belong to the export
+	sub := config.Subscription{ID: file.exportName, Name: file.exportName}
+
+	var records []provider.CostRecord
+	index := make(map[string]int)
+	for line := 2; ; line++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		row, err := layout.row(fields)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		record := c.parser.parseRow(row, layout.columnMap, layout.costIdx, layout.dateIdx, sub, layout.tagKeys)
+		record.CostType = file.costType
+
+		key := exportRecordKey(record, layout.tagKeys)
+		if i, ok := index[key]; ok {
+			records[i].Cost += record.Cost
+			records[i].UsageQuantity += record.UsageQuantity
+			continue
+		}
+		index[key] = len(records)
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// row converts the fields of an export line into a query response row
+func (l exportLayout) row(fields []string) ([]interface{}, error) {
+	row := make([]interface{}, len(l.sources), len(l.sources)+len(l.tagKeys))
+	for i, source := range l.sources {
+		value := ""
+		if source < len(fields) {
+			value = strings.TrimSpace(fields[source])
+		}
+
+		switch {
+		case i == l.dateIdx:
+			date, err := parseExportDate(value)
+			if err != nil {
+				return nil, err
+			}
+			row[i] = date
+		case l.numeric[i]:
+			number := 0.0
+			if value != "" {
+				var err error
+				if number, err = strconv.ParseFloat(value, 64); err != nil {
+					return nil, fmt.Errorf("invalid number %q", value)
+				}
+			}
+			row[i] = number
+		default:
+			row[i] = value
+		}
+	}
+
+	if l.tagsIdx >= 0 {
+		tags := ""
+		if l.tagsIdx < len(fields) {
+			tags = fields[l.tagsIdx]
+		}
+		values := parseExportTags(tags)
+		for _, key := range l.tagKeys {
+			row = append(row, lookupTag(values, key))
+		}
+	}
+
+	return row, nil
+}
+
+// parseExportDate converts an export date to YYYY-MM-DD
+func parseExportDate(value string) (string, error) {
+	for _, layout := range exportDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(time.DateOnly), nil
+		}
+	}
+	return "", fmt.Errorf("invalid date %q", value)
+}
+
+// parseExportTags parses the tags column of an export row
+// Newer exports write a JSON object, older ones its members without braces;
+// malformed tags are treated as no tags
+func parseExportTags(value string) map[string]string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	if !strings.HasPrefix(value, "{") {
+		value = "{" + value + "}"
+	}
+	var tags map[string]string
+	if err := json.Unmarshal([]byte(value), &tags); err != nil {
+		return nil
+	}
+	return tags
+}
+
+// lookupTag returns the value of a tag; Azure tag names are case-insensitive
+func lookupTag(tags map[string]string, key string) string {
+	if value, ok := tags[key]; ok {
+		return value
+	}
+	for k, value := range tags {
+		if strings.EqualFold(k, key) {
+			return value
+		}
+	}
+	return ""
+}
+
+// exportRecordKey returns the label values identifying a record within an export file
+func exportRecordKey(r provider.CostRecord, tagKeys []string) string {
+	values := []string{
+		r.Date, r.AccountID, r.AccountName, r.Service, r.Currency, r.ResourceType, r.ResourceGroup,
+		r.ResourceLocation, r.ResourceID, r.MeterCategory, r.MeterSubCategory, r.ChargeType,
+		r.PricingModel, r.Meter, r.UnitOfMeasure,
+	}
+	for _, key := range tagKeys {
+		values = append(values, r.Tags[key])
+	}
+	return strings.Join(values, "\x00")
+}
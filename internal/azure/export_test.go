@@ -0,0 +1,275 @@
+package azure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/clock"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/logger"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// exportTestConfig returns settings querying 2026-01-14 and 2026-01-15, grouped by the given dimensions and the team tag
+func exportTestConfig(dimensions ...string) *config.Config {
+	offset := 0
+	groups := make([]config.GroupBy, 0, len(dimensions)+1)
+	for _, d := range dimensions {
+		groups = append(groups, config.GroupBy{Type: config.GroupTypeDimension, Name: d})
+	}
+	groups = append(groups, config.GroupBy{Type: config.GroupTypeTagKey, Name: "team"})
+	return &config.Config{
+		Currency:  "€",
+		DateRange: config.DateRange{DaysToQuery: 2, EndDateOffset: &offset},
+		GroupBy:   config.GroupByConfig{Enabled: true, Groups: groups},
+	}
+}
+
+// newTestExportClient creates an export client reading path at 2026-01-15 18:00 UTC
+func newTestExportClient(cfg *config.Config, path, costType string) *ExportClient {
+	client := NewExportClient(cfg, config.AzureExportConfig{Path: path, CostType: costType}, logger.New("error"))
+	client.clock = clock.Fixed(time.Date(2026, 1, 15, 18, 0, 0, 0, time.UTC))
+	return client
+}
+
+// copyExportFixture copies a testdata export file to dir/name and sets its modification time
+func copyExportFixture(t *testing.T, fixture, dir, name string, modTime time.Time) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "exports", fixture))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+}
+
+// TestExportClient_Partitioned tests that only the latest run of every partitioned export is read
+// and that ActualCost and AmortizedCost exports map onto their cost types
+func TestExportClient_Partitioned(t *testing.T) {
+	cfg := exportTestConfig("ServiceName", "ResourceGroup")
+	client := newTestExportClient(cfg, filepath.Join("testdata", "exports", "partitioned"), config.CostTypeBoth)
+
+	result, err := client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+
+	// The superseded run (999) and the row of 2026-01-10 are not exported
+	want := []provider.CostRecord{
+		{Date: "2026-01-15", AccountID: "sub-1", AccountName: "prod", Service: "Virtual Machines", ResourceGroup: "shop",
+			Cost: 10.5, CostType: config.CostTypeActual, Tags: map[string]string{"team": "checkout"}},
+		{Date: "2026-01-15", AccountID: "sub-1", AccountName: "prod", Service: "Storage", ResourceGroup: "shop",
+			Cost: 2, CostType: config.CostTypeActual, Tags: map[string]string{"team": "checkout"}},
+		{Date: "2026-01-14", AccountID: "sub-2", AccountName: "dev", Service: "Storage", ResourceGroup: "test",
+			Cost: 1.25, CostType: config.CostTypeActual, Tags: map[string]string{"team": ""}},
+		{Date: "2026-01-15", AccountID: "sub-1", AccountName: "prod", Service: "Virtual Machines",
+			Cost: 300, CostType: config.CostTypeActual, Tags: map[string]string{"team": ""}},
+		{Date: "2026-01-15", AccountID: "sub-1", AccountName: "prod", Service: "Virtual Machines", ResourceGroup: "shop",
+			Cost: 9.86, CostType: config.CostTypeAmortized, Tags: map[string]string{"team": "checkout"}},
+	}
+
+	if len(result.Records) != len(want) {
+		t.Fatalf("Got %d records, want %d: %+v", len(result.Records), len(want), result.Records)
+	}
+	for i, got := range result.Records {
+		w := want[i]
+		if got.Provider != "azure" || got.Currency != "EUR" {
+			t.Errorf("Record %d: provider/currency = %s/%s", i, got.Provider, got.Currency)
+		}
+		if got.Date != w.Date || got.AccountID != w.AccountID || got.AccountName != w.AccountName ||
+			got.Service != w.Service || got.ResourceGroup != w.ResourceGroup || got.Cost != w.Cost ||
+			got.CostType != w.CostType || got.Tags["team"] != w.Tags["team"] {
+			t.Errorf("Record %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	if len(result.Accounts) != 1 || result.Accounts[0].Err != nil || result.Accounts[0].Rows != len(want) {
+		t.Errorf("Account results = %+v", result.Accounts)
+	}
+
+	// Only exports of the configured cost type are read
+	client = newTestExportClient(cfg, filepath.Join("testdata", "exports", "partitioned"), config.CostTypeAmortized)
+	result, err = client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+	if len(result.Records) != 1 || result.Records[0].Cost != 9.86 {
+		t.Errorf("Amortized records = %+v, want the amortized export only", result.Records)
+	}
+}
+
+// TestExportClient_Aggregation tests that export rows are reduced to the configured groupings
+// and summed like a query would aggregate them
+func TestExportClient_Aggregation(t *testing.T) {
+	cfg := exportTestConfig("ResourceGroup")
+	cfg.Usage.Enabled = true
+	client := newTestExportClient(cfg, filepath.Join("testdata", "exports", "partitioned", "daily-actual"), config.CostTypeActual)
+
+	result, err := client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+
+	// The VM and disk rows of the shop resource group only differ by their meter
+	var vm *provider.CostRecord
+	for i, r := range result.Records {
+		if r.ResourceGroup == "shop" && r.Meter == "D2s v3" {
+			vm = &result.Records[i]
+		}
+		if r.MeterCategory != "" || r.ResourceID != "" {
+			t.Errorf("Record %d carries columns that are not grouped by: %+v", i, r)
+		}
+	}
+	if len(result.Records) != 4 {
+		t.Fatalf("Got %d records, want 4: %+v", len(result.Records), result.Records)
+	}
+	if vm == nil || vm.Cost != 10.5 || vm.UnitOfMeasure != "1 Hour" || vm.UsageQuantity != 24 || vm.Service != "Unknown" {
+		t.Errorf("VM record = %+v", vm)
+	}
+
+	cfg = exportTestConfig("ResourceGroup")
+	client = newTestExportClient(cfg, filepath.Join("testdata", "exports", "partitioned", "daily-actual"), config.CostTypeActual)
+	result, err = client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+	if len(result.Records) != 3 || result.Records[0].Cost != 12.5 {
+		t.Errorf("Records = %+v, want VM and disk summed to 12.5", result.Records)
+	}
+}
+
+// TestExportClient_Legacy tests EA exports without manifest, of which only the latest file per folder is read
+func TestExportClient_Legacy(t *testing.T) {
+	dir := t.TempDir()
+	legacy := "legacy/monthly-ea/20260101-20260131/monthly-ea_6f1d2a3b-4c5d-4e6f-8a9b-0c1d2e3f4a5b.csv"
+	older := time.Date(2026, 1, 14, 6, 0, 0, 0, time.UTC)
+	copyExportFixture(t, "partitioned/daily-actual/20260101-20260131/5b0c8a1e-3f41-4c55-9a3e-0d6f2f1b7a10/part_0_0001.csv",
+		dir, "monthly-ea/20260101-20260131/monthly-ea_0b9e.csv", older)
+	copyExportFixture(t, legacy, dir, "monthly-ea/20260101-20260131/monthly-ea_6f1d.csv", older.Add(24*time.Hour))
+	// Part files without manifest belong to a run still being written
+	copyExportFixture(t, legacy, dir, "daily/20260101-20260131/run-1/part_0_0001.csv", older)
+
+	client := newTestExportClient(exportTestConfig("ServiceName", "ResourceGroup"), dir, config.CostTypeActual)
+	result, err := client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+
+	if len(result.Records) != 2 {
+		t.Fatalf("Got %d records, want 2: %+v", len(result.Records), result.Records)
+	}
+	sql, previous := result.Records[0], result.Records[1]
+	if sql.Date != "2026-01-15" || sql.AccountID != "sub-3" || sql.AccountName != "legacy-prod" || sql.Service != "SQL Database" ||
+		sql.ResourceGroup != "erp" || sql.Cost != 7.5 || sql.Currency != "USD" || sql.CostType != config.CostTypeActual || sql.Tags["team"] != "finance" {
+		t.Errorf("SQL record = %+v", sql)
+	}
+	if previous.Date != "2026-01-14" || previous.Cost != 2.5 || previous.Tags["team"] != "" {
+		t.Errorf("Previous day record = %+v", previous)
+	}
+}
+
+// TestExportClient_NewRun tests that a completed new run replaces the previous one
+func TestExportClient_NewRun(t *testing.T) {
+	dir := t.TempDir()
+	run1 := "partitioned/daily-actual/20260101-20260131/5b0c8a1e-3f41-4c55-9a3e-0d6f2f1b7a10/"
+	run2 := "partitioned/daily-actual/20260101-20260131/9e2d4c7a-81b6-4f0e-b5d2-6a3c9f8e1d24/"
+	now := time.Now()
+	copyExportFixture(t, run1+"manifest.json", dir, "20260101-20260131/run-1/manifest.json", now)
+	copyExportFixture(t, run1+"part_0_0001.csv", dir, "20260101-20260131/run-1/part_0_0001.csv", now)
+
+	client := newTestExportClient(exportTestConfig(), dir, config.CostTypeActual)
+	total := func() float64 {
+		t.Helper()
+		result, err := client.QueryCosts(context.Background())
+		if err != nil {
+			t.Fatalf("QueryCosts() error = %v", err)
+		}
+		sum := 0.0
+		for _, r := range result.Records {
+			sum += r.Cost
+		}
+		return sum
+	}
+
+	if got := total(); got != 999 {
+		t.Errorf("Total = %v, want 999 of the first run", got)
+	}
+
+	// Parts of the next run are ignored until its manifest is written
+	copyExportFixture(t, run2+"part_0_0001.csv", dir, "20260101-20260131/run-2/part_0_0001.csv", now)
+	copyExportFixture(t, run2+"part_0_0002.csv", dir, "20260101-20260131/run-2/part_0_0002.csv", now)
+	if got := total(); got != 999 {
+		t.Errorf("Total = %v, want 999 while the second run is incomplete", got)
+	}
+
+	copyExportFixture(t, run2+"manifest.json", dir, "20260101-20260131/run-2/manifest.json", now)
+	if got := total(); got != 313.75 {
+		t.Errorf("Total = %v, want 313.75 of the second run", got)
+	}
+	if client.files.Len() != 2 {
+		t.Errorf("Cache holds %d files, want the 2 parts of the second run", client.files.Len())
+	}
+}
+
+func TestExportClient_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "export_1.csv"), []byte("date,meterCategory\n01/15/2026,Storage\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"no files", filepath.Join(dir, "*.gz")},
+		{"missing cost column", dir},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := newTestExportClient(exportTestConfig(), tt.path, config.CostTypeActual).QueryCosts(context.Background())
+			if err == nil {
+				t.Fatal("QueryCosts() error = nil, want error")
+			}
+			if len(result.Accounts) != 1 || result.Accounts[0].Err == nil {
+				t.Errorf("Account results = %+v, want one failed account", result.Accounts)
+			}
+		})
+	}
+}
+
+func TestParseExportTags(t *testing.T) {
+	tests := []struct {
+		value string
+		want  map[string]string
+	}{
+		{`{"team":"checkout","env":"prod"}`, map[string]string{"team": "checkout", "env": "prod"}},
+		{`"team": "finance","cost-center": "4711"`, map[string]string{"team": "finance", "cost-center": "4711"}},
+		{"", nil},
+		{"not json", nil},
+	}
+
+	for _, tt := range tests {
+		got := parseExportTags(tt.value)
+		if len(got) != len(tt.want) {
+			t.Errorf("parseExportTags(%q) = %v, want %v", tt.value, got, tt.want)
+			continue
+		}
+		for k, v := range tt.want {
+			if got[k] != v {
+				t.Errorf("parseExportTags(%q)[%s] = %q, want %q", tt.value, k, got[k], v)
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package azure
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// compileFilter converts a configured filter into a Cost Management query filter
+// The filter must not contain Not expressions (see config.Filter.Split)
+func compileFilter(f *config.Filter) *armcostmanagement.QueryFilter {
+	if f == nil {
+		return nil
+	}
+
+	switch {
+	case len(f.And) > 0:
+		return &armcostmanagement.QueryFilter{And: compileFilters(f.And)}
+	case len(f.Or) > 0:
+		return &armcostmanagement.QueryFilter{Or: compileFilters(f.Or)}
+	case f.Dimension != nil:
+		return &armcostmanagement.QueryFilter{Dimensions: compileComparison(f.Dimension.DimensionName(), f.Dimension.Values)}
+	case f.Tag != nil:
+		return &armcostmanagement.QueryFilter{Tags: compileComparison(f.Tag.Name, f.Tag.Values)}
+	default:
+		return nil
+	}
+}
+
+// compileFilters converts a list of sub-filters
+func compileFilters(filters []config.Filter) []*armcostmanagement.QueryFilter {
+	compiled := make([]*armcostmanagement.QueryFilter, 0, len(filters))
+	for i := range filters {
+		compiled = append(compiled, compileFilter(&filters[i]))
+	}
+	return compiled
+}
+
+// compileComparison builds an In comparison expression
+func compileComparison(name string, values []string) *armcostmanagement.QueryComparisonExpression {
+	operator := armcostmanagement.QueryOperatorTypeIn
+	expr := &armcostmanagement.QueryComparisonExpression{
+		Name:     stringPtr(name),
+		Operator: &operator,
+		Values:   make([]*string, len(values)),
+	}
+	for i := range values {
+		expr.Values[i] = stringPtr(values[i])
+	}
+	return expr
+}
+
+// excludeRecords drops records matching any of the negated filters
+// Values are compared case-insensitively, as the Cost Management API does
+func excludeRecords(records []provider.CostRecord, excludes []config.Filter) []provider.CostRecord {
+	if len(excludes) == 0 {
+		return records
+	}
+
+	kept := records[:0]
+	for _, r := range records {
+		excluded := false
+		for i := range excludes {
+			if matchesFilter(r, excludes[i]) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// matchesFilter evaluates a filter against a parsed record
+func matchesFilter(r provider.CostRecord, f config.Filter) bool {
+	switch {
+	case len(f.And) > 0:
+		for _, sub := range f.And {
+			if !matchesFilter(r, sub) {
+				return false
+			}
+		}
+		return true
+	case len(f.Or) > 0:
+		for _, sub := range f.Or {
+			if matchesFilter(r, sub) {
+				return true
+			}
+		}
+		return false
+	case f.Not != nil:
+		return !matchesFilter(r, *f.Not)
+	case f.Dimension != nil:
+		dim, ok := provider.LookupDimension(f.Dimension.Name)
+		return ok && containsFold(f.Dimension.Values, dim.Value(r))
+	case f.Tag != nil:
+		value, ok := r.Tags[f.Tag.Name]
+		return ok && containsFold(f.Tag.Values, value)
+	default:
+		return false
+	}
+}
+
+// containsFold reports whether value is in values, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// TestCompileFilter tests that filter trees are converted into the API model
+func TestCompileFilter(t *testing.T) {
+	filter := &config.Filter{And: []config.Filter{
+		{Dimension: &config.FilterComparison{Name: "Location", Values: []string{"westeurope"}}},
+		{Or: []config.Filter{
+			{Tag: &config.FilterComparison{Name: "env", Values: []string{"prod"}}},
+			{Tag: &config.FilterComparison{Name: "team", Values: []string{"core", "platform"}}},
+		}},
+	}}
+
+	data, err := json.Marshal(compileFilter(filter))
+	if err != nil {
+		t.Fatalf("Failed to marshal filter: %v", err)
+	}
+
+	want := `{"and":[{"dimensions":{"name":"ResourceLocation","operator":"In","values":["westeurope"]}},` +
+		`{"or":[{"tags":{"name":"env","operator":"In","values":["prod"]}},{"tags":{"name":"team","operator":"In","values":["core","platform"]}}]}]}`
+	if string(data) != want {
+		t.Errorf("compileFilter() =\n%s\nwant\n%s", data, want)
+	}
+
+	if compileFilter(nil) != nil {
+		t.Error("compileFilter(nil) should return nil")
+	}
+}
+
+// TestExcludeRecords tests that records matching a negated filter are dropped
+func TestExcludeRecords(t *testing.T) {
+	records := []provider.CostRecord{
+		{Service: "Storage", Tags: map[string]string{"env": "prod"}},
+		{Service: "Bandwidth", Tags: map[string]string{"env": "prod"}},
+		{Service: "Storage", Tags: map[string]string{"env": "DEV"}},
+	}
+	excludes := []config.Filter{
+		{Dimension: &config.FilterComparison{Name: "ServiceName", Values: []string{"Bandwidth"}}},
+		{Tag: &config.FilterComparison{Name: "env", Values: []string{"dev"}}},
+	}
+
+	kept := excludeRecords(records, excludes)
+	if len(kept) != 1 || kept[0].Service != "Storage" || kept[0].Tags["env"] != "prod" {
+		t.Errorf("excludeRecords() = %+v, want only the prod Storage record", kept)
+	}
+}
+
+// TestQueryCosts_Filter tests that the top-level filter is sent with each query,
+// subscriptions can override it, and negated parts are applied to the records
+func TestQueryCosts_Filter(t *testing.T) {
+	cfg := testQueryConfig()
+	cfg.Subscriptions = []config.Subscription{
+		{ID: "sub-prod", Name: "prod", Filter: &config.Filter{
+			Dimension: &config.FilterComparison{Name: "ChargeType", Values: []string{"Usage"}},
+		}},
+		{ID: "sub-dev", Name: "dev"},
+	}
+	cfg.GroupBy = config.GroupByConfig{Enabled: true, Groups: []config.GroupBy{{Type: config.GroupTypeDimension, Name: "ServiceName"}}}
+	cfg.Filter = &config.Filter{And: []config.Filter{
+		{Tag: &config.FilterComparison{Name: "env", Values: []string{"dev"}}},
+		{Not: &config.Filter{Dimension: &config.FilterComparison{Name: "ServiceName", Values: []string{"Bandwidth"}}}},
+	}}
+
+	var (
+		mu      sync.Mutex
+		filters = make(map[string]*armcostmanagement.QueryFilter)
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var def armcostmanagement.QueryDefinition
+		if err := json.NewDecoder(r.Body).Decode(&def); err != nil {
+			t.Errorf("Failed to decode query: %v", err)
+		}
+		mu.Lock()
+		filters[strings.Split(r.URL.Path, "/")[2]] = def.Dataset.Filter
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"UsageDate","type":"Number"},{"name":"ServiceName","type":"String"}],` +
+			`"rows":[[1.0,20260115,"Storage"],[2.0,20260115,"Bandwidth"]]}}`))
+	})
+	client, _ := newTestServerClient(t, cfg, handler)
+
+	result, err := client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+
+	if f := filters["sub-prod"]; f == nil || f.Dimensions == nil || *f.Dimensions.Name != "ChargeType" {
+		t.Errorf("sub-prod filter = %+v, want its own ChargeType filter", f)
+	}
+	if f := filters["sub-dev"]; f == nil || f.Tags == nil || *f.Tags.Name != "env" || f.And != nil {
+		t.Errorf("sub-dev filter = %+v, want the env tag filter without the negated part", f)
+	}
+
+	services := map[string][]string{}
+	for _, r := range result.Records {
+		services[r.AccountID] = append(services[r.AccountID], r.Service)
+	}
+	if got := strings.Join(services["sub-prod"], ","); got != "Storage,Bandwidth" {
+		t.Errorf("sub-prod services = %q, want Storage,Bandwidth", got)
+	}
+	if got := strings.Join(services["sub-dev"], ","); got != "Storage" {
+		t.Errorf("sub-dev services = %q, want Bandwidth excluded", got)
+	}
+}
@@ -0,0 +1,217 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// Verify that Client implements provider.Forecaster
+var _ provider.Forecaster = (*Client)(nil)
+
+// Forecast response status column and the status of rows reporting actual costs
+const (
+	forecastStatusColumn = "CostStatus"
+	forecastStatusActual = "Actual"
+)
+
+// forecastBoundColumns maps confidence bounds to their response columns
+// The cost column holds the expected value; bounds are exported when the response carries them
+var forecastBoundColumns = map[string]string{
+	provider.ForecastBoundLower: "CostLowerBound",
+	provider.ForecastBoundUpper: "CostUpperBound",
+}
+
+// QueryForecast forecasts daily costs from today until the end of the month for all targets
+// Targets are queried like in QueryCosts; the Forecast API doesn't support grouping,
+// so there is one forecast per subscription or scope and cost type
+func (c *Client) QueryForecast(ctx context.Context) (provider.ForecastResult, error) {
+	targets := c.targets()
+	results := c.queryTargets(ctx, targets, c.forecastTarget)
+
+	var (
+		records  []provider.ForecastRecord
+		failures []error
+	)
+	accounts := make([]provider.AccountResult, 0, len(targets))
+
+	for i, target := range targets {
+		accounts = append(accounts, results[i].account)
+		if err := results[i].account.Err; err != nil {
+			c.logger.Warn("Failed to forecast subscription costs, continuing with others",
+				"tenant_id", target.tenant.cfg.ID,
+				"subscription_name", target.account.Name,
+				"subscription_id", target.account.ID,
+				"scope", target.scope,
+				"reason", provider.ErrorReason(err),
+				"records_kept", len(results[i].forecasts),
+				"error", err)
+			failures = append(failures, fmt.Errorf("subscription %s: %w", target.account.Name, err))
+		}
+		// Forecasts of cost types that succeeded are kept when another cost type failed
+		records = append(records, results[i].forecasts...)
+	}
+
+	if len(failures) > 0 && len(records) == 0 {
+		return provider.ForecastResult{Accounts: accounts}, fmt.Errorf("all %d subscription forecasts failed: %w",
+			len(targets), errors.Join(failures...))
+	}
+
+	return provider.ForecastResult{Records: records, Accounts: accounts}, nil
+}
+
+// forecastTarget forecasts the costs of a single target in its own context
+func (c *Client) forecastTarget(ctx context.Context, target queryTarget) targetResult {
+	account := provider.AccountResult{
+		AccountID:   target.account.ID,
+		AccountName: target.account.Name,
+	}
+	if err := ctx.Err(); err != nil {
+		account.Err = classifyError(err)
+		return targetResult{account: account}
+	}
+
+	targetCtx, cancel := context.WithTimeout(ctx, time.Duration(len(c.cfg.CostTypes()))*c.queryTimeout())
+	defer cancel()
+
+	var (
+		forecasts []provider.ForecastRecord
+		failures  []error
+		retries   int
+	)
+	start := time.Now()
+	for _, costType := range c.cfg.CostTypes() {
+		err := c.retryQuery(targetCtx, target, costType+" forecast", &retries, func() error {
+			records, err := c.forecastTargetInternal(targetCtx, target, costType)
+			if err != nil {
+				return err
+			}
+			forecasts = append(forecasts, records...)
+			return nil
+		})
+		if err != nil {
+			// Forecasts of the other cost type are kept
+			failures = append(failures, fmt.Errorf("subscription %s (ID: %s) %s forecast failed after retries: %w",
+				target.account.Name, target.account.ID, costType, err))
+		}
+	}
+	account.Err = errors.Join(failures...)
+	account.Duration = time.Since(start)
+	account.Retries = retries
+	account.Rows = len(forecasts)
+
+	return targetResult{forecasts: forecasts, account: account}
+}
+
+// forecastTargetInternal performs a single Forecast API call without retry logic
+func (c *Client) forecastTargetInternal(ctx context.Context, target queryTarget, costType string) ([]provider.ForecastRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.apiTimeout())
+	defer cancel()
+
+	from, to := forecastPeriod(c.clock.Now())
+
+	forecastType := armcostmanagement.ForecastType(exportType(costType))
+	timeframe := armcostmanagement.ForecastTimeframeTypeCustom
+	granularity := armcostmanagement.GranularityTypeDaily
+	includeActual, includeFresh := false, false
+
+	// Validation rejects negated filters with forecasts enabled, they need grouped rows
+	filter, _ := c.cfg.FilterFor(target.filter).Split()
+
+	def := armcostmanagement.ForecastDefinition{
+		Type:      &forecastType,
+		Timeframe: &timeframe,
+		TimePeriod: &armcostmanagement.QueryTimePeriod{
+			From: &from,
+			To:   &to,
+		},
+		Dataset: &armcostmanagement.ForecastDataset{
+			Granularity: &granularity,
+			Aggregation: map[string]*armcostmanagement.QueryAggregation{
+				"totalCost": {
+					Name:     stringPtr(c.costColumn()),
+					Function: functionPtr(armcostmanagement.FunctionTypeSum),
+				},
+			},
+			Filter: compileFilter(filter),
+		},
+		IncludeActualCost:       &includeActual,
+		IncludeFreshPartialCost: &includeFresh,
+	}
+
+	resp, err := target.tenant.forecastClient.Usage(ctx, target.scope, def, nil)
+	if err != nil {
+		return nil, fmt.Errorf("forecast failed for date range %s to %s: %w",
+			from.Format("2006-01-02"), to.Format("2006-01-02"), err)
+	}
+
+	records := c.parseForecast(resp.QueryResult, target.account)
+	for i := range records {
+		records[i].CostType = costType
+		records[i].TenantID = target.tenant.cfg.ID
+	}
+	return records, nil
+}
+
+// forecastPeriod returns the forecast period: today until the last day of the month (UTC)
+func forecastPeriod(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	return from, to
+}
+
+// parseForecast converts a Forecast API response to forecast records
+// Rows reporting actual costs are skipped
+func (c *Client) parseForecast(result armcostmanagement.QueryResult, sub config.Subscription) []provider.ForecastRecord {
+	var records []provider.ForecastRecord
+
+	if result.Properties == nil {
+		return records
+	}
+
+	columnMap := buildColumnMap(result.Properties.Columns)
+	dateIdx, hasDate := columnMap["UsageDate"]
+	costIdx, hasCost := findColumn(columnMap, c.costColumns())
+	if !hasCost || !hasDate {
+		return records
+	}
+
+	for _, row := range result.Properties.Rows {
+		if len(row) <= costIdx || len(row) <= dateIdx {
+			continue
+		}
+		if getStringFromRow(row, columnMap, forecastStatusColumn) == forecastStatusActual {
+			continue
+		}
+
+		base := provider.CostRecord{
+			Date:        parseDate(row[dateIdx]),
+			Provider:    string(provider.ProviderAzure),
+			AccountID:   sub.ID,
+			AccountName: sub.Name,
+			Currency:    c.rowCurrency(row, columnMap),
+		}
+
+		expected := base
+		expected.Cost = parseCost(row[costIdx])
+		records = append(records, provider.ForecastRecord{CostRecord: expected, Bound: provider.ForecastBoundExpected})
+
+		for _, bound := range []string{provider.ForecastBoundLower, provider.ForecastBoundUpper} {
+			idx, ok := columnMap[forecastBoundColumns[bound]]
+			if !ok || len(row) <= idx {
+				continue
+			}
+			bounded := base
+			bounded.Cost = parseCost(row[idx])
+			records = append(records, provider.ForecastRecord{CostRecord: bounded, Bound: bound})
+		}
+	}
+
+	return records
+}
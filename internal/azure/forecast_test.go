@@ -0,0 +1,139 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
+	"github.com/zgpcy/azure-cost-exporter/internal/clock"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// TestForecastPeriod tests that forecasts cover today until the end of the month
+func TestForecastPeriod(t *testing.T) {
+	tests := []struct {
+		now      time.Time
+		from, to string
+	}{
+		{time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC), "2026-01-15", "2026-01-31"},
+		{time.Date(2026, 2, 28, 23, 0, 0, 0, time.UTC), "2026-02-28", "2026-02-28"},
+		{time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), "2026-12-01", "2026-12-31"},
+	}
+
+	for _, tt := range tests {
+		from, to := forecastPeriod(tt.now)
+		if got := from.Format("2006-01-02"); got != tt.from {
+			t.Errorf("forecastPeriod(%v) from = %s, want %s", tt.now, got, tt.from)
+		}
+		if got := to.Format("2006-01-02"); got != tt.to {
+			t.Errorf("forecastPeriod(%v) to = %s, want %s", tt.now, got, tt.to)
+		}
+	}
+}
+
+// TestQueryForecast tests the forecast request and that actual rows are skipped
+// while confidence bounds are exported when present
+func TestQueryForecast(t *testing.T) {
+	cfg := testQueryConfig()
+	cfg.CostType = config.CostTypeAmortized
+
+	var def armcostmanagement.ForecastDefinition
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/providers/Microsoft.CostManagement/forecast") {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&def); err != nil {
+			t.Errorf("Failed to decode forecast definition: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"properties":{"columns":[` +
+			`{"name":"Cost","type":"Number"},{"name":"UsageDate","type":"Number"},{"name":"CostStatus","type":"String"},` +
+			`{"name":"CostLowerBound","type":"Number"},{"name":"CostUpperBound","type":"Number"},{"name":"Currency","type":"String"}],` +
+			`"rows":[[5.0,20260115,"Actual",5.0,5.0,"EUR"],[10.0,20260116,"Forecast",8.0,12.0,"EUR"]]}}`))
+	})
+	client, _ := newTestServerClient(t, cfg, handler)
+	client.clock = clock.Fixed(time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC))
+
+	result, err := client.QueryForecast(context.Background())
+	if err != nil {
+		t.Fatalf("QueryForecast() error = %v", err)
+	}
+
+	if def.Type == nil || *def.Type != armcostmanagement.ForecastTypeAmortizedCost {
+		t.Errorf("Forecast type = %v, want AmortizedCost", def.Type)
+	}
+	if def.Timeframe == nil || *def.Timeframe != armcostmanagement.ForecastTimeframeTypeCustom {
+		t.Errorf("Timeframe = %v, want Custom", def.Timeframe)
+	}
+	if def.TimePeriod == nil || def.TimePeriod.From.Format("2006-01-02") != "2026-01-15" || def.TimePeriod.To.Format("2006-01-02") != "2026-01-31" {
+		t.Errorf("TimePeriod = %+v, want 2026-01-15 to 2026-01-31", def.TimePeriod)
+	}
+	if def.IncludeActualCost == nil || *def.IncludeActualCost {
+		t.Error("Forecast should not include actual costs")
+	}
+
+	got := map[string]float64{}
+	for _, r := range result.Records {
+		if r.Date != "2026-01-16" || r.AccountID != "test-sub-1" || r.CostType != config.CostTypeAmortized || r.Currency != "EUR" {
+			t.Errorf("Unexpected forecast record %+v", r)
+		}
+		got[r.Bound] = r.Cost
+	}
+	want := map[string]float64{provider.ForecastBoundExpected: 10, provider.ForecastBoundLower: 8, provider.ForecastBoundUpper: 12}
+	if len(got) != len(want) {
+		t.Fatalf("Forecast bounds = %v, want %v", got, want)
+	}
+	for bound, cost := range want {
+		if got[bound] != cost {
+			t.Errorf("Forecast %s = %v, want %v", bound, got[bound], cost)
+		}
+	}
+	if len(result.Accounts) != 1 || result.Accounts[0].Err != nil || result.Accounts[0].Rows != 3 {
+		t.Errorf("Accounts = %+v, want one successful account with 3 rows", result.Accounts)
+	}
+}
+
+// TestQueryForecast_CostTypeFailure tests that the forecast of one cost type is kept
+// when the other cost type fails, and that the failure is recorded on the account
+func TestQueryForecast_CostTypeFailure(t *testing.T) {
+	cfg := testQueryConfig()
+	cfg.CostType = config.CostTypeBoth
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var def armcostmanagement.ForecastDefinition
+		if err := json.NewDecoder(r.Body).Decode(&def); err != nil {
+			t.Errorf("Failed to decode forecast definition: %v", err)
+		}
+		if *def.Type == armcostmanagement.ForecastTypeAmortizedCost {
+			http.Error(w, `{"error":{"code":"BadRequest","message":"amortized forecast not supported"}}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"UsageDate","type":"Number"},{"name":"Currency","type":"String"}],` +
+			`"rows":[[10.0,20260116,"EUR"]]}}`))
+	})
+	client, _ := newTestServerClient(t, cfg, handler)
+	client.clock = clock.Fixed(time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC))
+
+	result, err := client.QueryForecast(context.Background())
+	if err != nil {
+		t.Fatalf("QueryForecast() error = %v, want partial data", err)
+	}
+
+	if len(result.Records) != 1 || result.Records[0].CostType != config.CostTypeActual {
+		t.Errorf("Records = %+v, want the actual forecast", result.Records)
+	}
+	if len(result.Accounts) != 1 {
+		t.Fatalf("Got %d account results, want 1", len(result.Accounts))
+	}
+	account := result.Accounts[0]
+	if account.Err == nil || !strings.Contains(account.Err.Error(), "amortized forecast failed") || account.Rows != 1 {
+		t.Errorf("Account result = %+v, want amortized failure with 1 row", account)
+	}
+}
@@ -0,0 +1,134 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// Verify that Client implements provider.MonthCostReader
+var _ provider.MonthCostReader = (*Client)(nil)
+
+// monthTimeframes are the timeframes queried for the month totals, in query order
+var monthTimeframes = []armcostmanagement.TimeframeType{
+	armcostmanagement.TimeframeTypeMonthToDate,
+	armcostmanagement.TimeframeTypeTheLastMonth,
+}
+
+// QueryMonthCosts retrieves the month-to-date and previous month totals for all targets
+// Targets are queried like in QueryCosts, with the same grouping and filters but
+// without daily granularity, so every record is a total over its timeframe
+func (c *Client) QueryMonthCosts(ctx context.Context) (provider.MonthCostResult, error) {
+	targets := c.targets()
+	results := c.queryTargets(ctx, targets, c.monthTarget)
+
+	var (
+		result   provider.MonthCostResult
+		failures []error
+	)
+	result.Accounts = make([]provider.AccountResult, 0, len(targets))
+
+	for i, target := range targets {
+		result.Accounts = append(result.Accounts, results[i].account)
+		if err := results[i].account.Err; err != nil {
+			c.logger.Warn("Failed to query subscription month totals, continuing with others",
+				"tenant_id", target.tenant.cfg.ID,
+				"subscription_name", target.account.Name,
+				"subscription_id", target.account.ID,
+				"scope", target.scope,
+				"reason", provider.ErrorReason(err),
+				"records_kept", len(results[i].monthToDate)+len(results[i].previousMonth),
+				"error", err)
+			failures = append(failures, fmt.Errorf("subscription %s: %w", target.account.Name, err))
+		}
+		// Totals of timeframes and cost types that succeeded are kept when another one failed
+		result.MonthToDate = append(result.MonthToDate, results[i].monthToDate...)
+		result.PreviousMonth = append(result.PreviousMonth, results[i].previousMonth...)
+	}
+
+	if len(failures) > 0 && len(result.MonthToDate) == 0 && len(result.PreviousMonth) == 0 {
+		return provider.MonthCostResult{Accounts: result.Accounts}, fmt.Errorf("all %d subscription month total queries failed: %w",
+			len(targets), errors.Join(failures...))
+	}
+
+	return result, nil
+}
+
+// monthTarget queries the month totals of a single target in its own context
+func (c *Client) monthTarget(ctx context.Context, target queryTarget) targetResult {
+	account := provider.AccountResult{
+		AccountID:   target.account.ID,
+		AccountName: target.account.Name,
+	}
+	if err := ctx.Err(); err != nil {
+		account.Err = classifyError(err)
+		return targetResult{account: account}
+	}
+
+	queries := len(c.cfg.CostTypes()) * len(monthTimeframes)
+	targetCtx, cancel := context.WithTimeout(ctx, time.Duration(queries)*c.queryTimeout())
+	defer cancel()
+
+	var (
+		result   targetResult
+		failures []error
+		retries  int
+	)
+	start := time.Now()
+	for _, costType := range c.cfg.CostTypes() {
+		for _, timeframe := range monthTimeframes {
+			err := c.retryQuery(targetCtx, target, costType+" "+string(timeframe), &retries, func() error {
+				records, err := c.monthTargetInternal(targetCtx, target, costType, timeframe)
+				if err != nil {
+					return err
+				}
+				if timeframe == armcostmanagement.TimeframeTypeMonthToDate {
+					result.monthToDate = append(result.monthToDate, records...)
+				} else {
+					result.previousMonth = append(result.previousMonth, records...)
+				}
+				return nil
+			})
+			if err != nil {
+				failures = append(failures, fmt.Errorf("subscription %s (ID: %s) %s %s query failed after retries: %w",
+					target.account.Name, target.account.ID, costType, timeframe, err))
+			}
+		}
+	}
+	account.Err = errors.Join(failures...)
+	account.Duration = time.Since(start)
+	account.Retries = retries
+	account.Rows = len(result.monthToDate) + len(result.previousMonth)
+
+	result.account = account
+	return result
+}
+
+// monthTargetInternal performs a single total query over a timeframe without retry logic
+func (c *Client) monthTargetInternal(ctx context.Context, target queryTarget, costType string, timeframe armcostmanagement.TimeframeType) ([]provider.CostRecord, error) {
+	// Without granularity the API returns one row per group with the timeframe total
+	queryType := exportType(costType)
+	dataset, excludes := c.costDataset(target)
+
+	queryDef := armcostmanagement.QueryDefinition{
+		Type:      &queryType,
+		Timeframe: &timeframe,
+		Dataset:   dataset,
+	}
+
+	result, err := c.queryAllPages(ctx, target.tenant, target.scope, queryDef, target.account)
+	if err != nil {
+		return nil, fmt.Errorf("%s cost query failed: %w", timeframe, err)
+	}
+
+	records := excludeRecords(c.parseRows(result, target.account, false), excludes)
+	for i := range records {
+		records[i].CostType = costType
+		records[i].TenantID = target.tenant.cfg.ID
+	}
+	return records, nil
+}
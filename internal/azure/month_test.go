@@ -0,0 +1,119 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+)
+
+// TestQueryMonthCosts tests that month totals are queried per timeframe without granularity
+func TestQueryMonthCosts(t *testing.T) {
+	cfg := testQueryConfig()
+	cfg.GroupBy = config.GroupByConfig{Enabled: true, Groups: []config.GroupBy{{Type: config.GroupTypeDimension, Name: "ServiceName"}}}
+
+	totals := map[armcostmanagement.TimeframeType]float64{
+		armcostmanagement.TimeframeTypeMonthToDate:  120.5,
+		armcostmanagement.TimeframeTypeTheLastMonth: 900,
+	}
+
+	var (
+		mu         sync.Mutex
+		timeframes []armcostmanagement.TimeframeType
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var def armcostmanagement.QueryDefinition
+		if err := json.NewDecoder(r.Body).Decode(&def); err != nil {
+			t.Errorf("Failed to decode query: %v", err)
+		}
+		if def.Dataset.Granularity != nil || def.TimePeriod != nil {
+			t.Errorf("Month query has granularity %v and time period %+v, want neither", def.Dataset.Granularity, def.TimePeriod)
+		}
+		if len(def.Dataset.Grouping) != 1 || *def.Dataset.Grouping[0].Name != "ServiceName" {
+			t.Errorf("Month query grouping = %+v, want the configured grouping", def.Dataset.Grouping)
+		}
+		mu.Lock()
+		timeframes = append(timeframes, *def.Timeframe)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"ServiceName","type":"String"},{"name":"Currency","type":"String"}],`+
+			`"rows":[[%v,"Storage","EUR"]]}}`, totals[*def.Timeframe])
+	})
+	client, _ := newTestServerClient(t, cfg, handler)
+
+	result, err := client.QueryMonthCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryMonthCosts() error = %v", err)
+	}
+
+	if len(timeframes) != 2 || timeframes[0] != armcostmanagement.TimeframeTypeMonthToDate || timeframes[1] != armcostmanagement.TimeframeTypeTheLastMonth {
+		t.Errorf("Queried timeframes = %v, want MonthToDate then TheLastMonth", timeframes)
+	}
+	if len(result.MonthToDate) != 1 || result.MonthToDate[0].Cost != 120.5 || result.MonthToDate[0].Service != "Storage" || result.MonthToDate[0].Date != "" {
+		t.Errorf("MonthToDate = %+v, want one undated Storage total of 120.5", result.MonthToDate)
+	}
+	if len(result.PreviousMonth) != 1 || result.PreviousMonth[0].Cost != 900 || result.PreviousMonth[0].CostType != config.CostTypeActual {
+		t.Errorf("PreviousMonth = %+v, want one actual cost total of 900", result.PreviousMonth)
+	}
+	if len(result.Accounts) != 1 || result.Accounts[0].Err != nil || result.Accounts[0].Rows != 2 {
+		t.Errorf("Accounts = %+v, want one successful account with 2 rows", result.Accounts)
+	}
+}
+
+// TestQueryMonthCosts_AllFailed tests that an error is returned only when every target failed
+func TestQueryMonthCosts_AllFailed(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":{"code":"AuthorizationFailed","message":"denied"}}`))
+	})
+	client, _ := newTestServerClient(t, testQueryConfig(), handler)
+
+	result, err := client.QueryMonthCosts(context.Background())
+	if err == nil {
+		t.Fatal("Expected error when all targets fail")
+	}
+	if len(result.Accounts) != 1 || result.Accounts[0].Err == nil {
+		t.Errorf("Accounts = %+v, want one failed account", result.Accounts)
+	}
+}
+
+// TestQueryMonthCosts_TimeframeFailure tests that the totals of the timeframe that succeeded
+// are kept when the other timeframe fails, and that the failure is recorded on the account
+func TestQueryMonthCosts_TimeframeFailure(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var def armcostmanagement.QueryDefinition
+		if err := json.NewDecoder(r.Body).Decode(&def); err != nil {
+			t.Errorf("Failed to decode query: %v", err)
+		}
+		if *def.Timeframe == armcostmanagement.TimeframeTypeTheLastMonth {
+			http.Error(w, `{"error":{"code":"BadRequest","message":"timeframe not supported"}}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"Currency","type":"String"}],"rows":[[120.5,"EUR"]]}}`))
+	})
+	client, _ := newTestServerClient(t, testQueryConfig(), handler)
+
+	result, err := client.QueryMonthCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryMonthCosts() error = %v, want partial data", err)
+	}
+
+	if len(result.MonthToDate) != 1 || result.MonthToDate[0].Cost != 120.5 || len(result.PreviousMonth) != 0 {
+		t.Errorf("MonthToDate = %+v, PreviousMonth = %+v, want only the month-to-date total", result.MonthToDate, result.PreviousMonth)
+	}
+	if len(result.Accounts) != 1 {
+		t.Fatalf("Got %d account results, want 1", len(result.Accounts))
+	}
+	account := result.Accounts[0]
+	if account.Err == nil || !strings.Contains(account.Err.Error(), "TheLastMonth query failed") || account.Rows != 1 {
+		t.Errorf("Account result = %+v, want TheLastMonth failure with 1 row", account)
+	}
+}
@@ -0,0 +1,121 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// queryAllPages executes a cost query and follows NextLink until all pages are
+// fetched or the configured page cap is reached. Rows from all pages are merged
+// into a single result using the columns of the first page.
+// The API timeout applies to each page request, so large scopes aren't cut off
+// by the number of pages they return.
+func (c *Client) queryAllPages(ctx context.Context, t *tenant, scope string, queryDef armcostmanagement.QueryDefinition, sub config.Subscription) (armcostmanagement.QueryResult, error) {
+	pageCtx, cancel := context.WithTimeout(ctx, c.apiTimeout())
+	resp, err := t.client.Usage(pageCtx, scope, queryDef, nil)
+	cancel()
+	if err != nil {
+		return armcostmanagement.QueryResult{}, err
+	}
+
+	result := resp.QueryResult
+	pages := 1
+	nextLink := nextLinkOf(result)
+
+	for nextLink != "" {
+		if pages >= c.maxQueryPages() {
+			c.logger.Warn("Query page limit reached, cost data is truncated",
+				"subscription_name", sub.Name,
+				"subscription_id", sub.ID,
+				"pages", pages,
+				"max_query_pages", c.maxQueryPages())
+			break
+		}
+
+		pageCtx, cancel := context.WithTimeout(ctx, c.apiTimeout())
+		page, err := c.fetchNextPage(pageCtx, t, nextLink, queryDef)
+		cancel()
+		if err != nil {
+			c.recordPages(sub, pages)
+			return armcostmanagement.QueryResult{}, fmt.Errorf("failed to fetch result page %d: %w", pages+1, err)
+		}
+		pages++
+
+		if page.Properties != nil {
+			if result.Properties == nil {
+				result.Properties = &armcostmanagement.QueryProperties{Columns: page.Properties.Columns}
+			}
+			result.Properties.Rows = append(result.Properties.Rows, page.Properties.Rows...)
+		}
+		nextLink = nextLinkOf(page)
+	}
+
+	c.recordPages(sub, pages)
+	return result, nil
+}
+
+// fetchNextPage requests the page referenced by a NextLink
+// The Cost Management API expects the original query body to be re-posted to the link
+func (c *Client) fetchNextPage(ctx context.Context, t *tenant, nextLink string, queryDef armcostmanagement.QueryDefinition) (armcostmanagement.QueryResult, error) {
+	req, err := runtime.NewRequest(ctx, http.MethodPost, nextLink)
+	if err != nil {
+		return armcostmanagement.QueryResult{}, err
+	}
+	req.Raw().Header["Accept"] = []string{"application/json"}
+	if err := runtime.MarshalAsJSON(req, queryDef); err != nil {
+		return armcostmanagement.QueryResult{}, err
+	}
+
+	resp, err := t.pipeline.Do(req)
+	if err != nil {
+		return armcostmanagement.QueryResult{}, err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK, http.StatusNoContent) {
+		return armcostmanagement.QueryResult{}, runtime.NewResponseError(resp)
+	}
+
+	var result armcostmanagement.QueryResult
+	if err := runtime.UnmarshalAsJSON(resp, &result); err != nil {
+		return armcostmanagement.QueryResult{}, err
+	}
+	return result, nil
+}
+
+// apiTimeout returns the timeout of a single API request
+func (c *Client) apiTimeout() time.Duration {
+	return time.Duration(c.cfg.APITimeout) * time.Second
+}
+
+// queryTimeout returns the time a paged query may take including its retries:
+// the retry budget plus one API timeout for every page it may fetch
+func (c *Client) queryTimeout() time.Duration {
+	return provider.MaxRetryElapsedTime + time.Duration(c.maxQueryPages())*c.apiTimeout()
+}
+
+// maxQueryPages returns the configured page cap, falling back to the default
+func (c *Client) maxQueryPages() int {
+	if c.cfg.MaxQueryPages > 0 {
+		return c.cfg.MaxQueryPages
+	}
+	return config.DefaultMaxQueryPages
+}
+
+// recordPages adds fetched pages to the query pages counter
+func (c *Client) recordPages(sub config.Subscription, pages int) {
+	c.queryPagesTotal.WithLabelValues(string(provider.ProviderAzure), sub.Name, sub.ID).Add(float64(pages))
+}
+
+// nextLinkOf returns the NextLink of a query result, or "" on the last page
+func nextLinkOf(result armcostmanagement.QueryResult) string {
+	if result.Properties == nil || result.Properties.NextLink == nil {
+		return ""
+	}
+	return *result.Properties.NextLink
+}
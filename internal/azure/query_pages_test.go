@@ -0,0 +1,161 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+)
+
+// pagedQueryHandler serves a Cost Management query split across the given number of pages
+// Each page holds one row whose cost equals the page number
+func pagedQueryHandler(t *testing.T, totalPages int, serverURL *string, requests *atomic.Int32) http.Handler {
+	t.Helper()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected POST request, got %s", r.Method)
+		}
+		n := int(requests.Add(1))
+
+		properties := map[string]interface{}{
+			"columns": []map[string]string{
+				{"name": "Cost", "type": "Number"},
+				{"name": "UsageDate", "type": "Number"},
+				{"name": "ServiceName", "type": "String"},
+			},
+			"rows": [][]interface{}{
+				{float64(n), 20260115, fmt.Sprintf("Service %d", n)},
+			},
+		}
+		if n < totalPages {
+			properties["nextLink"] = fmt.Sprintf("%s/subscriptions/test-sub-1/providers/Microsoft.CostManagement/query?api-version=2021-10-01&$skiptoken=page%d", *serverURL, n+1)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{"properties": properties}); err != nil {
+			t.Errorf("Failed to encode response: %v", err)
+		}
+	})
+}
+
+func testQueryConfig() *config.Config {
+	return &config.Config{
+		Subscriptions: []config.Subscription{
+			{ID: "test-sub-1", Name: "test-subscription"},
+		},
+		Currency:   "€",
+		APITimeout: 30,
+		DateRange:  config.DateRange{DaysToQuery: 1},
+	}
+}
+
+// TestQueryCosts_FollowsNextLink tests that all result pages are fetched and merged
+func TestQueryCosts_FollowsNextLink(t *testing.T) {
+	var (
+		serverURL string
+		requests  atomic.Int32
+	)
+	cfg := testQueryConfig()
+	client, url := newTestServerClient(t, cfg, pagedQueryHandler(t, 3, &serverURL, &requests))
+	serverURL = url
+
+	records, err := client.queryCostsForTargetInternal(context.Background(), client.targets()[0], config.CostTypeActual)
+	if err != nil {
+		t.Fatalf("queryCostsForTargetInternal() error = %v", err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records from 3 pages, got %d", len(records))
+	}
+	for i, r := range records {
+		if r.Cost != float64(i+1) {
+			t.Errorf("Record %d cost: got %v, want %v", i, r.Cost, i+1)
+		}
+	}
+
+	pages := testutil.ToFloat64(client.queryPagesTotal.WithLabelValues("azure", "test-subscription", "test-sub-1"))
+	if pages != 3 {
+		t.Errorf("cloud_cost_exporter_query_pages_total: got %v, want 3", pages)
+	}
+}
+
+// TestQueryCosts_PageLimit tests that pagination stops at max_query_pages
+func TestQueryCosts_PageLimit(t *testing.T) {
+	var (
+		serverURL string
+		requests  atomic.Int32
+	)
+	cfg := testQueryConfig()
+	cfg.MaxQueryPages = 2
+	client, url := newTestServerClient(t, cfg, pagedQueryHandler(t, 5, &serverURL, &requests))
+	serverURL = url
+
+	records, err := client.queryCostsForTargetInternal(context.Background(), client.targets()[0], config.CostTypeActual)
+	if err != nil {
+		t.Fatalf("queryCostsForTargetInternal() error = %v", err)
+	}
+
+	if len(records) != 2 {
+		t.Errorf("Expected 2 records when capped at 2 pages, got %d", len(records))
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected 2 API requests, got %d", got)
+	}
+}
+
+// TestQueryCosts_PageTimeout tests that api_timeout applies to each page rather than the whole query
+func TestQueryCosts_PageTimeout(t *testing.T) {
+	var (
+		serverURL string
+		requests  atomic.Int32
+	)
+	paged := pagedQueryHandler(t, 3, &serverURL, &requests)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(600 * time.Millisecond)
+		paged.ServeHTTP(w, r)
+	})
+
+	cfg := testQueryConfig()
+	cfg.APITimeout = 1
+	client, url := newTestServerClient(t, cfg, handler)
+	serverURL = url
+
+	records, err := client.queryCostsForTargetInternal(context.Background(), client.targets()[0], config.CostTypeActual)
+	if err != nil {
+		t.Fatalf("queryCostsForTargetInternal() error = %v, want pages within api_timeout each to succeed", err)
+	}
+	if len(records) != 3 {
+		t.Errorf("Expected 3 records from 3 pages, got %d", len(records))
+	}
+}
+
+// TestQueryCosts_NextPageError tests that a failing NextLink request fails the query
+func TestQueryCosts_NextPageError(t *testing.T) {
+	var (
+		serverURL string
+		requests  atomic.Int32
+	)
+	paged := pagedQueryHandler(t, 3, &serverURL, &requests)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("$skiptoken") != "" {
+			http.Error(w, `{"error":{"code":"InternalServerError","message":"boom"}}`, http.StatusInternalServerError)
+			return
+		}
+		paged.ServeHTTP(w, r)
+	})
+
+	cfg := testQueryConfig()
+	client, url := newTestServerClient(t, cfg, handler)
+	serverURL = url
+
+	if _, err := client.queryCostsForTargetInternal(context.Background(), client.targets()[0], config.CostTypeActual); err == nil {
+		t.Error("Expected error when a NextLink page fails, got nil")
+	}
+}
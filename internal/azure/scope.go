@@ -0,0 +1,95 @@
+package azure
+
+import (
+	"fmt"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+)
+
+// queryTarget is a single Cost Management scope queried by the client
+type queryTarget struct {
+	tenant *tenant // Tenant whose credential queries the scope
+	// account the rows are attributed to; for aggregate scopes this is only a
+	// fallback used when a row carries no SubscriptionId column
+	account config.Subscription
+	scope   string // Cost Management scope path
+	// aggregate is true when the scope spans several subscriptions, in which
+	// case the query is additionally grouped by SubscriptionId and SubscriptionName
+	aggregate bool
+	filter    *config.Filter // Filter of the subscription or scope (nil = top-level filter)
+}
+
+// subscriptionTarget builds the query target for a configured subscription
+func subscriptionTarget(sub config.Subscription) queryTarget {
+	return queryTarget{
+		account: sub,
+		scope:   fmt.Sprintf("/subscriptions/%s", sub.ID),
+		filter:  sub.Filter,
+	}
+}
+
+// scopeTarget builds the query target for a configured scope
+func scopeTarget(scope config.Scope) queryTarget {
+	target := queryTarget{
+		account: config.Subscription{ID: scope.ID, Name: scope.Name},
+		scope:   scopePath(scope),
+		filter:  scope.Filter,
+	}
+
+	switch scope.Type {
+	case config.ScopeTypeSubscription:
+	case config.ScopeTypeResourceGroup:
+		// Resource group costs belong to the enclosing subscription
+		target.account.ID = scope.SubscriptionID
+	default:
+		target.aggregate = true
+	}
+
+	return target
+}
+
+// scopePath returns the Cost Management scope path for a configured scope
+func scopePath(scope config.Scope) string {
+	switch scope.Type {
+	case config.ScopeTypeResourceGroup:
+		return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", scope.SubscriptionID, scope.ID)
+	case config.ScopeTypeManagementGroup:
+		return fmt.Sprintf("/providers/Microsoft.Management/managementGroups/%s", scope.ID)
+	case config.ScopeTypeBillingAccount:
+		return fmt.Sprintf("/providers/Microsoft.Billing/billingAccounts/%s", scope.ID)
+	case config.ScopeTypeBillingProfile:
+		return fmt.Sprintf("/providers/Microsoft.Billing/billingAccounts/%s/billingProfiles/%s", scope.BillingAccountID, scope.ID)
+	case config.ScopeTypeDepartment:
+		return fmt.Sprintf("/providers/Microsoft.Billing/billingAccounts/%s/departments/%s", scope.BillingAccountID, scope.ID)
+	case config.ScopeTypeEnrollmentAccount:
+		return fmt.Sprintf("/providers/Microsoft.Billing/billingAccounts/%s/enrollmentAccounts/%s", scope.BillingAccountID, scope.ID)
+	default:
+		return fmt.Sprintf("/subscriptions/%s", scope.ID)
+	}
+}
+
+// targets returns all query targets in tenant order
+func (c *Client) targets() []queryTarget {
+	var targets []queryTarget
+	for _, t := range c.tenants {
+		targets = append(targets, t.targets()...)
+	}
+	return targets
+}
+
+// targets returns the tenant's query targets: configured and discovered subscriptions followed by scopes
+func (t *tenant) targets() []queryTarget {
+	subs := t.subscriptions()
+	targets := make([]queryTarget, 0, len(subs)+len(t.cfg.Scopes))
+	for _, sub := range subs {
+		target := subscriptionTarget(sub)
+		target.tenant = t
+		targets = append(targets, target)
+	}
+	for _, scope := range t.cfg.Scopes {
+		target := scopeTarget(scope)
+		target.tenant = t
+		targets = append(targets, target)
+	}
+	return targets
+}
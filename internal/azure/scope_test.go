@@ -0,0 +1,175 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+)
+
+// TestScopePath tests scope string construction for every scope type
+func TestScopePath(t *testing.T) {
+	tests := []struct {
+		name  string
+		scope config.Scope
+		want  string
+	}{
+		{
+			"subscription",
+			config.Scope{Type: config.ScopeTypeSubscription, ID: "sub-1"},
+			"/subscriptions/sub-1",
+		},
+		{
+			"resource group",
+			config.Scope{Type: config.ScopeTypeResourceGroup, ID: "prod-rg", SubscriptionID: "sub-1"},
+			"/subscriptions/sub-1/resourceGroups/prod-rg",
+		},
+		{
+			"management group",
+			config.Scope{Type: config.ScopeTypeManagementGroup, ID: "mg-platform"},
+			"/providers/Microsoft.Management/managementGroups/mg-platform",
+		},
+		{
+			"billing account",
+			config.Scope{Type: config.ScopeTypeBillingAccount, ID: "1234567"},
+			"/providers/Microsoft.Billing/billingAccounts/1234567",
+		},
+		{
+			"billing profile",
+			config.Scope{Type: config.ScopeTypeBillingProfile, ID: "BP-1", BillingAccountID: "ba-1"},
+			"/providers/Microsoft.Billing/billingAccounts/ba-1/billingProfiles/BP-1",
+		},
+		{
+			"department",
+			config.Scope{Type: config.ScopeTypeDepartment, ID: "42", BillingAccountID: "1234567"},
+			"/providers/Microsoft.Billing/billingAccounts/1234567/departments/42",
+		},
+		{
+			"enrollment account",
+			config.Scope{Type: config.ScopeTypeEnrollmentAccount, ID: "99", BillingAccountID: "1234567"},
+			"/providers/Microsoft.Billing/billingAccounts/1234567/enrollmentAccounts/99",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scopePath(tt.scope); got != tt.want {
+				t.Errorf("scopePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestScopeTarget_Aggregate tests which scopes are split per subscription
+func TestScopeTarget_Aggregate(t *testing.T) {
+	tests := []struct {
+		scopeType string
+		aggregate bool
+	}{
+		{config.ScopeTypeSubscription, false},
+		{config.ScopeTypeResourceGroup, false},
+		{config.ScopeTypeManagementGroup, true},
+		{config.ScopeTypeBillingAccount, true},
+		{config.ScopeTypeBillingProfile, true},
+		{config.ScopeTypeDepartment, true},
+		{config.ScopeTypeEnrollmentAccount, true},
+	}
+
+	for _, tt := range tests {
+		target := scopeTarget(config.Scope{Type: tt.scopeType, ID: "id", SubscriptionID: "sub", BillingAccountID: "ba"})
+		if target.aggregate != tt.aggregate {
+			t.Errorf("%s: aggregate = %v, want %v", tt.scopeType, target.aggregate, tt.aggregate)
+		}
+	}
+
+	rg := scopeTarget(config.Scope{Type: config.ScopeTypeResourceGroup, ID: "prod-rg", Name: "prod", SubscriptionID: "sub-1"})
+	if rg.account.ID != "sub-1" {
+		t.Errorf("Resource group scope account ID = %q, want sub-1", rg.account.ID)
+	}
+}
+
+// TestParseResponse_SubscriptionColumns tests that rows of aggregate scopes are attributed to their subscription
+func TestParseResponse_SubscriptionColumns(t *testing.T) {
+	client, _ := setupTestClient(t)
+	mg := config.Subscription{ID: "mg-platform", Name: "Platform"}
+
+	result := armcostmanagement.QueryResult{
+		Properties: &armcostmanagement.QueryProperties{
+			Columns: []*armcostmanagement.QueryColumn{
+				{Name: stringPtr("Cost"), Type: stringPtr("Number")},
+				{Name: stringPtr("UsageDate"), Type: stringPtr("Number")},
+				{Name: stringPtr("SubscriptionId"), Type: stringPtr("String")},
+				{Name: stringPtr("SubscriptionName"), Type: stringPtr("String")},
+			},
+			Rows: [][]interface{}{
+				{10.0, 20260115, "sub-a", "Production"},
+				{5.0, 20260115, "sub-b", ""},
+				{1.0, 20260115, "", ""},
+			},
+		},
+	}
+
+	records := client.parseResponse(result, mg)
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+
+	expected := []struct{ id, name string }{
+		{"sub-a", "Production"},
+		{"sub-b", "sub-b"},
+		{"mg-platform", "Platform"},
+	}
+	for i, exp := range expected {
+		if records[i].AccountID != exp.id || records[i].AccountName != exp.name {
+			t.Errorf("Record %d account: got (%q, %q), want (%q, %q)",
+				i, records[i].AccountID, records[i].AccountName, exp.id, exp.name)
+		}
+	}
+}
+
+// TestQueryCosts_ManagementGroupScope tests the request sent for a management group scope
+func TestQueryCosts_ManagementGroupScope(t *testing.T) {
+	var (
+		gotPath  string
+		gotQuery armcostmanagement.QueryDefinition
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&gotQuery); err != nil {
+			t.Errorf("Failed to decode query: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"UsageDate","type":"Number"},{"name":"SubscriptionId","type":"String"},{"name":"SubscriptionName","type":"String"}],"rows":[[3.5,20260115,"sub-a","Production"]]}}`))
+	})
+
+	cfg := testQueryConfig()
+	cfg.Subscriptions = nil
+	cfg.Scopes = []config.Scope{{Type: config.ScopeTypeManagementGroup, ID: "mg-platform", Name: "Platform"}}
+	client, _ := newTestServerClient(t, cfg, handler)
+
+	result, err := client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+	records := result.Records
+
+	if !strings.HasPrefix(gotPath, "/providers/Microsoft.Management/managementGroups/mg-platform/") {
+		t.Errorf("Request path = %q, want management group scope", gotPath)
+	}
+
+	var groupNames []string
+	for _, g := range gotQuery.Dataset.Grouping {
+		groupNames = append(groupNames, *g.Name)
+	}
+	if strings.Join(groupNames, ",") != "SubscriptionId,SubscriptionName" {
+		t.Errorf("Grouping = %v, want [SubscriptionId SubscriptionName]", groupNames)
+	}
+
+	if len(records) != 1 || records[0].AccountID != "sub-a" || records[0].AccountName != "Production" {
+		t.Errorf("Records = %+v, want one record for sub-a/Production", records)
+	}
+}
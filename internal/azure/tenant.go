@@ -0,0 +1,93 @@
+package azure
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/version"
+)
+
+// semverPattern matches the module versions accepted by the SDK
+var semverPattern = regexp.MustCompile(`^v\d+\.\d+\.\d+(?:-[a-zA-Z0-9_.-]+)?$`)
+
+// credentialFactory creates the credential for a tenant's auth configuration
+type credentialFactory func(auth config.AuthConfig) (azcore.TokenCredential, error)
+
+// tenant holds the API clients and discovery state of a single Entra ID tenant
+// Each tenant authenticates with its own credential, so tokens are never shared
+type tenant struct {
+	cfg            config.Tenant
+	client         *armcostmanagement.QueryClient
+	forecastClient *armcostmanagement.ForecastClient
+	pipeline       runtime.Pipeline // Raw ARM pipeline for requests the SDK doesn't model (e.g. NextLink)
+	endpoint       string           // Azure Resource Manager endpoint
+
+	// Subscription discovery
+	discoveryFilter *subscriptionFilter
+	discovery       discoveryState
+
+	// Throttling state shared by all queries of the tenant
+	// Cost Management quotas are enforced per tenant
+	rateLimit *rateLimitState
+}
+
+// newTenant creates the API clients of a tenant
+func (c *Client) newTenant(cfg config.Tenant, newCred credentialFactory, opts *arm.ClientOptions) (*tenant, error) {
+	filter, err := newSubscriptionFilter(cfg.Discovery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery filter: %w", err)
+	}
+
+	cred, err := newCred(cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure credential: %w", err)
+	}
+
+	t := &tenant{
+		cfg:             cfg,
+		discoveryFilter: filter,
+		rateLimit:       &rateLimitState{},
+	}
+
+	opts = c.pipelineOptions(opts, t.rateLimit)
+
+	client, err := armcostmanagement.NewQueryClient(cred, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cost management client: %w", err)
+	}
+
+	forecastClient, err := armcostmanagement.NewForecastClient(cred, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create forecast client: %w", err)
+	}
+
+	armClient, err := arm.NewClient(pipelineModuleName, moduleVersion(), cred, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ARM pipeline: %w", err)
+	}
+
+	t.client = client
+	t.forecastClient = forecastClient
+	t.pipeline = armClient.Pipeline()
+	t.endpoint = armClient.Endpoint()
+	return t, nil
+}
+
+// moduleVersion returns the release version for the User-Agent of raw ARM requests
+// Development builds ("dev") fall back to pipelineModuleVersion
+func moduleVersion() string {
+	v := version.Version
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	if !semverPattern.MatchString(v) {
+		return pipelineModuleVersion
+	}
+	return v
+}
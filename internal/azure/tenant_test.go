@@ -0,0 +1,107 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/version"
+)
+
+// tenantCredential issues tokens naming the tenant it was created for
+type tenantCredential struct {
+	tenantID string
+}
+
+func (c tenantCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token-" + c.tenantID, ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// TestQueryCosts_MultipleTenants tests that each tenant's targets are queried with
+// the tenant's own credential and that records carry the tenant ID
+func TestQueryCosts_MultipleTenants(t *testing.T) {
+	cfg := testQueryConfig()
+	cfg.Auth.TenantID = "home"
+	cfg.Tenants = []config.Tenant{{
+		ID:            "customer",
+		Auth:          config.AuthConfig{TenantID: "customer"},
+		Subscriptions: []config.Subscription{{ID: "customer-sub", Name: "customer-subscription"}},
+	}}
+
+	var (
+		mu     sync.Mutex
+		tokens = make(map[string]string) // subscription ID -> bearer token
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		tokens[strings.Split(r.URL.Path, "/")[2]] = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"UsageDate","type":"Number"}],"rows":[[1.0,20260115]]}}`))
+	})
+
+	var credentialTenants []string
+	newCred := func(auth config.AuthConfig) (azcore.TokenCredential, error) {
+		credentialTenants = append(credentialTenants, auth.TenantID)
+		return tenantCredential{tenantID: auth.TenantID}, nil
+	}
+	client, _ := newTestServerClientWithCredentials(t, cfg, newCred, handler)
+
+	if got := strings.Join(credentialTenants, ","); got != "home,customer" {
+		t.Errorf("Credentials created for tenants %q, want home,customer", got)
+	}
+	if got := client.AccountCount(); got != 2 {
+		t.Errorf("AccountCount() = %d, want 2", got)
+	}
+
+	result, err := client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+
+	if tokens["test-sub-1"] != "token-home" || tokens["customer-sub"] != "token-customer" {
+		t.Errorf("Tokens by subscription = %v, want each subscription queried with its tenant's token", tokens)
+	}
+
+	wantTenants := map[string]string{"test-sub-1": "home", "customer-sub": "customer"}
+	if len(result.Records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(result.Records))
+	}
+	for _, r := range result.Records {
+		if r.TenantID != wantTenants[r.AccountID] {
+			t.Errorf("Record for %s has TenantID %q, want %q", r.AccountID, r.TenantID, wantTenants[r.AccountID])
+		}
+	}
+}
+
+func TestModuleVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"v1.4.0", "v1.4.0"},
+		{"1.4.0", "v1.4.0"},
+		{"v1.4.0-3-gabc1234-dirty", "v1.4.0-3-gabc1234-dirty"},
+		{"dev", pipelineModuleVersion},
+		{"abc1234", pipelineModuleVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			orig := version.Version
+			version.Version = tt.version
+			defer func() { version.Version = orig }()
+
+			if got := moduleVersion(); got != tt.want {
+				t.Errorf("moduleVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// Cost Management throttling headers
+// The API reports a retry delay per exhausted limit (qpu, entity, tenant, client), e.g.
+// x-ms-ratelimit-microsoft.costmanagement-qpu-retry-after: 27
+const (
+	rateLimitHeaderPrefix  = "x-ms-ratelimit-microsoft.costmanagement-"
+	rateLimitRetrySuffix   = "-retry-after"
+	qpuRemainingHeader     = rateLimitHeaderPrefix + "qpu-remaining"
+	genericRetryAfterLimit = "retry_after" // limit label for the standard Retry-After header
+)
+
+// retryableStatusCodes are the status codes retried by the SDK pipeline
+// 429 is deliberately missing: throttling is handled by queryCostsForTarget,
+// which honours the Cost Management retry headers and shares the delay across queries
+var retryableStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// retryAfter returns the longest server-requested delay in a response and the limit it belongs to
+// Both the Cost Management specific headers and the standard Retry-After header are considered
+func retryAfter(resp *http.Response) (time.Duration, string) {
+	if resp == nil {
+		return 0, ""
+	}
+
+	var (
+		delay time.Duration
+		limit string
+	)
+
+	for name, values := range resp.Header {
+		lower := strings.ToLower(name)
+		if !strings.HasPrefix(lower, rateLimitHeaderPrefix) || !strings.HasSuffix(lower, rateLimitRetrySuffix) || len(values) == 0 {
+			continue
+		}
+		if d := parseRetryAfterValue(values[0]); d > delay {
+			delay = d
+			limit = strings.TrimSuffix(strings.TrimPrefix(lower, rateLimitHeaderPrefix), rateLimitRetrySuffix)
+		}
+	}
+
+	if d := parseRetryAfterValue(resp.Header.Get("Retry-After")); d > delay {
+		delay = d
+		limit = genericRetryAfterLimit
+	}
+
+	return delay, limit
+}
+
+// parseRetryAfterValue parses a retry delay given in seconds or as an HTTP date
+func parseRetryAfterValue(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// parseQPURemaining parses the remaining query processing unit quotas
+// The header holds a list of quota=value pairs separated by commas or semicolons;
+// a bare number is reported as the "total" quota
+func parseQPURemaining(value string) map[string]float64 {
+	quotas := make(map[string]float64)
+
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+		part = strings.TrimSpace(part)
+		name, raw, found := strings.Cut(part, "=")
+		if !found {
+			name, raw, found = strings.Cut(part, ":")
+		}
+		if !found {
+			name, raw = "total", part
+		}
+
+		v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			continue
+		}
+		quotas[strings.TrimSpace(name)] = v
+	}
+
+	return quotas
+}
+
+// errorRetryAfter extracts the server-requested delay from a failed API call
+func errorRetryAfter(err error) time.Duration {
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) || respErr.RawResponse == nil {
+		return 0
+	}
+	delay, _ := retryAfter(respErr.RawResponse)
+	return delay
+}
+
+// rateLimitState is the throttling state shared by all queries of a client
+// Once any query is throttled, every query waits until the server-given delay has passed
+type rateLimitState struct {
+	mu           sync.Mutex
+	blockedUntil time.Time
+}
+
+// throttle blocks all queries for the given delay
+func (s *rateLimitState) throttle(delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if until := time.Now().Add(delay); until.After(s.blockedUntil) {
+		s.blockedUntil = until
+	}
+}
+
+// wait blocks until the shared throttling delay has passed or ctx is done
+func (s *rateLimitState) wait(ctx context.Context) error {
+	s.mu.Lock()
+	delay := time.Until(s.blockedUntil)
+	s.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// rateLimitPolicy is a pipeline policy that records Cost Management quota headers
+// and feeds throttling responses into the shared rate-limit state
+type rateLimitPolicy struct {
+	state        *rateLimitState
+	qpuRemaining *prometheus.GaugeVec
+	throttled    *prometheus.CounterVec
+}
+
+// Do implements policy.Policy
+func (p *rateLimitPolicy) Do(req *policy.Request) (*http.Response, error) {
+	resp, err := req.Next()
+	if err != nil {
+		return resp, err
+	}
+
+	if value := resp.Header.Get(qpuRemainingHeader); value != "" {
+		for quota, remaining := range parseQPURemaining(value) {
+			p.qpuRemaining.WithLabelValues(string(provider.ProviderAzure), quota).Set(remaining)
+		}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		delay, limit := retryAfter(resp)
+		if limit == "" {
+			limit = "unknown"
+		}
+		p.throttled.WithLabelValues(string(provider.ProviderAzure), limit).Inc()
+		p.state.throttle(delay)
+	}
+
+	return resp, nil
+}
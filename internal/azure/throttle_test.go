@@ -0,0 +1,184 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestRetryAfter tests parsing of throttling headers
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name      string
+		headers   map[string]string
+		wantDelay time.Duration
+		wantLimit string
+	}{
+		{
+			name:      "no headers",
+			wantDelay: 0,
+		},
+		{
+			name:      "qpu limit",
+			headers:   map[string]string{"x-ms-ratelimit-microsoft.costmanagement-qpu-retry-after": "27"},
+			wantDelay: 27 * time.Second,
+			wantLimit: "qpu",
+		},
+		{
+			name: "longest limit wins",
+			headers: map[string]string{
+				"x-ms-ratelimit-microsoft.costmanagement-entity-retry-after": "10",
+				"x-ms-ratelimit-microsoft.costmanagement-tenant-retry-after": "45",
+				"Retry-After": "5",
+			},
+			wantDelay: 45 * time.Second,
+			wantLimit: "tenant",
+		},
+		{
+			name:      "standard Retry-After",
+			headers:   map[string]string{"Retry-After": "12"},
+			wantDelay: 12 * time.Second,
+			wantLimit: genericRetryAfterLimit,
+		},
+		{
+			name:      "invalid value ignored",
+			headers:   map[string]string{"x-ms-ratelimit-microsoft.costmanagement-client-retry-after": "soon"},
+			wantDelay: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			for k, v := range tt.headers {
+				resp.Header.Set(k, v)
+			}
+
+			delay, limit := retryAfter(resp)
+			if delay != tt.wantDelay || limit != tt.wantLimit {
+				t.Errorf("retryAfter() = (%v, %q), want (%v, %q)", delay, limit, tt.wantDelay, tt.wantLimit)
+			}
+		})
+	}
+}
+
+// TestRetryAfter_HTTPDate tests Retry-After given as an HTTP date
+func TestRetryAfter_HTTPDate(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", time.Now().Add(30*time.Second).UTC().Format(http.TimeFormat))
+
+	delay, _ := retryAfter(resp)
+	if delay < 25*time.Second || delay > 30*time.Second {
+		t.Errorf("retryAfter() = %v, want ~30s", delay)
+	}
+}
+
+// TestParseQPURemaining tests parsing of the remaining quota header
+func TestParseQPURemaining(t *testing.T) {
+	tests := []struct {
+		value string
+		want  map[string]float64
+	}{
+		{"12", map[string]float64{"total": 12}},
+		{"QueryResource=12, QueryTenant=300", map[string]float64{"QueryResource": 12, "QueryTenant": 300}},
+		{"qpu:7;entity:x", map[string]float64{"qpu": 7}},
+		{"", map[string]float64{}},
+	}
+
+	for _, tt := range tests {
+		got := parseQPURemaining(tt.value)
+		if len(got) != len(tt.want) {
+			t.Errorf("parseQPURemaining(%q) = %v, want %v", tt.value, got, tt.want)
+			continue
+		}
+		for k, v := range tt.want {
+			if got[k] != v {
+				t.Errorf("parseQPURemaining(%q)[%s] = %v, want %v", tt.value, k, got[k], v)
+			}
+		}
+	}
+}
+
+// TestRateLimitState_Wait tests that the shared state delays queries and honours cancellation
+func TestRateLimitState_Wait(t *testing.T) {
+	state := &rateLimitState{}
+
+	start := time.Now()
+	if err := state.wait(context.Background()); err != nil || time.Since(start) > 10*time.Millisecond {
+		t.Errorf("wait() without throttling should return immediately, err = %v", err)
+	}
+
+	state.throttle(50 * time.Millisecond)
+	state.throttle(10 * time.Millisecond) // A shorter delay must not shorten the block
+
+	start = time.Now()
+	if err := state.wait(context.Background()); err != nil {
+		t.Fatalf("wait() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("wait() returned after %v, want ~50ms", elapsed)
+	}
+
+	state.throttle(time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := state.wait(ctx); err == nil {
+		t.Error("wait() should fail when the context is cancelled")
+	}
+}
+
+// TestQueryCosts_HonoursRetryAfter tests that a throttled query waits for the
+// server-given delay and that quota and throttle metrics are recorded
+func TestQueryCosts_HonoursRetryAfter(t *testing.T) {
+	var (
+		requests  atomic.Int32
+		throttled time.Time
+		retried   time.Time
+	)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(qpuRemainingHeader, "QueryResource=3")
+
+		if requests.Add(1) == 1 {
+			throttled = time.Now()
+			w.Header().Set("x-ms-ratelimit-microsoft.costmanagement-qpu-retry-after", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"code":"429","message":"Too many requests"}}`))
+			return
+		}
+
+		retried = time.Now()
+		_, _ = w.Write([]byte(`{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"UsageDate","type":"Number"}],"rows":[[1.0,20260115]]}}`))
+	})
+
+	client, _ := newTestServerClient(t, testQueryConfig(), handler)
+
+	result, err := client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+	records := result.Records
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record after retry, got %d", len(records))
+	}
+	if len(result.Accounts) != 1 || result.Accounts[0].Retries != 1 || result.Accounts[0].Rows != 1 {
+		t.Errorf("Account result = %+v, want 1 retry and 1 row", result.Accounts)
+	}
+
+	// Exponential backoff alone would retry after at most 1.5s
+	if wait := retried.Sub(throttled); wait < 2*time.Second {
+		t.Errorf("Retried after %v, want at least the server-given 2s", wait)
+	}
+
+	if got := testutil.ToFloat64(client.throttledRequests.WithLabelValues("azure", "qpu")); got != 1 {
+		t.Errorf("throttled requests = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(client.qpuRemaining.WithLabelValues("azure", "QueryResource")); got != 3 {
+		t.Errorf("qpu remaining = %v, want 3", got)
+	}
+}
@@ -0,0 +1,103 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
+	"github.com/zgpcy/azure-cost-exporter/internal/clock"
+)
+
+// TestParseResponse_Usage tests that usage quantity, meter and unit of measure are parsed
+func TestParseResponse_Usage(t *testing.T) {
+	client, sub := setupTestClient(t)
+
+	records := client.parseResponse(loadMockResponse(t, "mock_response_usage.json"), sub)
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+
+	r := records[0]
+	if r.Cost != 9.6 || r.UsageQuantity != 24 {
+		t.Errorf("Cost/UsageQuantity = %v/%v, want 9.6/24", r.Cost, r.UsageQuantity)
+	}
+	if r.Meter != "D4s v5" || r.UnitOfMeasure != "1 Hour" {
+		t.Errorf("Meter/UnitOfMeasure = %q/%q, want D4s v5/1 Hour", r.Meter, r.UnitOfMeasure)
+	}
+	if records[1].UsageQuantity != 20.5 {
+		t.Errorf("Record 2 UsageQuantity = %v, want 20.5", records[1].UsageQuantity)
+	}
+}
+
+// TestQueryCosts_Usage tests that usage is queried apart from costs, for the current day only,
+// so that the cost query keeps the configured grouping
+func TestQueryCosts_Usage(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		cfg := testQueryConfig()
+		cfg.DateRange.DaysToQuery = 7
+		cfg.Usage.Enabled = enabled
+
+		var defs []armcostmanagement.QueryDefinition
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var def armcostmanagement.QueryDefinition
+			if err := json.NewDecoder(r.Body).Decode(&def); err != nil {
+				t.Errorf("Failed to decode query: %v", err)
+			}
+			defs = append(defs, def)
+			w.Header().Set("Content-Type", "application/json")
+			if _, ok := def.Dataset.Aggregation["totalUsageQuantity"]; ok {
+				_, _ = w.Write([]byte(`{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"UsageQuantity","type":"Number"},{"name":"UsageDate","type":"Number"},{"name":"Meter","type":"String"},{"name":"UnitOfMeasure","type":"String"}],"rows":[[1.0,24,20260115,"D4s v5","1 Hour"]]}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"UsageDate","type":"Number"}],"rows":[[1.0,20260115]]}}`))
+		})
+		client, _ := newTestServerClient(t, cfg, handler)
+		client.clock = clock.Fixed(time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC))
+
+		result, err := client.QueryCosts(context.Background())
+		if err != nil {
+			t.Fatalf("usage enabled=%v: QueryCosts() error = %v", enabled, err)
+		}
+
+		wantQueries := 1
+		if enabled {
+			wantQueries = 2
+		}
+		if len(defs) != wantQueries {
+			t.Fatalf("usage enabled=%v: got %d queries, want %d", enabled, len(defs), wantQueries)
+		}
+
+		cost := defs[0]
+		if _, ok := cost.Dataset.Aggregation["totalUsageQuantity"]; ok || len(cost.Dataset.Grouping) != 0 {
+			t.Errorf("usage enabled=%v: cost query aggregation/grouping = %v/%v, want cost only", enabled, cost.Dataset.Aggregation, cost.Dataset.Grouping)
+		}
+		if len(result.Records) != 1 || result.Records[0].Meter != "" {
+			t.Errorf("usage enabled=%v: cost records = %+v", enabled, result.Records)
+		}
+
+		if !enabled {
+			if result.Usage != nil {
+				t.Errorf("Usage = %+v, want nil when disabled", result.Usage)
+			}
+			continue
+		}
+
+		usage := defs[1]
+		var groupings []string
+		for _, g := range usage.Dataset.Grouping {
+			groupings = append(groupings, *g.Name)
+		}
+		if len(groupings) != 2 || groupings[0] != "Meter" || groupings[1] != "UnitOfMeasure" {
+			t.Errorf("Usage query groupings = %v, want Meter, UnitOfMeasure", groupings)
+		}
+		if from, to := usage.TimePeriod.From.Format(time.DateOnly), usage.TimePeriod.To.Format(time.DateOnly); from != "2026-01-15" || to != "2026-01-15" {
+			t.Errorf("Usage query period = %s to %s, want today only", from, to)
+		}
+		if len(result.Usage) != 1 || result.Usage[0].Meter != "D4s v5" || result.Usage[0].UsageQuantity != 24 {
+			t.Errorf("Usage = %+v, want one D4s v5 record", result.Usage)
+		}
+	}
+}
@@ -0,0 +1,399 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// API versions of the utilization summary APIs
+const (
+	reservationSummariesAPIVersion = "2023-05-01" // Microsoft.Consumption
+	benefitSummariesAPIVersion     = "2023-11-01" // Microsoft.CostManagement
+)
+
+// Charge types of the unused part of commitments in amortized cost data
+const (
+	chargeTypeUnusedReservation = "UnusedReservation"
+	chargeTypeUnusedSavingsPlan = "UnusedSavingsPlan"
+)
+
+// benefitKindSavingsPlan is the kind of savings plan benefit utilization summaries
+// Reservations are also listed by the API but taken from the reservation summaries, which report hours
+const benefitKindSavingsPlan = "SavingsPlan"
+
+// Verify that Client implements provider.UtilizationReader
+var _ provider.UtilizationReader = (*Client)(nil)
+
+// armReservationSummary is a daily reservation summary as returned by the Consumption API
+type armReservationSummary struct {
+	Properties struct {
+		ReservationOrderID       string  `json:"reservationOrderId"`
+		ReservationID            string  `json:"reservationId"`
+		SKUName                  string  `json:"skuName"`
+		ReservedHours            float64 `json:"reservedHours"`
+		UsedHours                float64 `json:"usedHours"`
+		UsageDate                string  `json:"usageDate"`
+		AvgUtilizationPercentage float64 `json:"avgUtilizationPercentage"`
+	} `json:"properties"`
+}
+
+// armBenefitSummary is a daily benefit utilization summary as returned by the Cost Management API
+type armBenefitSummary struct {
+	Kind       string `json:"kind"`
+	Properties struct {
+		BenefitOrderID           string  `json:"benefitOrderId"`
+		BenefitID                string  `json:"benefitId"`
+		ARMSKUName               string  `json:"armSkuName"`
+		UsageDate                string  `json:"usageDate"`
+		AvgUtilizationPercentage float64 `json:"avgUtilizationPercentage"`
+	} `json:"properties"`
+}
+
+// commitmentKey identifies a commitment order and SKU on a single day
+type commitmentKey struct {
+	kind, orderID, sku, date string
+}
+
+// commitmentSummary accumulates the summaries of the commitments of one order, SKU and day
+type commitmentSummary struct {
+	utilization   float64 // Sum of the utilization percentages of all commitments
+	count         int     // Number of commitments summed up
+	reservedHours float64
+	usedHours     float64
+	unusedCost    float64
+	currency      string
+}
+
+// utilizationTargets returns the billing scopes of all tenants in tenant order
+func (c *Client) utilizationTargets() []queryTarget {
+	var targets []queryTarget
+	for _, t := range c.tenants {
+		for _, scope := range t.cfg.Scopes {
+			if !scope.IsBillingScope() {
+				continue
+			}
+			target := scopeTarget(scope)
+			target.tenant = t
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// QueryUtilization retrieves the daily reservation and savings plan utilization of all billing scopes
+// Returns partial data if some of them fail (best-effort approach)
+func (c *Client) QueryUtilization(ctx context.Context) (provider.UtilizationResult, error) {
+	targets := c.utilizationTargets()
+	results := c.queryTargets(ctx, targets, c.utilizationTarget)
+
+	var (
+		result   provider.UtilizationResult
+		failures []error
+	)
+	result.Accounts = make([]provider.AccountResult, 0, len(targets))
+
+	for i, target := range targets {
+		result.Accounts = append(result.Accounts, results[i].account)
+		if err := results[i].account.Err; err != nil {
+			c.logger.Warn("Failed to query commitment utilization, continuing with others",
+				"tenant_id", target.tenant.cfg.ID,
+				"scope_name", target.account.Name,
+				"scope", target.scope,
+				"reason", provider.ErrorReason(err),
+				"error", err)
+			failures = append(failures, fmt.Errorf("scope %s: %w", target.account.Name, err))
+			continue
+		}
+		result.Commitments = append(result.Commitments, results[i].commitments...)
+	}
+
+	if len(failures) == len(targets) && len(failures) > 0 {
+		return provider.UtilizationResult{Accounts: result.Accounts}, fmt.Errorf("utilization query failed for all %d billing scopes: %w",
+			len(targets), errors.Join(failures...))
+	}
+
+	return result, nil
+}
+
+// utilizationPeriod returns the completed days to report: days_to_query days ending yesterday (UTC)
+func utilizationPeriod(now time.Time, days int) (time.Time, time.Time) {
+	now = now.UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+	return to.AddDate(0, 0, -(days - 1)), to
+}
+
+// utilizationTarget queries the reservation summaries, savings plan summaries and
+// unused commitment costs of a single billing scope with retry logic
+func (c *Client) utilizationTarget(ctx context.Context, target queryTarget) targetResult {
+	account := provider.AccountResult{
+		AccountID:   target.account.ID,
+		AccountName: target.account.Name,
+	}
+	from, to := utilizationPeriod(c.clock.Now(), c.cfg.Utilization.DaysToQuery)
+
+	var (
+		reservations []armReservationSummary
+		savingsPlans []armBenefitSummary
+		unused       []provider.CostRecord
+		retries      int
+	)
+	queries := []struct {
+		name  string
+		query func() error
+	}{
+		{"reservation summaries", func() (err error) {
+			reservations, err = c.listReservationSummaries(ctx, target, from, to)
+			return err
+		}},
+		{"savings plan summaries", func() (err error) {
+			savingsPlans, err = c.listSavingsPlanSummaries(ctx, target, from, to)
+			return err
+		}},
+		{"unused commitment cost", func() (err error) {
+			unused, err = c.queryUnusedCommitmentCost(ctx, target, from, to)
+			return err
+		}},
+	}
+
+	start := time.Now()
+	for _, q := range queries {
+		if err := c.retryQuery(ctx, target, q.name, &retries, q.query); err != nil {
+			account.Err = fmt.Errorf("scope %s (ID: %s) %s query failed after retries: %w",
+				target.account.Name, target.account.ID, q.name, err)
+			break
+		}
+	}
+	account.Duration = time.Since(start)
+	account.Retries = retries
+	if account.Err != nil {
+		return targetResult{account: account}
+	}
+
+	commitments := c.mergeUtilization(target, reservations, savingsPlans, unused)
+	account.Rows = len(commitments)
+	return targetResult{commitments: commitments, account: account}
+}
+
+// listReservationSummaries lists the daily summaries of all reservations of a billing scope
+func (c *Client) listReservationSummaries(ctx context.Context, target queryTarget, from, to time.Time) ([]armReservationSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.cfg.APITimeout)*time.Second)
+	defer cancel()
+
+	query := url.Values{
+		"api-version": {reservationSummariesAPIVersion},
+		"grain":       {"daily"},
+		"startDate":   {from.Format("2006-01-02")},
+		"endDate":     {to.Format("2006-01-02")},
+	}
+	link := runtime.JoinPaths(target.tenant.endpoint, target.scope, "/providers/Microsoft.Consumption/reservationSummaries") +
+		"?" + query.Encode()
+	return listAll[armReservationSummary](ctx, target.tenant, link)
+}
+
+// listSavingsPlanSummaries lists the daily utilization summaries of all savings plans of a billing scope
+func (c *Client) listSavingsPlanSummaries(ctx context.Context, target queryTarget, from, to time.Time) ([]armBenefitSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.cfg.APITimeout)*time.Second)
+	defer cancel()
+
+	query := url.Values{
+		"api-version":    {benefitSummariesAPIVersion},
+		"grainParameter": {"Daily"},
+		"$filter": {fmt.Sprintf("properties/usageDate ge '%s' and properties/usageDate le '%s'",
+			from.Format("2006-01-02"), to.Format("2006-01-02"))},
+	}
+	link := runtime.JoinPaths(target.tenant.endpoint, target.scope, "/providers/Microsoft.CostManagement/benefitUtilizationSummaries") +
+		"?" + query.Encode()
+
+	summaries, err := listAll[armBenefitSummary](ctx, target.tenant, link)
+	if err != nil {
+		return nil, err
+	}
+
+	savingsPlans := summaries[:0]
+	for _, s := range summaries {
+		if s.Kind == benefitKindSavingsPlan {
+			savingsPlans = append(savingsPlans, s)
+		}
+	}
+	return savingsPlans, nil
+}
+
+// queryUnusedCommitmentCost queries the daily amortized cost of unused commitments per reservation or savings plan
+func (c *Client) queryUnusedCommitmentCost(ctx context.Context, target queryTarget, from, to time.Time) ([]provider.CostRecord, error) {
+	queryType := armcostmanagement.ExportTypeAmortizedCost
+	timeframe := armcostmanagement.TimeframeTypeCustom
+	granularity := armcostmanagement.GranularityTypeDaily
+	dimension := armcostmanagement.QueryColumnTypeDimension
+	filter := config.Filter{Dimension: &config.FilterComparison{
+		Name:   "ChargeType",
+		Values: []string{chargeTypeUnusedReservation, chargeTypeUnusedSavingsPlan},
+	}}
+
+	queryDef := armcostmanagement.QueryDefinition{
+		Type:       &queryType,
+		Timeframe:  &timeframe,
+		TimePeriod: &armcostmanagement.QueryTimePeriod{From: &from, To: &to},
+		Dataset: &armcostmanagement.QueryDataset{
+			Granularity: &granularity,
+			Aggregation: map[string]*armcostmanagement.QueryAggregation{
+				"totalCost": {
+					Name:     stringPtr(c.costColumn()),
+					Function: functionPtr(armcostmanagement.FunctionTypeSum),
+				},
+			},
+			Grouping: []*armcostmanagement.QueryGrouping{{Type: &dimension, Name: stringPtr("ReservationId")}},
+			Filter:   compileFilter(&filter),
+		},
+	}
+
+	result, err := c.queryAllPages(ctx, target.tenant, target.scope, queryDef, target.account)
+	if err != nil {
+		return nil, fmt.Errorf("unused commitment cost query failed: %w", err)
+	}
+	return c.parseUnusedCost(result), nil
+}
+
+// parseUnusedCost converts an unused commitment cost response to records
+// The commitment ID is carried in ResourceID
+func (c *Client) parseUnusedCost(result armcostmanagement.QueryResult) []provider.CostRecord {
+	var records []provider.CostRecord
+	if result.Properties == nil {
+		return records
+	}
+
+	columnMap := buildColumnMap(result.Properties.Columns)
+	costIdx, hasCost := findColumn(columnMap, c.costColumns())
+	dateIdx, hasDate := columnMap["UsageDate"]
+	if !hasCost || !hasDate {
+		return records
+	}
+
+	for _, row := range result.Properties.Rows {
+		if len(row) <= costIdx || len(row) <= dateIdx {
+			continue
+		}
+		records = append(records, provider.CostRecord{
+			Date:       parseDate(row[dateIdx]),
+			ResourceID: getStringFromRow(row, columnMap, "ReservationId"),
+			Cost:       parseCost(row[costIdx]),
+			Currency:   c.rowCurrency(row, columnMap),
+		})
+	}
+	return records
+}
+
+// mergeUtilization combines the summaries and unused costs of a billing scope per order, SKU and day
+// Reservation utilization is weighted by reserved hours; savings plan utilization is averaged.
+// Unused costs of commitments missing from the summaries are skipped
+func (c *Client) mergeUtilization(target queryTarget, reservations []armReservationSummary, savingsPlans []armBenefitSummary, unused []provider.CostRecord) []provider.CommitmentUtilization {
+	summaries := make(map[commitmentKey]*commitmentSummary)
+	owners := make(map[string]commitmentKey) // Commitment ID -> order and SKU (date unset)
+
+	add := func(key commitmentKey) *commitmentSummary {
+		s, ok := summaries[key]
+		if !ok {
+			s = &commitmentSummary{currency: c.cfg.Currency}
+			summaries[key] = s
+		}
+		return s
+	}
+
+	for _, r := range reservations {
+		p := r.Properties
+		key := commitmentKey{provider.CommitmentKindReservation, commitmentID(p.ReservationOrderID), p.SKUName, parseDate(p.UsageDate)}
+		s := add(key)
+		s.utilization += p.AvgUtilizationPercentage
+		s.count++
+		s.reservedHours += p.ReservedHours
+		s.usedHours += p.UsedHours
+		owners[commitmentID(p.ReservationID)] = commitmentKey{kind: key.kind, orderID: key.orderID, sku: key.sku}
+	}
+
+	for _, b := range savingsPlans {
+		p := b.Properties
+		key := commitmentKey{provider.CommitmentKindSavingsPlan, commitmentID(p.BenefitOrderID), p.ARMSKUName, parseDate(p.UsageDate)}
+		s := add(key)
+		s.utilization += p.AvgUtilizationPercentage
+		s.count++
+		owners[commitmentID(p.BenefitID)] = commitmentKey{kind: key.kind, orderID: key.orderID, sku: key.sku}
+	}
+
+	for _, r := range unused {
+		owner, ok := owners[commitmentID(r.ResourceID)]
+		if !ok {
+			c.logger.Debug("Skipping unused commitment cost of unknown commitment",
+				"scope", target.scope, "commitment_id", r.ResourceID, "date", r.Date)
+			continue
+		}
+		owner.date = r.Date
+		s := add(owner)
+		s.unusedCost += r.Cost
+		s.currency = r.Currency
+	}
+
+	keys := make([]commitmentKey, 0, len(summaries))
+	for key := range summaries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.kind != b.kind {
+			return a.kind < b.kind
+		}
+		if a.orderID != b.orderID {
+			return a.orderID < b.orderID
+		}
+		if a.sku != b.sku {
+			return a.sku < b.sku
+		}
+		return a.date < b.date
+	})
+
+	commitments := make([]provider.CommitmentUtilization, 0, len(keys))
+	for _, key := range keys {
+		s := summaries[key]
+		u := provider.CommitmentUtilization{
+			Provider:    string(provider.ProviderAzure),
+			AccountID:   target.account.ID,
+			AccountName: target.account.Name,
+			Kind:        key.kind,
+			OrderID:     key.orderID,
+			SKU:         key.sku,
+			Date:        key.date,
+			UnusedCost:  s.unusedCost,
+			Currency:    s.currency,
+		}
+		switch {
+		case key.kind == provider.CommitmentKindReservation && s.reservedHours > 0:
+			u.UtilizationPercent = s.usedHours / s.reservedHours * 100
+		case s.count > 0:
+			u.UtilizationPercent = s.utilization / float64(s.count)
+		}
+		if key.kind == provider.CommitmentKindReservation {
+			reserved, used := s.reservedHours, s.usedHours
+			u.ReservedHours, u.UsedHours = &reserved, &used
+		}
+		commitments = append(commitments, u)
+	}
+	return commitments
+}
+
+// commitmentID normalizes a reservation, savings plan or order ID to its lower-case GUID
+// The APIs return either the bare GUID or the full ARM resource ID
+func commitmentID(id string) string {
+	id = strings.TrimSuffix(id, "/")
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		id = id[i+1:]
+	}
+	return strings.ToLower(id)
+}
@@ -0,0 +1,125 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
+	"github.com/zgpcy/azure-cost-exporter/internal/clock"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// TestUtilizationPeriod tests that utilization covers completed days ending yesterday
+func TestUtilizationPeriod(t *testing.T) {
+	from, to := utilizationPeriod(time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC), 3)
+	if got := from.Format("2006-01-02") + " " + to.Format("2006-01-02"); got != "2026-02-27 2026-03-01" {
+		t.Errorf("utilizationPeriod() = %s, want 2026-02-27 2026-03-01", got)
+	}
+}
+
+// TestQueryUtilization tests that reservation and savings plan summaries are merged
+// with the unused commitment costs per order, SKU and day
+func TestQueryUtilization(t *testing.T) {
+	cfg := testQueryConfig()
+	cfg.Scopes = []config.Scope{
+		{Type: config.ScopeTypeBillingAccount, ID: "12345678", Name: "contoso"},
+		{Type: config.ScopeTypeManagementGroup, ID: "mg-root", Name: "root"},
+	}
+	cfg.Utilization = config.UtilizationConfig{Enabled: true, DaysToQuery: 1}
+
+	const scope = "/providers/Microsoft.Billing/billingAccounts/12345678"
+	var costQuery armcostmanagement.QueryDefinition
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var fixture string
+		switch r.URL.Path {
+		case scope + "/providers/Microsoft.Consumption/reservationSummaries":
+			if q := r.URL.Query(); q.Get("grain") != "daily" || q.Get("startDate") != "2026-01-14" || q.Get("endDate") != "2026-01-14" {
+				t.Errorf("Unexpected reservation summary query %s", r.URL.RawQuery)
+			}
+			fixture = "reservation_summaries.json"
+		case scope + "/providers/Microsoft.CostManagement/benefitUtilizationSummaries":
+			if q := r.URL.Query(); q.Get("grainParameter") != "Daily" || q.Get("api-version") != benefitSummariesAPIVersion {
+				t.Errorf("Unexpected benefit summary query %s", r.URL.RawQuery)
+			}
+			fixture = "benefit_utilization_summaries.json"
+		case scope + "/providers/Microsoft.CostManagement/query":
+			if err := json.NewDecoder(r.Body).Decode(&costQuery); err != nil {
+				t.Errorf("Failed to decode query: %v", err)
+			}
+			fixture = "unused_commitment_cost.json"
+		default:
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		data, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			t.Fatalf("Failed to read fixture %s: %v", fixture, err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	})
+	client, _ := newTestServerClient(t, cfg, handler)
+	client.clock = clock.Fixed(time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC))
+
+	result, err := client.QueryUtilization(context.Background())
+	if err != nil {
+		t.Fatalf("QueryUtilization() error = %v", err)
+	}
+
+	if costQuery.Type == nil || *costQuery.Type != armcostmanagement.ExportTypeAmortizedCost {
+		t.Errorf("Unused cost query type = %v, want AmortizedCost", costQuery.Type)
+	}
+	if f := costQuery.Dataset.Filter; f == nil || f.Dimensions == nil || *f.Dimensions.Name != "ChargeType" || len(f.Dimensions.Values) != 2 {
+		t.Errorf("Unused cost filter = %+v, want both unused charge types", f)
+	}
+
+	if len(result.Accounts) != 1 || result.Accounts[0].AccountID != "12345678" || result.Accounts[0].Err != nil {
+		t.Fatalf("Accounts = %+v, want only the billing account", result.Accounts)
+	}
+	if len(result.Commitments) != 2 {
+		t.Fatalf("Expected 2 commitments, got %d: %+v", len(result.Commitments), result.Commitments)
+	}
+
+	ri := result.Commitments[0]
+	if ri.Kind != provider.CommitmentKindReservation || ri.OrderID != "0a1b2c3d-0000-0000-0000-000000000001" || ri.SKU != "Standard_D4s_v5" || ri.Date != "2026-01-14" {
+		t.Errorf("Unexpected reservation %+v", ri)
+	}
+	if ri.ReservedHours == nil || *ri.ReservedHours != 72 || ri.UsedHours == nil || *ri.UsedHours != 60 {
+		t.Errorf("Reservation hours = %v/%v, want 72 reserved and 60 used", ri.ReservedHours, ri.UsedHours)
+	}
+	if want := 60.0 / 72.0 * 100; math.Abs(ri.UtilizationPercent-want) > 1e-9 || ri.UnusedCost != 4.8 || ri.Currency != "EUR" {
+		t.Errorf("Reservation utilization/unused cost = %v/%v %s, want %v/4.8 EUR", ri.UtilizationPercent, ri.UnusedCost, ri.Currency, want)
+	}
+
+	sp := result.Commitments[1]
+	if sp.Kind != provider.CommitmentKindSavingsPlan || sp.OrderID != "9f8e7d6c-0000-0000-0000-000000000002" || sp.SKU != "Compute_Savings_Plan" {
+		t.Errorf("Unexpected savings plan %+v", sp)
+	}
+	if sp.UtilizationPercent != 92.5 || sp.UnusedCost != 1.25 || sp.ReservedHours != nil {
+		t.Errorf("Savings plan utilization/unused cost/hours = %v/%v/%v, want 92.5/1.25/nil", sp.UtilizationPercent, sp.UnusedCost, sp.ReservedHours)
+	}
+}
+
+// TestCommitmentID tests normalization of commitment IDs
+func TestCommitmentID(t *testing.T) {
+	tests := map[string]string{
+		"0A1B2C3D": "0a1b2c3d",
+		"/providers/Microsoft.Capacity/reservationorders/AB":                  "ab",
+		"/providers/Microsoft.Capacity/reservationorders/ab/reservations/CD/": "cd",
+		"": "",
+	}
+	for id, want := range tests {
+		if got := commitmentID(id); got != want {
+			t.Errorf("commitmentID(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
@@ -14,3 +14,11 @@ type RealClock struct{}
 func (RealClock) Now() time.Time {
 	return time.Now()
 }
+
+// Fixed implements Clock with a time that never changes, for testing
+type Fixed time.Time
+
+// Now returns the fixed time
+func (f Fixed) Now() time.Time {
+	return time.Time(f)
+}
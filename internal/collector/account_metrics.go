@@ -0,0 +1,127 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// accountLabels are the labels of all per-account health metrics
+var accountLabels = []string{"provider", "account_name", "account_id"}
+
+// accountState is the last known query outcome of a single account
+type accountState struct {
+	result      provider.AccountResult
+	lastSuccess time.Time // Zero until the account has been queried successfully
+}
+
+// accountMetrics holds the descriptors of the per-account health metrics
+type accountMetrics struct {
+	up          *prometheus.Desc
+	duration    *prometheus.Desc
+	retries     *prometheus.Desc
+	rows        *prometheus.Desc
+	lastSuccess *prometheus.Desc
+}
+
+// newAccountMetrics creates the per-account health metric descriptors
+func newAccountMetrics() accountMetrics {
+	return accountMetrics{
+		up: prometheus.NewDesc(
+			"cloud_cost_exporter_account_up",
+			"Was the last cost query for the account successful (1 = success, 0 = failure)",
+			accountLabels, nil,
+		),
+		duration: prometheus.NewDesc(
+			"cloud_cost_exporter_account_query_duration_seconds",
+			"Duration of the last cost query for the account in seconds, including retries",
+			accountLabels, nil,
+		),
+		retries: prometheus.NewDesc(
+			"cloud_cost_exporter_account_query_retries",
+			"Number of API calls retried during the last cost query for the account",
+			accountLabels, nil,
+		),
+		rows: prometheus.NewDesc(
+			"cloud_cost_exporter_account_rows",
+			"Number of cost records returned by the last cost query for the account",
+			accountLabels, nil,
+		),
+		lastSuccess: prometheus.NewDesc(
+			"cloud_cost_exporter_account_last_success_timestamp_seconds",
+			"Unix timestamp of the last successful cost query for the account",
+			accountLabels, nil,
+		),
+	}
+}
+
+// describe sends all per-account descriptors
+func (m accountMetrics) describe(ch chan<- *prometheus.Desc) {
+	ch <- m.up
+	ch <- m.duration
+	ch <- m.retries
+	ch <- m.rows
+	ch <- m.lastSuccess
+}
+
+// collect sends the per-account metrics of all known accounts
+func (m accountMetrics) collect(ch chan<- prometheus.Metric, providerName string, accounts []accountState) {
+	for _, a := range accounts {
+		labels := []string{providerName, a.result.AccountName, a.result.AccountID}
+
+		up := 0.0
+		if a.result.Err == nil {
+			up = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(m.up, prometheus.GaugeValue, up, labels...)
+		ch <- prometheus.MustNewConstMetric(m.duration, prometheus.GaugeValue, a.result.Duration.Seconds(), labels...)
+		ch <- prometheus.MustNewConstMetric(m.retries, prometheus.GaugeValue, float64(a.result.Retries), labels...)
+		ch <- prometheus.MustNewConstMetric(m.rows, prometheus.GaugeValue, float64(a.result.Rows), labels...)
+
+		if !a.lastSuccess.IsZero() {
+			ch <- prometheus.MustNewConstMetric(m.lastSuccess, prometheus.GaugeValue, float64(a.lastSuccess.Unix()), labels...)
+		}
+	}
+}
+
+// updateAccounts replaces the account states with the outcomes of the latest query
+// Accounts no longer queried (e.g. removed by discovery) are dropped; the last
+// success time of the remaining accounts is carried over. Results sharing the same
+// labels (e.g. a subscription and one of its resource groups) are merged.
+func updateAccounts(previous []accountState, results []provider.AccountResult, now time.Time) []accountState {
+	lastSuccess := make(map[string]time.Time, len(previous))
+	for _, a := range previous {
+		lastSuccess[accountKey(a.result)] = a.lastSuccess
+	}
+
+	accounts := make([]accountState, 0, len(results))
+	index := make(map[string]int, len(results))
+	for _, r := range results {
+		key := accountKey(r)
+		if i, ok := index[key]; ok {
+			merged := &accounts[i].result
+			merged.Duration += r.Duration
+			merged.Retries += r.Retries
+			merged.Rows += r.Rows
+			if merged.Err == nil {
+				merged.Err = r.Err
+			}
+			continue
+		}
+		index[key] = len(accounts)
+		accounts = append(accounts, accountState{result: r, lastSuccess: lastSuccess[key]})
+	}
+
+	for i := range accounts {
+		if accounts[i].result.Err == nil {
+			accounts[i].lastSuccess = now
+		}
+	}
+	return accounts
+}
+
+// accountKey identifies an account by its label values
+func accountKey(r provider.AccountResult) string {
+	return r.AccountID + "|" + r.AccountName
+}
@@ -0,0 +1,158 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/logger"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// budgetLabels are the labels of all budget metrics
+var budgetLabels = []string{"provider", "account_name", "account_id", "scope", "budget_name", "time_grain", "currency"}
+
+// BudgetCollector implements prometheus.Collector for cloud budgets
+// Budgets are refreshed on their own schedule, independently of the cost data
+type BudgetCollector struct {
+	reader providerBudgetReader
+	cfg    *config.Config
+	logger *logger.Logger
+
+	// Metrics
+	amount        *prometheus.Desc
+	currentSpend  *prometheus.Desc
+	forecastSpend *prometheus.Desc
+	threshold     *prometheus.Desc
+	errorsTotal   *prometheus.CounterVec
+
+	// State
+	mu      sync.RWMutex
+	budgets []provider.Budget
+}
+
+// providerBudgetReader is a cloud provider that can list budgets
+type providerBudgetReader interface {
+	provider.CloudProvider
+	provider.BudgetReader
+}
+
+// NewBudgetCollector creates a BudgetCollector if the provider supports budgets
+// Returns nil if it doesn't
+func NewBudgetCollector(cloudProvider provider.CloudProvider, cfg *config.Config, log *logger.Logger) *BudgetCollector {
+	reader, ok := cloudProvider.(providerBudgetReader)
+	if !ok {
+		return nil
+	}
+
+	thresholdLabels := append(append([]string{}, budgetLabels...), "notification", "threshold_type", "operator")
+
+	return &BudgetCollector{
+		reader: reader,
+		cfg:    cfg,
+		logger: log,
+		amount: prometheus.NewDesc(
+			"cloud_budget_amount",
+			"Budget amount for the current budget period",
+			budgetLabels, nil,
+		),
+		currentSpend: prometheus.NewDesc(
+			"cloud_budget_current_spend",
+			"Spend in the current budget period as reported by the cloud provider",
+			budgetLabels, nil,
+		),
+		forecastSpend: prometheus.NewDesc(
+			"cloud_budget_forecast_spend",
+			"Forecasted spend for the current budget period as reported by the cloud provider",
+			budgetLabels, nil,
+		),
+		threshold: prometheus.NewDesc(
+			"cloud_budget_notification_threshold",
+			"Enabled budget notification threshold in percent of the budget amount",
+			thresholdLabels, nil,
+		),
+		errorsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "cloud_cost_exporter_budget_errors_total",
+				Help: "Total number of failed budget refreshes since startup, by failure reason",
+			},
+			[]string{"provider", "reason"},
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *BudgetCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.amount
+	ch <- c.currentSpend
+	ch <- c.forecastSpend
+	ch <- c.threshold
+	c.errorsTotal.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *BudgetCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, b := range c.budgets {
+		labels := []string{b.Provider, b.AccountName, b.AccountID, b.Scope, b.Name, b.TimeGrain, b.Currency}
+
+		ch <- prometheus.MustNewConstMetric(c.amount, prometheus.GaugeValue, b.Amount, labels...)
+		ch <- prometheus.MustNewConstMetric(c.currentSpend, prometheus.GaugeValue, b.CurrentSpend, labels...)
+		if b.ForecastSpend != nil {
+			ch <- prometheus.MustNewConstMetric(c.forecastSpend, prometheus.GaugeValue, *b.ForecastSpend, labels...)
+		}
+		for _, n := range b.Notifications {
+			ch <- prometheus.MustNewConstMetric(c.threshold, prometheus.GaugeValue, n.Threshold,
+				append(labels, n.Name, n.ThresholdType, n.Operator)...)
+		}
+	}
+
+	c.errorsTotal.Collect(ch)
+}
+
+// StartBackgroundRefresh refreshes the budgets now and then periodically until ctx is done
+func (c *BudgetCollector) StartBackgroundRefresh(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(c.cfg.Budgets.RefreshInterval) * time.Second)
+	go func() {
+		defer ticker.Stop()
+		c.refresh(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.refresh(ctx)
+			}
+		}
+	}()
+}
+
+// refresh lists the budgets and updates the cached budgets
+// On failure the previous budgets are kept
+func (c *BudgetCollector) refresh(ctx context.Context) {
+	providerName := c.reader.Name()
+	start := time.Now()
+
+	result, err := c.reader.QueryBudgets(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err != nil {
+		reason := provider.ErrorReason(err)
+		c.errorsTotal.With(prometheus.Labels{"provider": string(providerName), "reason": reason}).Inc()
+		c.logger.Error("Failed to refresh budgets, keeping previous budgets",
+			"provider", providerName, "reason", reason, "error", err)
+		return
+	}
+
+	c.budgets = result.Budgets
+	c.logger.Info("Successfully refreshed budgets",
+		"provider", providerName,
+		"budgets", len(result.Budgets),
+		"duration_seconds", time.Since(start).Seconds())
+}
@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// mockBudgetReader is a cloud provider that also lists budgets
+type mockBudgetReader struct {
+	mockCloudProvider
+	budgets   []provider.Budget
+	budgetErr error
+}
+
+func (m *mockBudgetReader) QueryBudgets(context.Context) (provider.BudgetResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return provider.BudgetResult{Budgets: m.budgets}, m.budgetErr
+}
+
+// TestNewBudgetCollector_Unsupported tests that providers without budgets get no collector
+func TestNewBudgetCollector_Unsupported(t *testing.T) {
+	if c := NewBudgetCollector(&mockCloudProvider{}, &config.Config{}, testLogger()); c != nil {
+		t.Error("Expected nil collector for a provider without budget support")
+	}
+}
+
+// TestBudgetCollector_Collect tests budget metrics and that failed refreshes keep the previous budgets
+func TestBudgetCollector_Collect(t *testing.T) {
+	forecast := 980.0
+	mock := &mockBudgetReader{
+		mockCloudProvider: mockCloudProvider{providerType: provider.ProviderAzure},
+		budgets: []provider.Budget{{
+			Provider: "azure", AccountName: "prod", AccountID: "sub-1", Scope: "/subscriptions/sub-1",
+			Name: "monthly", TimeGrain: "Monthly", Currency: "EUR",
+			Amount: 1000, CurrentSpend: 420.5, ForecastSpend: &forecast,
+			Notifications: []provider.BudgetNotification{
+				{Name: "Actual_GreaterThan_80", Threshold: 80, Operator: "GreaterThan", ThresholdType: "Actual"},
+			},
+		}},
+	}
+
+	collector := NewBudgetCollector(mock, &config.Config{Budgets: config.BudgetsConfig{Enabled: true, RefreshInterval: 3600}}, testLogger())
+	collector.refresh(context.Background())
+
+	want := `
+# HELP cloud_budget_amount Budget amount for the current budget period
+# TYPE cloud_budget_amount gauge
+cloud_budget_amount{account_id="sub-1",account_name="prod",budget_name="monthly",currency="EUR",provider="azure",scope="/subscriptions/sub-1",time_grain="Monthly"} 1000
+# HELP cloud_budget_current_spend Spend in the current budget period as reported by the cloud provider
+# TYPE cloud_budget_current_spend gauge
+cloud_budget_current_spend{account_id="sub-1",account_name="prod",budget_name="monthly",currency="EUR",provider="azure",scope="/subscriptions/sub-1",time_grain="Monthly"} 420.5
+# HELP cloud_budget_forecast_spend Forecasted spend for the current budget period as reported by the cloud provider
+# TYPE cloud_budget_forecast_spend gauge
+cloud_budget_forecast_spend{account_id="sub-1",account_name="prod",budget_name="monthly",currency="EUR",provider="azure",scope="/subscriptions/sub-1",time_grain="Monthly"} 980
+# HELP cloud_budget_notification_threshold Enabled budget notification threshold in percent of the budget amount
+# TYPE cloud_budget_notification_threshold gauge
+cloud_budget_notification_threshold{account_id="sub-1",account_name="prod",budget_name="monthly",currency="EUR",notification="Actual_GreaterThan_80",operator="GreaterThan",provider="azure",scope="/subscriptions/sub-1",threshold_type="Actual",time_grain="Monthly"} 80
+`
+	names := []string{"cloud_budget_amount", "cloud_budget_current_spend", "cloud_budget_forecast_spend", "cloud_budget_notification_threshold"}
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want), names...); err != nil {
+		t.Errorf("Unexpected budget metrics: %v", err)
+	}
+
+	mock.budgetErr = errors.New("budgets unavailable")
+	collector.refresh(context.Background())
+
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want), names...); err != nil {
+		t.Errorf("Budgets not kept after failed refresh: %v", err)
+	}
+	if got := testutil.ToFloat64(collector.errorsTotal.WithLabelValues("azure", provider.ReasonUnknown)); got != 1 {
+		t.Errorf("budget_errors_total = %v, want 1", got)
+	}
+}
@@ -2,6 +2,10 @@ package collector
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -19,109 +23,210 @@ import (
 // At ~200 bytes per record, 100K records = ~20MB
 const MaxRecordsToCache = 100000
 
-// buildMetricLabels builds the label names for the cost metric
-// based on the groupBy configuration
-func buildMetricLabels(cfg *config.Config) []string {
+// metricLabel is a Prometheus label paired with the CostRecord value it exports
+type metricLabel struct {
+	name  string
+	value func(provider.CostRecord) string
+}
+
+// Base labels present on every cost metric
+var (
+	baseLeadingLabels = []metricLabel{
+		{name: "provider", value: func(r provider.CostRecord) string { return r.Provider }},
+		{name: "account_name", value: func(r provider.CostRecord) string { return r.AccountName }},
+		{name: "account_id", value: func(r provider.CostRecord) string { return r.AccountID }},
+		{name: "service", value: func(r provider.CostRecord) string { return r.Service }},
+	}
+	tenantLabel   = metricLabel{name: "tenant_id", value: func(r provider.CostRecord) string { return r.TenantID }}
+	costTypeLabel = metricLabel{name: "cost_type", value: func(r provider.CostRecord) string { return r.CostType }}
+	currencyLabel = metricLabel{name: "currency", value: func(r provider.CostRecord) string { return r.Currency }}
+	dateLabel     = metricLabel{name: "date", value: func(r provider.CostRecord) string { return r.Date }}
+)
+
+// groupLabel builds the metric label for a group_by entry using the dimension registry
+func groupLabel(group config.GroupBy) metricLabel {
+	name := group.MetricLabel()
+
+	if group.Type == config.GroupTypeTagKey {
+		tagKey := group.Name
+		return metricLabel{name: name, value: func(r provider.CostRecord) string { return r.Tags[tagKey] }}
+	}
+
+	if dim, ok := provider.LookupDimension(group.Name); ok {
+		// A label named after a dimension derived from the grouped one exports the derived
+		// value, e.g. ResourceId with label_name resource_name exports the resource name
+		if derived, ok := provider.LookupDimension(name); ok && derived.Derived() && derived.QueryName() == dim.Name {
+			return metricLabel{name: name, value: derived.Value}
+		}
+		return metricLabel{name: name, value: dim.Value}
+	}
+
+	// Unregistered dimensions are rejected by config validation
+	return metricLabel{name: name, value: func(provider.CostRecord) string { return "" }}
+}
+
+// awsGroupLabel builds the metric label for an AWS group_by entry
+// LINKED_ACCOUNT and SERVICE fill the account and service labels every cost metric has
+func awsGroupLabel(groupBy string) (metricLabel, bool) {
+	if tagKey, ok := strings.CutPrefix(groupBy, config.AWSTagPrefix); ok {
+		return groupLabel(config.GroupBy{Type: config.GroupTypeTagKey, Name: tagKey}), true
+	}
+
+	switch groupBy {
+	case config.AWSDimensionRegion:
+		return groupLabel(config.GroupBy{Type: config.GroupTypeDimension, Name: "ResourceLocation"}), true
+	case config.AWSDimensionUsageType:
+		// "meter" is taken by the usage metrics, which add it to the cost labels
+		return metricLabel{name: config.AWSUsageTypeLabel, value: func(r provider.CostRecord) string { return r.Meter }}, true
+	case config.AWSDimensionRecordType:
+		return groupLabel(config.GroupBy{Type: config.GroupTypeDimension, Name: "ChargeType"}), true
+	case config.AWSDimensionPurchaseType:
+		return groupLabel(config.GroupBy{Type: config.GroupTypeDimension, Name: "PricingModel"}), true
+	}
+	return metricLabel{}, false
+}
+
+// buildMetricLabels builds the labels for the cost metric
+// based on the groupBy configuration and the groupings of the other providers
+func buildMetricLabels(cfg *config.Config) []metricLabel {
 	// Base labels always present
-	labels := []string{"provider", "account_name", "account_id", "service"}
+	labels := append([]metricLabel{}, baseLeadingLabels...)
 
 	// Add dynamic labels from groupBy configuration
 	if cfg.GroupBy.Enabled {
 		for _, group := range cfg.GroupBy.Groups {
-			labels = append(labels, group.LabelName)
+			labels = append(labels, groupLabel(group))
 		}
 	}
 
+	// Add the labels of AWS groupings; a label shared with the groupBy configuration
+	// (e.g. the same tag) is exported once and filled by every provider
+	for _, p := range cfg.Providers {
+		if p.AWS == nil {
+			continue
+		}
+		for _, g := range p.AWS.GroupBy {
+			label, ok := awsGroupLabel(g)
+			if ok && !slices.ContainsFunc(labels, func(l metricLabel) bool { return l.name == label.name }) {
+				labels = append(labels, label)
+			}
+		}
+	}
+
+	// Distinguish tenants when several are configured
+	if cfg.MultiTenant() {
+		labels = append(labels, tenantLabel)
+	}
+
+	// Distinguish actual and amortized costs when any provider queries both
+	if cfg.QueriesBothCostTypes() {
+		labels = append(labels, costTypeLabel)
+	}
+
 	// Trailing labels always present
-	labels = append(labels, "currency")
+	labels = append(labels, currencyLabel)
 
 	return labels
 }
 
-// extractLabelValues extracts label values from a CostRecord based on label names
-func extractLabelValues(record provider.CostRecord, labelNames []string) []string {
-	values := make([]string, len(labelNames))
-
-	for i, labelName := range labelNames {
-		switch labelName {
-		case "provider":
-			values[i] = record.Provider
-		case "account_name":
-			values[i] = record.AccountName
-		case "account_id":
-			values[i] = record.AccountID
-		case "service":
-			values[i] = record.Service
-		case "resource_type":
-			values[i] = record.ResourceType
-		case "resource_group":
-			values[i] = record.ResourceGroup
-		case "resource_location":
-			values[i] = record.ResourceLocation
-		case "resource_id":
-			values[i] = record.ResourceID
-		case "resource_name":
-			values[i] = record.ResourceName
-		case "meter_category":
-			values[i] = record.MeterCategory
-		case "meter_subcategory":
-			values[i] = record.MeterSubCategory
-		case "charge_type":
-			values[i] = record.ChargeType
-		case "pricing_model":
-			values[i] = record.PricingModel
-		case "date":
-			values[i] = record.Date
-		case "currency":
-			values[i] = record.Currency
-		default:
-			values[i] = ""
-		}
+// labelNames returns the names of the given labels
+func labelNames(labels []metricLabel) []string {
+	names := make([]string, len(labels))
+	for i, l := range labels {
+		names[i] = l.name
 	}
+	return names
+}
 
+// extractLabelValues extracts label values from a CostRecord for the given labels
+func extractLabelValues(record provider.CostRecord, labels []metricLabel) []string {
+	values := make([]string, len(labels))
+	for i, l := range labels {
+		values[i] = l.value(record)
+	}
 	return values
 }
 
+// ProviderSchedule is a cost provider together with the interval its costs are refreshed at
+type ProviderSchedule struct {
+	Provider        provider.CloudProvider
+	RefreshInterval time.Duration
+}
+
+// providerState is the cached cost data and refresh outcome of a single provider
+type providerState struct {
+	cloudProvider   provider.CloudProvider
+	refreshInterval time.Duration
+	monthReader     provider.MonthCostReader // nil unless month totals are enabled and supported
+	forecaster      provider.Forecaster      // nil unless forecasts are enabled and supported
+
+	// State (guarded by CostCollector.mu)
+	lastRecords          []provider.CostRecord // Today's live data
+	lastUsage            []provider.CostRecord // Today's usage per meter
+	completedDayRecords  []provider.CostRecord // Yesterday's finalized data
+	lastCompletedDay     string                // Last date we queried for completed data (YYYY-MM-DD)
+	lastError            error
+	lastScrape           time.Time
+	lastScrapeDuration   time.Duration
+	accounts             []accountState // Per-account outcome of the last refresh
+	isReady              bool
+	monthToDateRecords   []provider.CostRecord
+	previousMonthRecords []provider.CostRecord
+	forecastRecords      []provider.ForecastRecord
+}
+
+// name returns the provider name used in labels and logs
+func (p *providerState) name() provider.ProviderType {
+	return p.cloudProvider.Name()
+}
+
 // CostCollector implements prometheus.Collector for cloud cost metrics
+// Every provider is refreshed on its own schedule; all of them feed the same
+// metric families, told apart by the provider label
 type CostCollector struct {
-	cloudProvider provider.CloudProvider
-	cfg           *config.Config
-	logger        *logger.Logger
-	clock         clock.Clock // Time provider for testing
+	providers []*providerState // In configuration order
+	cfg       *config.Config
+	logger    *logger.Logger
+	clock     clock.Clock // Time provider for testing
 
 	// Metrics
 	costMetric                *prometheus.Desc
-	costMetricLabelNames      []string // Dynamic label names from groupBy config
+	costMetricLabels          []metricLabel // Dynamic labels from groupBy config
 	completedDailyCostMetric  *prometheus.Desc
-	completedCostMetricLabels []string // Label names with 'date' added
+	completedCostMetricLabels []metricLabel // Labels with 'date' added
 	upMetric                  *prometheus.Desc
 	scrapeDurationMetric      *prometheus.Desc
 	scrapeErrorsTotal         *prometheus.CounterVec // Proper counter metric
 	lastScrapeTimeMetric      *prometheus.Desc
 	recordCountMetric         *prometheus.Desc
+	accountMetrics            accountMetrics       // Per-account health metrics
+	usage                     *usageMetric         // nil unless usage metrics are enabled
+	month                     *monthMetric         // nil unless month totals are enabled and supported
+	forecast                  *forecastMetric      // nil unless forecasts are enabled and supported
 	buildInfo                 *prometheus.GaugeVec // Build version information
 
 	// State
-	mu                  sync.RWMutex
-	lastRecords         []provider.CostRecord // Today's live data
-	completedDayRecords []provider.CostRecord // Yesterday's finalized data
-	lastCompletedDay    string                // Last date we queried for completed data (YYYY-MM-DD)
-	lastError           error
-	lastScrape          time.Time
-	lastScrapeDuration  time.Duration
-	refreshStarted      atomic.Bool // Prevent multiple refresh goroutines
-	isReady             bool
+	mu             sync.RWMutex
+	refreshStarted atomic.Bool // Prevent multiple refresh goroutines
 }
 
-// NewCostCollector creates a new CostCollector
+// NewCostCollector creates a CostCollector for a single provider refreshed every refresh_interval
 func NewCostCollector(cloudProvider provider.CloudProvider, cfg *config.Config, log *logger.Logger) *CostCollector {
+	return NewMultiCostCollector([]ProviderSchedule{{
+		Provider:        cloudProvider,
+		RefreshInterval: time.Duration(cfg.RefreshInterval) * time.Second,
+	}}, cfg, log)
+}
+
+// NewMultiCostCollector creates a CostCollector for several providers, each refreshed on its own schedule
+func NewMultiCostCollector(schedules []ProviderSchedule, cfg *config.Config, log *logger.Logger) *CostCollector {
 	// Create proper counter metric for scrape errors
 	scrapeErrorsTotal := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "cloud_cost_exporter_scrape_errors_total",
-			Help: "Total number of cloud cost data scrape errors since startup",
+			Help: "Total number of cloud cost data scrape errors since startup, by failure reason",
 		},
-		[]string{"provider"},
+		[]string{"provider", "reason"},
 	)
 
 	// Create build info metric
@@ -146,27 +251,40 @@ func NewCostCollector(cloudProvider provider.CloudProvider, cfg *config.Config,
 	metricLabels := buildMetricLabels(cfg)
 
 	// Build labels for completed daily metric (includes 'date')
-	completedDailyLabels := append([]string{}, metricLabels...)
-	completedDailyLabels = append(completedDailyLabels, "date")
+	completedDailyLabels := append([]metricLabel{}, metricLabels...)
+	completedDailyLabels = append(completedDailyLabels, dateLabel)
+
+	// Detect the optional capabilities of every provider
+	providers := make([]*providerState, len(schedules))
+	for i, s := range schedules {
+		p := &providerState{cloudProvider: s.Provider, refreshInterval: s.RefreshInterval}
+		if reader, ok := s.Provider.(provider.MonthCostReader); ok && cfg.MonthTotals.Enabled {
+			p.monthReader = reader
+		}
+		if forecaster, ok := s.Provider.(provider.Forecaster); ok && cfg.Forecast.Enabled {
+			p.forecaster = forecaster
+		}
+		providers[i] = p
+	}
 
 	return &CostCollector{
-		cloudProvider: cloudProvider,
-		cfg:           cfg,
-		logger:        log,
-		clock:         clock.RealClock{}, // Use real system time by default
+		providers: providers,
+		cfg:       cfg,
+		logger:    log,
+		clock:     clock.RealClock{}, // Use real system time by default
 		// Cost metric with dynamic labels based on groupBy configuration (TODAY ONLY)
 		costMetric: prometheus.NewDesc(
 			"cloud_cost_daily",
 			"Current day's cloud cost (live updates). Resets at midnight. For historical data, use cloud_cost_completed_daily.",
-			metricLabels,
+			labelNames(metricLabels),
 			nil,
 		),
-		costMetricLabelNames: metricLabels,
+		costMetricLabels: metricLabels,
 		// Completed daily cost metric (HISTORICAL with date label)
 		completedDailyCostMetric: prometheus.NewDesc(
 			"cloud_cost_completed_daily",
 			"Completed daily cloud costs with date label. Query for historical multi-day aggregations.",
-			completedDailyLabels,
+			labelNames(completedDailyLabels),
 			nil,
 		),
 		completedCostMetricLabels: completedDailyLabels,
@@ -195,7 +313,11 @@ func NewCostCollector(cloudProvider provider.CloudProvider, cfg *config.Config,
 			[]string{"provider"},
 			nil,
 		),
-		buildInfo: buildInfo,
+		accountMetrics: newAccountMetrics(),
+		usage:          newUsageMetric(cfg, metricLabels),
+		month:          newMonthMetric(providers, metricLabels),
+		forecast:       newForecastMetric(providers, metricLabels),
+		buildInfo:      buildInfo,
 	}
 }
 
@@ -208,6 +330,16 @@ func (c *CostCollector) Describe(ch chan<- *prometheus.Desc) {
 	c.scrapeErrorsTotal.Describe(ch) // Describe the counter
 	ch <- c.lastScrapeTimeMetric
 	ch <- c.recordCountMetric
+	c.accountMetrics.describe(ch)
+	if c.usage != nil {
+		c.usage.describe(ch)
+	}
+	if c.month != nil {
+		c.month.describe(ch)
+	}
+	if c.forecast != nil {
+		c.forecast.describe(ch)
+	}
 	c.buildInfo.Describe(ch) // Describe build info
 }
 
@@ -216,7 +348,13 @@ func (c *CostCollector) Collect(ch chan<- prometheus.Metric) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	providerName := string(c.cloudProvider.Name())
+	// Records of all providers; the provider label keeps their series apart
+	var todayRecords, completedRecords, usageRecords []provider.CostRecord
+	for _, p := range c.providers {
+		todayRecords = append(todayRecords, p.lastRecords...)
+		completedRecords = append(completedRecords, p.completedDayRecords...)
+		usageRecords = append(usageRecords, p.lastUsage...)
+	}
 
 	// Aggregate costs by label values
 	// Key is a string representation of all label values joined together
@@ -227,9 +365,9 @@ func (c *CostCollector) Collect(ch chan<- prometheus.Metric) {
 	})
 
 	// Aggregate costs for all records
-	for _, record := range c.lastRecords {
+	for _, record := range todayRecords {
 		// Extract label values dynamically based on configured label names
-		labelValues := extractLabelValues(record, c.costMetricLabelNames)
+		labelValues := extractLabelValues(record, c.costMetricLabels)
 
 		// Create unique key from label values
 		key := labelKey(strings.Join(labelValues, "|"))
@@ -257,7 +395,7 @@ func (c *CostCollector) Collect(ch chan<- prometheus.Metric) {
 		cost        float64
 	})
 
-	for _, record := range c.completedDayRecords {
+	for _, record := range completedRecords {
 		// Extract label values including 'date'
 		labelValues := extractLabelValues(record, c.completedCostMetricLabels)
 		key := labelKey(strings.Join(labelValues, "|"))
@@ -277,9 +415,39 @@ func (c *CostCollector) Collect(ch chan<- prometheus.Metric) {
 		)
 	}
 
+	// Export today's usage quantities and unit prices
+	if c.usage != nil {
+		c.usage.collect(ch, usageRecords)
+	}
+
+	for _, p := range c.providers {
+		c.collectHealth(ch, p)
+	}
+
+	// Collect scrape errors counter (proper counter that survives across scrapes)
+	c.scrapeErrorsTotal.Collect(ch)
+
+	// Send month-to-date and previous month totals
+	if c.month != nil {
+		c.month.collect(ch, c.providers)
+	}
+
+	// Send forecast for the rest of the month
+	if c.forecast != nil {
+		c.forecast.collect(ch, c.providers, c.clock.Now().Format("2006-01-02"))
+	}
+
+	// Collect build info metric
+	c.buildInfo.Collect(ch)
+}
+
+// collectHealth sends the up, scrape and per-account metrics of a provider
+func (c *CostCollector) collectHealth(ch chan<- prometheus.Metric, p *providerState) {
+	providerName := string(p.name())
+
 	// Send up metric
 	upValue := 0.0
-	if c.lastError == nil && len(c.lastRecords) > 0 {
+	if p.lastError == nil && len(p.lastRecords) > 0 {
 		upValue = 1.0
 	}
 	ch <- prometheus.MustNewConstMetric(
@@ -293,19 +461,16 @@ func (c *CostCollector) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(
 		c.scrapeDurationMetric,
 		prometheus.GaugeValue,
-		c.lastScrapeDuration.Seconds(),
+		p.lastScrapeDuration.Seconds(),
 		providerName,
 	)
 
-	// Collect scrape errors counter (proper counter that survives across scrapes)
-	c.scrapeErrorsTotal.Collect(ch)
-
 	// Send last scrape time metric
-	if !c.lastScrape.IsZero() {
+	if !p.lastScrape.IsZero() {
 		ch <- prometheus.MustNewConstMetric(
 			c.lastScrapeTimeMetric,
 			prometheus.GaugeValue,
-			float64(c.lastScrape.Unix()),
+			float64(p.lastScrape.Unix()),
 			providerName,
 		)
 	}
@@ -314,15 +479,15 @@ func (c *CostCollector) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(
 		c.recordCountMetric,
 		prometheus.GaugeValue,
-		float64(len(c.lastRecords)),
+		float64(len(p.lastRecords)),
 		providerName,
 	)
 
-	// Collect build info metric
-	c.buildInfo.Collect(ch)
+	// Send per-account health metrics
+	c.accountMetrics.collect(ch, providerName, p.accounts)
 }
 
-// StartBackgroundRefresh starts a goroutine that periodically refreshes cost data
+// StartBackgroundRefresh starts one goroutine per provider that periodically refreshes its cost data
 // Uses atomic flag to prevent multiple refresh goroutines
 func (c *CostCollector) StartBackgroundRefresh(ctx context.Context) {
 	// Prevent multiple refresh goroutines
@@ -334,35 +499,66 @@ func (c *CostCollector) StartBackgroundRefresh(ctx context.Context) {
 	// Initial fetch
 	c.refresh(ctx)
 
-	// Background refresh loop
-	ticker := time.NewTicker(time.Duration(c.cfg.RefreshInterval) * time.Second)
-	go func() {
-		defer ticker.Stop()
-		defer c.refreshStarted.Store(false) // Reset on exit
-		for {
-			select {
-			case <-ctx.Done():
-				c.logger.Info("Stopping background refresh")
-				return
-			case <-ticker.C:
-				c.refresh(ctx)
+	// Month totals and forecasts run on their own schedule
+	if c.month != nil {
+		c.startMonthRefresh(ctx)
+	}
+	if c.forecast != nil {
+		c.startForecastRefresh(ctx)
+	}
+
+	// Background refresh loops
+	var wg sync.WaitGroup
+	for _, p := range c.providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(p.refreshInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					c.logger.Info("Stopping background refresh", "provider", p.name())
+					return
+				case <-ticker.C:
+					c.refreshProvider(ctx, p)
+				}
 			}
-		}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		c.refreshStarted.Store(false) // Reset on exit
 	}()
 }
 
-// refresh queries the cloud provider and updates the cached data
+// refresh queries all providers concurrently and waits for their cached data to be updated
 func (c *CostCollector) refresh(ctx context.Context) {
-	providerName := c.cloudProvider.Name()
+	var wg sync.WaitGroup
+	for _, p := range c.providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.refreshProvider(ctx, p)
+		}()
+	}
+	wg.Wait()
+}
+
+// refreshProvider queries a cloud provider and updates its cached data
+func (c *CostCollector) refreshProvider(ctx context.Context, p *providerState) {
+	providerName := p.name()
 	c.logger.Info("Refreshing cost data", "provider", providerName)
 	start := time.Now()
 
-	records, err := c.cloudProvider.QueryCosts(ctx)
+	result, err := p.cloudProvider.QueryCosts(ctx)
 	duration := time.Since(start)
+	records := result.Records
 
 	// Enforce memory limits
 	if len(records) > MaxRecordsToCache {
 		c.logger.Warn("Received records exceeding limit, truncating to prevent memory issues",
+			"provider", providerName,
 			"received_count", len(records),
 			"limit", MaxRecordsToCache)
 		records = records[:MaxRecordsToCache]
@@ -371,17 +567,26 @@ func (c *CostCollector) refresh(ctx context.Context) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.lastScrape = c.clock.Now()
-	c.lastScrapeDuration = duration
-	c.lastError = err
+	p.lastScrape = c.clock.Now()
+	p.lastScrapeDuration = duration
+	p.lastError = err
+	p.accounts = updateAccounts(p.accounts, result.Accounts, p.lastScrape)
 
 	if err != nil {
-		c.scrapeErrorsTotal.With(prometheus.Labels{"provider": string(providerName)}).Inc()
-		c.logger.Error("Failed to refresh cost data", "provider", providerName, "error", err)
-		c.isReady = false
+		reason := provider.ErrorReason(err)
+		c.scrapeErrorsTotal.With(prometheus.Labels{"provider": string(providerName), "reason": reason}).Inc()
+		c.logger.Error("Failed to refresh cost data", "provider", providerName, "reason", reason, "error", err)
+		p.isReady = false
 		return
 	}
 
+	// The currency label keeps the series apart, but sums across it would be meaningless
+	if currencies := recordCurrencies(records); len(currencies) > 1 {
+		c.logger.Warn("Cost records use several currencies, aggregate per currency label or set cost_in_usd",
+			"provider", providerName,
+			"currencies", strings.Join(currencies, ","))
+	}
+
 	// Split records into today (live) and historical (completed)
 	today := c.clock.Now().Format("2006-01-02")
 
@@ -397,18 +602,30 @@ func (c *CostCollector) refresh(ctx context.Context) {
 		}
 	}
 
-	c.lastRecords = todayRecords
+	p.lastRecords = todayRecords
+
+	// Providers querying usage separately return it apart from the cost records
+	p.lastUsage = todayRecords
+	if result.Usage != nil {
+		p.lastUsage = nil
+		for _, record := range result.Usage {
+			if record.Date == today {
+				p.lastUsage = append(p.lastUsage, record)
+			}
+		}
+	}
 
 	// Update completed day records only once per day when day changes
 	// This ensures we export all historical data, not just yesterday
-	if c.lastCompletedDay != today && len(historicalRecords) > 0 {
-		c.completedDayRecords = historicalRecords
-		c.lastCompletedDay = today
+	if p.lastCompletedDay != today && len(historicalRecords) > 0 {
+		p.completedDayRecords = historicalRecords
+		p.lastCompletedDay = today
 		c.logger.Info("Updated completed day data",
+			"provider", providerName,
 			"record_count", len(historicalRecords))
 	}
 
-	c.isReady = true
+	p.isReady = true
 	c.logger.Info("Successfully refreshed cost records",
 		"provider", providerName,
 		"today_records", len(todayRecords),
@@ -416,30 +633,71 @@ func (c *CostCollector) refresh(ctx context.Context) {
 		"duration_seconds", duration.Seconds())
 }
 
-// IsReady returns true if the collector has successfully fetched data at least once
+// recordCurrencies returns the distinct currencies of the records in sorted order
+func recordCurrencies(records []provider.CostRecord) []string {
+	seen := make(map[string]bool)
+	var currencies []string
+	for _, record := range records {
+		if !seen[record.Currency] {
+			seen[record.Currency] = true
+			currencies = append(currencies, record.Currency)
+		}
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// IsReady returns true if at least one provider has successfully fetched data
+// and its last refresh succeeded
+// Providers that are failing are reported through up and LastError
 func (c *CostCollector) IsReady() bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.isReady
+	for _, p := range c.providers {
+		if p.isReady {
+			return true
+		}
+	}
+	return false
 }
 
-// LastError returns the last error encountered during refresh
+// LastError returns the errors of the last refresh of every failed provider
+// With several providers each error is prefixed with its provider name
 func (c *CostCollector) LastError() error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.lastError
+	if len(c.providers) == 1 {
+		return c.providers[0].lastError
+	}
+	var errs []error
+	for _, p := range c.providers {
+		if p.lastError != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.name(), p.lastError))
+		}
+	}
+	return errors.Join(errs...)
 }
 
-// LastScrapeTime returns the time of the last scrape attempt
+// LastScrapeTime returns the time of the most recent scrape attempt of any provider
 func (c *CostCollector) LastScrapeTime() time.Time {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.lastScrape
+	var last time.Time
+	for _, p := range c.providers {
+		if p.lastScrape.After(last) {
+			last = p.lastScrape
+		}
+	}
+	return last
 }
 
-// RecordCount returns the number of cost records currently cached
+// RecordCount returns the number of cost records currently cached for today
 func (c *CostCollector) RecordCount() int {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return len(c.lastRecords)
+	count := 0
+	for _, p := range c.providers {
+		count += len(p.lastRecords)
+	}
+	return count
 }
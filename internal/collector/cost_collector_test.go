@@ -3,11 +3,14 @@ package collector
 import (
 	"context"
 	"errors"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/zgpcy/azure-cost-exporter/internal/config"
 	"github.com/zgpcy/azure-cost-exporter/internal/logger"
 	"github.com/zgpcy/azure-cost-exporter/internal/provider"
@@ -22,14 +25,16 @@ func testLogger() *logger.Logger {
 type mockCloudProvider struct {
 	mu            sync.Mutex
 	records       []provider.CostRecord
+	usage         []provider.CostRecord // Usage queried apart from records (nil: usage is in records)
 	err           error
 	queryCalls    int
 	queryDuration time.Duration
 	providerType  provider.ProviderType
 	accountCount  int
+	accounts      []provider.AccountResult
 }
 
-func (m *mockCloudProvider) QueryCosts(ctx context.Context) ([]provider.CostRecord, error) {
+func (m *mockCloudProvider) QueryCosts(ctx context.Context) (provider.QueryResult, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -42,10 +47,10 @@ func (m *mockCloudProvider) QueryCosts(ctx context.Context) ([]provider.CostReco
 
 	// Check context cancellation
 	if ctx.Err() != nil {
-		return nil, ctx.Err()
+		return provider.QueryResult{}, ctx.Err()
 	}
 
-	return m.records, m.err
+	return provider.QueryResult{Records: m.records, Usage: m.usage, Accounts: m.accounts}, m.err
 }
 
 func (m *mockCloudProvider) Name() provider.ProviderType {
@@ -68,6 +73,21 @@ func (m *mockCloudProvider) SetRecords(records []provider.CostRecord) {
 	m.records = records
 }
 
+// fakeClock is a settable clock for testing
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func (m *mockCloudProvider) SetAccounts(accounts []provider.AccountResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.accounts = accounts
+}
+
 func (m *mockCloudProvider) SetError(err error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -86,7 +106,7 @@ func TestNewCostCollector(t *testing.T) {
 	if collector == nil {
 		t.Fatal("NewCostCollector returned nil")
 	}
-	if collector.cloudProvider == nil {
+	if len(collector.providers) != 1 || collector.providers[0].cloudProvider == nil {
 		t.Error("cloudProvider should not be nil")
 	}
 	if collector.cfg == nil {
@@ -117,9 +137,10 @@ func TestDescribe(t *testing.T) {
 		descs = append(descs, desc)
 	}
 
-	// Should have: costMetric, completedDailyCostMetric, upMetric, scrapeDurationMetric, scrapeErrorsTotal, lastScrapeTimeMetric, recordCountMetric, buildInfo
-	if len(descs) != 8 {
-		t.Errorf("Expected 8 descriptors, got %d", len(descs))
+	// Should have: costMetric, completedDailyCostMetric, upMetric, scrapeDurationMetric, scrapeErrorsTotal, lastScrapeTimeMetric, recordCountMetric,
+	// 5 per-account metrics (up, duration, retries, rows, last success), buildInfo
+	if len(descs) != 13 {
+		t.Errorf("Expected 13 descriptors, got %d", len(descs))
 	}
 }
 
@@ -273,6 +294,100 @@ func TestCollect_WithError(t *testing.T) {
 	}
 }
 
+// reasonError is an error carrying a failure category
+type reasonError struct{ reason string }
+
+func (e *reasonError) Error() string  { return e.reason + " failure" }
+func (e *reasonError) Reason() string { return e.reason }
+
+// TestRefresh_ScrapeErrorReason tests that scrape errors are counted by failure reason
+func TestRefresh_ScrapeErrorReason(t *testing.T) {
+	mockClient := &mockCloudProvider{providerType: provider.ProviderAzure}
+	collector := NewCostCollector(mockClient, &config.Config{RefreshInterval: 3600}, testLogger())
+
+	mockClient.err = &reasonError{reason: provider.ReasonThrottled}
+	collector.refresh(context.Background())
+	collector.refresh(context.Background())
+
+	mockClient.err = errors.New("unclassified")
+	collector.refresh(context.Background())
+
+	if got := testutil.ToFloat64(collector.scrapeErrorsTotal.WithLabelValues("azure", provider.ReasonThrottled)); got != 2 {
+		t.Errorf("throttled scrape errors = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(collector.scrapeErrorsTotal.WithLabelValues("azure", provider.ReasonUnknown)); got != 1 {
+		t.Errorf("unknown scrape errors = %v, want 1", got)
+	}
+}
+
+// TestCollect_AccountMetrics tests per-account health metrics across refreshes
+func TestCollect_AccountMetrics(t *testing.T) {
+	mockClient := &mockCloudProvider{
+		providerType: provider.ProviderAzure,
+		records: []provider.CostRecord{
+			{Date: time.Now().Format("2006-01-02"), Provider: "azure", AccountName: "prod", AccountID: "sub-1", Service: "Storage", Cost: 10},
+		},
+		accounts: []provider.AccountResult{
+			{AccountID: "sub-1", AccountName: "prod", Duration: 1500 * time.Millisecond, Rows: 1},
+			{AccountID: "sub-2", AccountName: "dev", Duration: 2 * time.Second, Retries: 3, Err: errors.New("forbidden")},
+		},
+	}
+	collector := NewCostCollector(mockClient, &config.Config{RefreshInterval: 3600}, testLogger())
+	firstRefresh := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	collector.clock = &fakeClock{now: firstRefresh}
+	collector.refresh(context.Background())
+
+	expected := `
+# HELP cloud_cost_exporter_account_up Was the last cost query for the account successful (1 = success, 0 = failure)
+# TYPE cloud_cost_exporter_account_up gauge
+cloud_cost_exporter_account_up{account_id="sub-1",account_name="prod",provider="azure"} 1
+cloud_cost_exporter_account_up{account_id="sub-2",account_name="dev",provider="azure"} 0
+# HELP cloud_cost_exporter_account_query_duration_seconds Duration of the last cost query for the account in seconds, including retries
+# TYPE cloud_cost_exporter_account_query_duration_seconds gauge
+cloud_cost_exporter_account_query_duration_seconds{account_id="sub-1",account_name="prod",provider="azure"} 1.5
+cloud_cost_exporter_account_query_duration_seconds{account_id="sub-2",account_name="dev",provider="azure"} 2
+# HELP cloud_cost_exporter_account_query_retries Number of API calls retried during the last cost query for the account
+# TYPE cloud_cost_exporter_account_query_retries gauge
+cloud_cost_exporter_account_query_retries{account_id="sub-1",account_name="prod",provider="azure"} 0
+cloud_cost_exporter_account_query_retries{account_id="sub-2",account_name="dev",provider="azure"} 3
+# HELP cloud_cost_exporter_account_rows Number of cost records returned by the last cost query for the account
+# TYPE cloud_cost_exporter_account_rows gauge
+cloud_cost_exporter_account_rows{account_id="sub-1",account_name="prod",provider="azure"} 1
+cloud_cost_exporter_account_rows{account_id="sub-2",account_name="dev",provider="azure"} 0
+# HELP cloud_cost_exporter_account_last_success_timestamp_seconds Unix timestamp of the last successful cost query for the account
+# TYPE cloud_cost_exporter_account_last_success_timestamp_seconds gauge
+cloud_cost_exporter_account_last_success_timestamp_seconds{account_id="sub-1",account_name="prod",provider="azure"} 1.7684712e+09
+`
+	names := []string{
+		"cloud_cost_exporter_account_up",
+		"cloud_cost_exporter_account_query_duration_seconds",
+		"cloud_cost_exporter_account_query_retries",
+		"cloud_cost_exporter_account_rows",
+		"cloud_cost_exporter_account_last_success_timestamp_seconds",
+	}
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected), names...); err != nil {
+		t.Errorf("Unexpected account metrics:\n%v", err)
+	}
+
+	// Next refresh: sub-1 fails and keeps its last success time, sub-2 recovers
+	mockClient.SetAccounts([]provider.AccountResult{
+		{AccountID: "sub-1", AccountName: "prod", Err: errors.New("timeout")},
+		{AccountID: "sub-2", AccountName: "dev", Rows: 4},
+	})
+	collector.clock = &fakeClock{now: firstRefresh.Add(time.Hour)}
+	collector.refresh(context.Background())
+
+	expected = `
+# HELP cloud_cost_exporter_account_last_success_timestamp_seconds Unix timestamp of the last successful cost query for the account
+# TYPE cloud_cost_exporter_account_last_success_timestamp_seconds gauge
+cloud_cost_exporter_account_last_success_timestamp_seconds{account_id="sub-1",account_name="prod",provider="azure"} 1.7684712e+09
+cloud_cost_exporter_account_last_success_timestamp_seconds{account_id="sub-2",account_name="dev",provider="azure"} 1.7684748e+09
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "cloud_cost_exporter_account_last_success_timestamp_seconds"); err != nil {
+		t.Errorf("Unexpected last success metrics:\n%v", err)
+	}
+}
+
 // TestRefresh tests the refresh method
 func TestRefresh(t *testing.T) {
 	mockClient := &mockCloudProvider{
@@ -849,3 +964,546 @@ func TestMetricLabels(t *testing.T) {
 		t.Error("Cost metric (cloud_cost_daily) not found")
 	}
 }
+
+// TestMetricLabels_TagGrouping tests that TagKey groupings are exported as sanitized labels
+func TestMetricLabels_TagGrouping(t *testing.T) {
+	cfg := &config.Config{
+		RefreshInterval: 3600,
+		GroupBy: config.GroupByConfig{
+			Enabled: true,
+			Groups: []config.GroupBy{
+				{Type: config.GroupTypeTagKey, Name: "cost-center"},
+				{Type: config.GroupTypeTagKey, Name: "env", LabelName: "environment"},
+			},
+		},
+	}
+
+	labels := buildMetricLabels(cfg)
+	names := labelNames(labels)
+	want := []string{"provider", "account_name", "account_id", "service", "tag_cost_center", "environment", "currency"}
+	if len(names) != len(want) {
+		t.Fatalf("Labels: got %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("Label %d: got %q, want %q", i, names[i], want[i])
+		}
+	}
+
+	record := provider.CostRecord{
+		Provider: "azure",
+		Service:  "Storage",
+		Tags:     map[string]string{"cost-center": "cc-42", "env": "prod"},
+		Currency: "€",
+	}
+	values := extractLabelValues(record, labels)
+	if values[4] != "cc-42" {
+		t.Errorf("tag_cost_center value: got %q, want %q", values[4], "cc-42")
+	}
+	if values[5] != "prod" {
+		t.Errorf("environment value: got %q, want %q", values[5], "prod")
+	}
+}
+
+// TestMetricLabels_DimensionRegistry tests that dimension groupings fill their labels
+// regardless of the configured label name
+func TestMetricLabels_DimensionRegistry(t *testing.T) {
+	cfg := &config.Config{
+		RefreshInterval: 3600,
+		GroupBy: config.GroupByConfig{
+			Enabled: true,
+			Groups: []config.GroupBy{
+				{Type: config.GroupTypeDimension, Name: "ServiceName", LabelName: "ServiceName"},
+				{Type: config.GroupTypeDimension, Name: "ResourceGroup", LabelName: "ResourceGroup"},
+				{Type: config.GroupTypeDimension, Name: "MeterCategory"},
+			},
+		},
+	}
+
+	labels := buildMetricLabels(cfg)
+	record := provider.CostRecord{
+		Provider:      "azure",
+		Service:       "Storage",
+		ResourceGroup: "prod-rg",
+		MeterCategory: "Blob Storage",
+		Currency:      "€",
+	}
+
+	names := labelNames(labels)
+	values := extractLabelValues(record, labels)
+	want := map[string]string{
+		"ServiceName":    "Storage",
+		"ResourceGroup":  "prod-rg",
+		"meter_category": "Blob Storage",
+	}
+	for i, name := range names {
+		if expected, ok := want[name]; ok && values[i] != expected {
+			t.Errorf("Label %s: got %q, want %q", name, values[i], expected)
+		}
+	}
+	if names[6] != "meter_category" {
+		t.Errorf("Default label name: got %q, want meter_category", names[6])
+	}
+}
+
+// TestMetricLabels_DerivedDimension tests that the resource name is exported by the ResourceName
+// dimension and by ResourceId groupings labelled resource_name
+func TestMetricLabels_DerivedDimension(t *testing.T) {
+	record := provider.CostRecord{
+		ResourceID:   "/subscriptions/sub/resourcegroups/prod-rg/providers/microsoft.compute/virtualmachines/vm-1",
+		ResourceName: "vm-1",
+	}
+
+	for _, group := range []config.GroupBy{
+		{Type: config.GroupTypeDimension, Name: "ResourceName"},
+		{Type: config.GroupTypeDimension, Name: "ResourceId", LabelName: "resource_name"},
+	} {
+		label := groupLabel(group)
+		if label.name != "resource_name" || label.value(record) != "vm-1" {
+			t.Errorf("%s: label %s = %q, want resource_name = vm-1", group.Name, label.name, label.value(record))
+		}
+	}
+
+	if label := groupLabel(config.GroupBy{Type: config.GroupTypeDimension, Name: "ResourceId"}); label.value(record) != record.ResourceID {
+		t.Errorf("ResourceId: value %q, want the resource ID", label.value(record))
+	}
+}
+
+// TestCollect_CostTypeBoth tests that actual and amortized costs are exported as separate series
+func TestCollect_CostTypeBoth(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	mockClient := &mockCloudProvider{
+		providerType: provider.ProviderAzure,
+		records: []provider.CostRecord{
+			{Date: today, Provider: "azure", AccountName: "sub", AccountID: "sub-1", Service: "Compute", Cost: 100.0, Currency: "€", CostType: config.CostTypeActual},
+			{Date: today, Provider: "azure", AccountName: "sub", AccountID: "sub-1", Service: "Compute", Cost: 3.3, Currency: "€", CostType: config.CostTypeAmortized},
+		},
+	}
+
+	cfg := &config.Config{RefreshInterval: 3600, CostType: config.CostTypeBoth}
+	collector := NewCostCollector(mockClient, cfg, testLogger())
+	collector.refresh(context.Background())
+
+	names := labelNames(collector.costMetricLabels)
+	if names[len(names)-2] != "cost_type" {
+		t.Errorf("Expected cost_type label before currency, got %v", names)
+	}
+
+	ch := make(chan prometheus.Metric, 20)
+	go func() {
+		collector.Collect(ch)
+		close(ch)
+	}()
+
+	costSeries := 0
+	for metric := range ch {
+		if metric.Desc().String() == collector.costMetric.String() {
+			costSeries++
+		}
+	}
+	if costSeries != 2 {
+		t.Errorf("Expected 2 cloud_cost_daily series (actual + amortized), got %d", costSeries)
+	}
+}
+
+// TestCollect_MultiTenant tests that records of different tenants are exported with a tenant_id label
+func TestCollect_MultiTenant(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	mockClient := &mockCloudProvider{
+		providerType: provider.ProviderAzure,
+		records: []provider.CostRecord{
+			{Date: today, Provider: "azure", AccountName: "prod", AccountID: "sub-1", Service: "Compute", Cost: 10.0, Currency: "€", TenantID: "home"},
+			{Date: today, Provider: "azure", AccountName: "prod", AccountID: "sub-1", Service: "Compute", Cost: 20.0, Currency: "€", TenantID: "customer"},
+		},
+	}
+
+	cfg := &config.Config{RefreshInterval: 3600, Tenants: []config.Tenant{{ID: "customer"}}}
+	collector := NewCostCollector(mockClient, cfg, testLogger())
+	collector.refresh(context.Background())
+
+	names := labelNames(collector.costMetricLabels)
+	if names[len(names)-2] != "tenant_id" {
+		t.Errorf("Expected tenant_id label before currency, got %v", names)
+	}
+
+	ch := make(chan prometheus.Metric, 20)
+	go func() {
+		collector.Collect(ch)
+		close(ch)
+	}()
+
+	costSeries := 0
+	for metric := range ch {
+		if metric.Desc().String() == collector.costMetric.String() {
+			costSeries++
+		}
+	}
+	if costSeries != 2 {
+		t.Errorf("Expected 2 cloud_cost_daily series (one per tenant), got %d", costSeries)
+	}
+}
+
+// mockForecaster is a cloud provider that also implements provider.Forecaster
+type mockForecaster struct {
+	mockCloudProvider
+	forecasts   []provider.ForecastRecord
+	forecastErr error
+}
+
+func (m *mockForecaster) QueryForecast(context.Context) (provider.ForecastResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return provider.ForecastResult{Records: m.forecasts}, m.forecastErr
+}
+
+// TestCollect_Forecast tests that the forecast is summed over the remaining days
+// of the month per confidence bound and kept when a refresh fails
+func TestCollect_Forecast(t *testing.T) {
+	forecast := func(date, bound string, cost float64) provider.ForecastRecord {
+		return provider.ForecastRecord{
+			CostRecord: provider.CostRecord{Date: date, Provider: "azure", AccountName: "prod", AccountID: "sub-1", Cost: cost, Currency: "€"},
+			Bound:      bound,
+		}
+	}
+	mock := &mockForecaster{
+		mockCloudProvider: mockCloudProvider{providerType: provider.ProviderAzure},
+		forecasts: []provider.ForecastRecord{
+			forecast("2026-01-30", provider.ForecastBoundExpected, 10),
+			forecast("2026-01-31", provider.ForecastBoundExpected, 20),
+			forecast("2026-01-31", provider.ForecastBoundUpper, 25),
+		},
+	}
+
+	cfg := &config.Config{RefreshInterval: 3600, Forecast: config.ForecastConfig{Enabled: true, RefreshInterval: 3600}}
+	collector := NewCostCollector(mock, cfg, testLogger())
+	clk := &fakeClock{now: time.Date(2026, 1, 30, 12, 0, 0, 0, time.UTC)}
+	collector.clock = clk
+	collector.refreshForecast(context.Background())
+
+	want := `
+# HELP cloud_cost_forecast Forecasted cloud cost from today until the end of the current billing month, by confidence bound. Not grouped: group_by labels are always empty
+# TYPE cloud_cost_forecast gauge
+cloud_cost_forecast{account_id="sub-1",account_name="prod",bound="expected",currency="€",provider="azure",service=""} 30
+cloud_cost_forecast{account_id="sub-1",account_name="prod",bound="upper",currency="€",provider="azure",service=""} 25
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want), "cloud_cost_forecast"); err != nil {
+		t.Errorf("Unexpected forecast metrics: %v", err)
+	}
+
+	// Failed refreshes keep the previous forecast; passed days drop out
+	mock.forecastErr = errors.New("forecast failed")
+	collector.refreshForecast(context.Background())
+	clk.now = clk.now.AddDate(0, 0, 1)
+
+	want = `
+# HELP cloud_cost_forecast Forecasted cloud cost from today until the end of the current billing month, by confidence bound. Not grouped: group_by labels are always empty
+# TYPE cloud_cost_forecast gauge
+cloud_cost_forecast{account_id="sub-1",account_name="prod",bound="expected",currency="€",provider="azure",service=""} 20
+cloud_cost_forecast{account_id="sub-1",account_name="prod",bound="upper",currency="€",provider="azure",service=""} 25
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want), "cloud_cost_forecast"); err != nil {
+		t.Errorf("Unexpected forecast metrics after failed refresh: %v", err)
+	}
+
+	// Forecast failures are counted apart from cost scrape failures
+	if got := testutil.ToFloat64(collector.forecast.errorsTotal.WithLabelValues("azure", provider.ReasonUnknown)); got != 1 {
+		t.Errorf("cloud_cost_exporter_forecast_errors_total = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(collector.scrapeErrorsTotal); got != 0 {
+		t.Errorf("cloud_cost_exporter_scrape_errors_total has %d series, want none", got)
+	}
+}
+
+// TestDescribe_ForecastDisabled tests that providers with forecast support only
+// export the forecast when it is enabled
+func TestDescribe_ForecastDisabled(t *testing.T) {
+	mock := &mockForecaster{mockCloudProvider: mockCloudProvider{providerType: provider.ProviderAzure}}
+	collector := NewCostCollector(mock, &config.Config{RefreshInterval: 3600}, testLogger())
+	if collector.forecast != nil {
+		t.Error("Forecast metric created although forecasts are disabled")
+	}
+}
+
+// TestCollect_Usage tests usage quantities and unit prices aggregated per meter and unit
+func TestCollect_Usage(t *testing.T) {
+	usage := func(service, meter, unit string, quantity, cost float64) provider.CostRecord {
+		return provider.CostRecord{
+			Date: "2026-01-30", Provider: "azure", AccountName: "prod", AccountID: "sub-1", Service: service,
+			Meter: meter, UnitOfMeasure: unit, UsageQuantity: quantity, Cost: cost, Currency: "€",
+		}
+	}
+	mock := &mockCloudProvider{
+		providerType: provider.ProviderAzure,
+		records: []provider.CostRecord{
+			usage("Virtual Machines", "D4s v5", "1 Hour", 20, 8),
+			usage("Virtual Machines", "D4s v5", "1 Hour", 5, 2),
+			usage("Storage", "LRS Data Stored", "1 GB/Month", 10, 0.2),
+			usage("Reservations", "", "", 0, 100),
+		},
+	}
+
+	cfg := &config.Config{RefreshInterval: 3600, Usage: config.UsageConfig{Enabled: true}}
+	collector := NewCostCollector(mock, cfg, testLogger())
+	collector.clock = &fakeClock{now: time.Date(2026, 1, 30, 12, 0, 0, 0, time.UTC)}
+	collector.refresh(context.Background())
+
+	want := `
+# HELP cloud_usage_quantity_daily Current day's consumed quantity per meter in unit_of_measure (live updates). Resets at midnight.
+# TYPE cloud_usage_quantity_daily gauge
+cloud_usage_quantity_daily{account_id="sub-1",account_name="prod",currency="€",meter="",provider="azure",service="Reservations",unit_of_measure=""} 0
+cloud_usage_quantity_daily{account_id="sub-1",account_name="prod",currency="€",meter="D4s v5",provider="azure",service="Virtual Machines",unit_of_measure="1 Hour"} 25
+cloud_usage_quantity_daily{account_id="sub-1",account_name="prod",currency="€",meter="LRS Data Stored",provider="azure",service="Storage",unit_of_measure="1 GB/Month"} 10
+# HELP cloud_usage_unit_price Effective price per unit_of_measure of the current day's usage (cost divided by usage quantity)
+# TYPE cloud_usage_unit_price gauge
+cloud_usage_unit_price{account_id="sub-1",account_name="prod",currency="€",meter="D4s v5",provider="azure",service="Virtual Machines",unit_of_measure="1 Hour"} 0.4
+cloud_usage_unit_price{account_id="sub-1",account_name="prod",currency="€",meter="LRS Data Stored",provider="azure",service="Storage",unit_of_measure="1 GB/Month"} 0.02
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want), "cloud_usage_quantity_daily", "cloud_usage_unit_price"); err != nil {
+		t.Errorf("Unexpected usage metrics: %v", err)
+	}
+
+	if disabled := NewCostCollector(mock, &config.Config{RefreshInterval: 3600}, testLogger()); disabled.usage != nil {
+		t.Error("Usage metrics created although usage is disabled")
+	}
+}
+
+// TestCollect_SeparateUsage tests that usage returned apart from the cost records feeds the usage
+// metrics only, while the cost metrics are built from the cost records
+func TestCollect_SeparateUsage(t *testing.T) {
+	record := provider.CostRecord{
+		Date: "2026-01-30", Provider: "azure", AccountName: "prod", AccountID: "sub-1", Service: "Virtual Machines", Cost: 10, Currency: "€",
+	}
+	usage := record
+	usage.Meter, usage.UnitOfMeasure, usage.UsageQuantity, usage.Cost = "D4s v5", "1 Hour", 25, 10
+	yesterday := usage
+	yesterday.Date = "2026-01-29"
+
+	mock := &mockCloudProvider{
+		providerType: provider.ProviderAzure,
+		records:      []provider.CostRecord{record},
+		usage:        []provider.CostRecord{usage, yesterday},
+	}
+
+	cfg := &config.Config{RefreshInterval: 3600, Usage: config.UsageConfig{Enabled: true}}
+	collector := NewCostCollector(mock, cfg, testLogger())
+	collector.clock = &fakeClock{now: time.Date(2026, 1, 30, 12, 0, 0, 0, time.UTC)}
+	collector.refresh(context.Background())
+
+	want := `
+# HELP cloud_cost_daily Current day's cloud cost (live updates). Resets at midnight. For historical data, use cloud_cost_completed_daily.
+# TYPE cloud_cost_daily gauge
+cloud_cost_daily{account_id="sub-1",account_name="prod",currency="€",provider="azure",service="Virtual Machines"} 10
+# HELP cloud_usage_quantity_daily Current day's consumed quantity per meter in unit_of_measure (live updates). Resets at midnight.
+# TYPE cloud_usage_quantity_daily gauge
+cloud_usage_quantity_daily{account_id="sub-1",account_name="prod",currency="€",meter="D4s v5",provider="azure",service="Virtual Machines",unit_of_measure="1 Hour"} 25
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want), "cloud_cost_daily", "cloud_usage_quantity_daily"); err != nil {
+		t.Errorf("Unexpected metrics: %v", err)
+	}
+}
+
+// TestRecordCurrencies tests detection of cost records in several currencies
+func TestRecordCurrencies(t *testing.T) {
+	records := []provider.CostRecord{{Currency: "USD"}, {Currency: "EUR"}, {Currency: "USD"}}
+	if got := strings.Join(recordCurrencies(records), ","); got != "EUR,USD" {
+		t.Errorf("recordCurrencies() = %q, want EUR,USD", got)
+	}
+	if got := recordCurrencies(records[:1]); len(got) != 1 {
+		t.Errorf("recordCurrencies() = %v, want a single currency", got)
+	}
+}
+
+// mockMonthReader is a cloud provider that also reports month totals
+type mockMonthReader struct {
+	mockCloudProvider
+	month    provider.MonthCostResult
+	monthErr error
+}
+
+func (m *mockMonthReader) QueryMonthCosts(context.Context) (provider.MonthCostResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.month, m.monthErr
+}
+
+// TestCollect_MonthTotals tests that month totals are summed per label set and kept when a refresh fails
+func TestCollect_MonthTotals(t *testing.T) {
+	total := func(service string, cost float64) provider.CostRecord {
+		return provider.CostRecord{Provider: "azure", AccountName: "prod", AccountID: "sub-1", Service: service, Cost: cost, Currency: "EUR"}
+	}
+	mock := &mockMonthReader{
+		mockCloudProvider: mockCloudProvider{providerType: provider.ProviderAzure},
+		month: provider.MonthCostResult{
+			MonthToDate:   []provider.CostRecord{total("Storage", 10), total("Storage", 5), total("Compute", 30)},
+			PreviousMonth: []provider.CostRecord{total("Storage", 40)},
+		},
+	}
+
+	cfg := &config.Config{RefreshInterval: 3600, MonthTotals: config.MonthTotalsConfig{Enabled: true, RefreshInterval: 3600}}
+	collector := NewCostCollector(mock, cfg, testLogger())
+	collector.refreshMonth(context.Background())
+
+	want := `
+# HELP cloud_cost_month_to_date Cloud cost from the first of the current month until today, as reported by the provider
+# TYPE cloud_cost_month_to_date gauge
+cloud_cost_month_to_date{account_id="sub-1",account_name="prod",currency="EUR",provider="azure",service="Compute"} 30
+cloud_cost_month_to_date{account_id="sub-1",account_name="prod",currency="EUR",provider="azure",service="Storage"} 15
+# HELP cloud_cost_previous_month_total Total cloud cost of the previous calendar month, as reported by the provider
+# TYPE cloud_cost_previous_month_total gauge
+cloud_cost_previous_month_total{account_id="sub-1",account_name="prod",currency="EUR",provider="azure",service="Storage"} 40
+`
+	metrics := []string{"cloud_cost_month_to_date", "cloud_cost_previous_month_total"}
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want), metrics...); err != nil {
+		t.Errorf("Unexpected month total metrics: %v", err)
+	}
+
+	// Failed refreshes keep the previous totals
+	mock.monthErr = errors.New("query failed")
+	mock.month = provider.MonthCostResult{}
+	collector.refreshMonth(context.Background())
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want), metrics...); err != nil {
+		t.Errorf("Month totals not kept after failed refresh: %v", err)
+	}
+
+	// Month total failures are counted apart from cost scrape failures
+	if got := testutil.ToFloat64(collector.month.errorsTotal.WithLabelValues("azure", provider.ReasonUnknown)); got != 1 {
+		t.Errorf("cloud_cost_exporter_month_errors_total = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(collector.scrapeErrorsTotal); got != 0 {
+		t.Errorf("cloud_cost_exporter_scrape_errors_total has %d series, want none", got)
+	}
+
+	if disabled := NewCostCollector(mock, &config.Config{RefreshInterval: 3600}, testLogger()); disabled.month != nil {
+		t.Error("Month total metrics created although month totals are disabled")
+	}
+}
+
+// TestCollect_MultipleProviders tests that the costs of several providers share the metric families
+// and that every provider reports its own health
+func TestCollect_MultipleProviders(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	azureMock := &mockCloudProvider{
+		providerType: provider.ProviderAzure,
+		records: []provider.CostRecord{
+			{Date: today, Provider: "azure", AccountName: "prod", AccountID: "sub-1", Service: "Storage", Cost: 10, Currency: "EUR"},
+		},
+	}
+	awsMock := &mockCloudProvider{
+		providerType: provider.ProviderAWS,
+		records: []provider.CostRecord{
+			{Date: today, Provider: "aws", AccountName: "shop", AccountID: "123456789012", Service: "Amazon S3", Cost: 4, Currency: "USD"},
+		},
+	}
+
+	collector := NewMultiCostCollector([]ProviderSchedule{
+		{Provider: azureMock, RefreshInterval: time.Hour},
+		{Provider: awsMock, RefreshInterval: 6 * time.Hour},
+	}, &config.Config{RefreshInterval: 3600}, testLogger())
+	collector.refresh(context.Background())
+
+	if !collector.IsReady() || collector.RecordCount() != 2 || collector.LastError() != nil {
+		t.Errorf("State = ready %v, records %d, error %v", collector.IsReady(), collector.RecordCount(), collector.LastError())
+	}
+
+	want := `
+# HELP cloud_cost_daily Current day's cloud cost (live updates). Resets at midnight. For historical data, use cloud_cost_completed_daily.
+# TYPE cloud_cost_daily gauge
+cloud_cost_daily{account_id="123456789012",account_name="shop",currency="USD",provider="aws",service="Amazon S3"} 4
+cloud_cost_daily{account_id="sub-1",account_name="prod",currency="EUR",provider="azure",service="Storage"} 10
+# HELP up Was the last cloud cost query successful (1 = success, 0 = failure)
+# TYPE up gauge
+up{provider="aws"} 1
+up{provider="azure"} 1
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want), "cloud_cost_daily", "up"); err != nil {
+		t.Errorf("Unexpected metrics: %v", err)
+	}
+
+	// A failing provider keeps the other provider's costs and is reported on its own
+	awsMock.mu.Lock()
+	awsMock.err = errors.New("throttled")
+	awsMock.mu.Unlock()
+	collector.refresh(context.Background())
+
+	if !collector.IsReady() {
+		t.Error("IsReady() = false although the azure provider succeeded")
+	}
+	if err := collector.LastError(); err == nil || !strings.Contains(err.Error(), "aws: throttled") {
+		t.Errorf("LastError() = %v, want error of the aws provider", err)
+	}
+
+	want = `
+# HELP up Was the last cloud cost query successful (1 = success, 0 = failure)
+# TYPE up gauge
+up{provider="aws"} 0
+up{provider="azure"} 1
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want), "up"); err != nil {
+		t.Errorf("Unexpected up metrics: %v", err)
+	}
+
+	// Without any provider succeeding the exporter is no longer ready
+	azureMock.mu.Lock()
+	azureMock.err = errors.New("forbidden")
+	azureMock.mu.Unlock()
+	collector.refresh(context.Background())
+
+	if collector.IsReady() {
+		t.Error("IsReady() = true although every provider failed")
+	}
+}
+
+// TestCollect_AWSGroupings tests that the groupings of an AWS provider are exported as labels
+// of the cost metrics next to the groupBy configuration
+func TestCollect_AWSGroupings(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	awsMock := &mockCloudProvider{
+		providerType: provider.ProviderAWS,
+		records: []provider.CostRecord{
+			{Date: today, Provider: "aws", AccountName: "shop", AccountID: "123456789012", Service: "Amazon S3", Tags: map[string]string{"team": "checkout"}, Cost: 4, Currency: "USD"},
+			{Date: today, Provider: "aws", AccountName: "shop", AccountID: "123456789012", Service: "Amazon S3", Tags: map[string]string{"team": "search"}, Cost: 2, Currency: "USD"},
+		},
+	}
+	cfg := &config.Config{
+		RefreshInterval: 3600,
+		Providers: []config.ProviderConfig{
+			{Type: provider.ProviderAWS, AWS: &config.AWSConfig{GroupBy: []string{config.AWSDimensionService, "tag:team"}}},
+		},
+	}
+
+	collector := NewMultiCostCollector([]ProviderSchedule{{Provider: awsMock, RefreshInterval: time.Hour}}, cfg, testLogger())
+	collector.refresh(context.Background())
+
+	want := `
+# HELP cloud_cost_daily Current day's cloud cost (live updates). Resets at midnight. For historical data, use cloud_cost_completed_daily.
+# TYPE cloud_cost_daily gauge
+cloud_cost_daily{account_id="123456789012",account_name="shop",currency="USD",provider="aws",service="Amazon S3",tag_team="checkout"} 4
+cloud_cost_daily{account_id="123456789012",account_name="shop",currency="USD",provider="aws",service="Amazon S3",tag_team="search"} 2
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want), "cloud_cost_daily"); err != nil {
+		t.Errorf("Unexpected metrics: %v", err)
+	}
+}
+
+// TestMetricLabels_AWSGroupings tests the labels of the AWS dimensions and that a tag grouped
+// by both the groupBy configuration and an AWS provider is exported once
+func TestMetricLabels_AWSGroupings(t *testing.T) {
+	cfg := &config.Config{
+		RefreshInterval: 3600,
+		GroupBy: config.GroupByConfig{
+			Enabled: true,
+			Groups:  []config.GroupBy{{Type: config.GroupTypeTagKey, Name: "team"}},
+		},
+		Providers: []config.ProviderConfig{
+			{Type: provider.ProviderAzure},
+			{Type: provider.ProviderAWS, AWS: &config.AWSConfig{GroupBy: []string{"tag:team", config.AWSDimensionUsageType}}},
+			{Type: provider.ProviderAWS, AWS: &config.AWSConfig{GroupBy: []string{config.AWSDimensionRegion, config.AWSDimensionLinkedAccount}}},
+		},
+	}
+
+	names := labelNames(buildMetricLabels(cfg))
+	want := []string{"provider", "account_name", "account_id", "service", "tag_team", "usage_type", "resource_location", "currency"}
+	if !slices.Equal(names, want) {
+		t.Errorf("Labels: got %v, want %v", names, want)
+	}
+}
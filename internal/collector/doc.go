@@ -8,9 +8,11 @@
 //   - cloud_cost_daily: Daily cloud cost with comprehensive dimensions
 //   - cloud_cost_exporter_up: Health status (1 = success, 0 = failure) with provider label
 //   - cloud_cost_exporter_scrape_duration_seconds: Duration of the last scrape with provider label
-//   - cloud_cost_exporter_scrape_errors_total: Total number of scrape errors with provider label
+//   - cloud_cost_exporter_scrape_errors_total: Total number of scrape errors with provider and reason labels
 //   - cloud_cost_exporter_last_scrape_timestamp_seconds: Unix timestamp of last successful scrape with provider label
 //   - cloud_cost_exporter_records_count: Number of cost records currently cached with provider label
+//   - cloud_cost_exporter_account_*: Per-account success, query duration, retries, rows and
+//     last success timestamp with provider, account_name and account_id labels
 //
 // The main type is CostCollector, which:
 //   - Fetches cost data from any cloud provider in the background at configurable intervals
@@ -0,0 +1,139 @@
+package collector
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// forecastMetric holds the forecast descriptor
+// The forecasts are cached per provider
+type forecastMetric struct {
+	desc        *prometheus.Desc
+	errorsTotal *prometheus.CounterVec // Kept apart from the cost scrape errors
+	labels      []metricLabel          // Cost metric labels; the bound label follows them
+}
+
+// newForecastMetric creates the forecast metric if forecasts are enabled and supported by any provider
+// Returns nil otherwise
+func newForecastMetric(providers []*providerState, metricLabels []metricLabel) *forecastMetric {
+	if !slices.ContainsFunc(providers, func(p *providerState) bool { return p.forecaster != nil }) {
+		return nil
+	}
+
+	return &forecastMetric{
+		desc: prometheus.NewDesc(
+			"cloud_cost_forecast",
+			"Forecasted cloud cost from today until the end of the current billing month, by confidence bound. Not grouped: group_by labels are always empty",
+			append(labelNames(metricLabels), "bound"),
+			nil,
+		),
+		errorsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "cloud_cost_exporter_forecast_errors_total",
+				Help: "Total number of failed forecast refreshes since startup, by failure reason",
+			},
+			[]string{"provider", "reason"},
+		),
+		labels: metricLabels,
+	}
+}
+
+// describe sends the forecast descriptors
+func (f *forecastMetric) describe(ch chan<- *prometheus.Desc) {
+	ch <- f.desc
+	f.errorsTotal.Describe(ch)
+}
+
+// collect exports the forecasts of all providers aggregated over the remaining days of the month
+// Days that have passed since the last forecast refresh are skipped
+func (f *forecastMetric) collect(ch chan<- prometheus.Metric, providers []*providerState, today string) {
+	type aggregate struct {
+		labelValues []string
+		cost        float64
+	}
+	forecasts := make(map[string]aggregate)
+
+	var records []provider.ForecastRecord
+	for _, p := range providers {
+		records = append(records, p.forecastRecords...)
+	}
+
+	for _, record := range records {
+		if record.Date < today {
+			continue
+		}
+		labelValues := append(extractLabelValues(record.CostRecord, f.labels), record.Bound)
+		key := strings.Join(labelValues, "|")
+
+		existing := forecasts[key]
+		existing.labelValues = labelValues
+		existing.cost += record.Cost
+		forecasts[key] = existing
+	}
+
+	for _, data := range forecasts {
+		ch <- prometheus.MustNewConstMetric(f.desc, prometheus.GaugeValue, data.cost, data.labelValues...)
+	}
+	f.errorsTotal.Collect(ch)
+}
+
+// startForecastRefresh refreshes the forecast now and then on its own schedule until ctx is done
+func (c *CostCollector) startForecastRefresh(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(c.cfg.Forecast.RefreshInterval) * time.Second)
+	go func() {
+		defer ticker.Stop()
+		c.refreshForecast(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.refreshForecast(ctx)
+			}
+		}
+	}()
+}
+
+// refreshForecast queries the forecast of every supporting provider and updates the cached forecasts
+func (c *CostCollector) refreshForecast(ctx context.Context) {
+	for _, p := range c.providers {
+		if p.forecaster != nil {
+			c.refreshProviderForecast(ctx, p)
+		}
+	}
+}
+
+// refreshProviderForecast queries the forecast of a provider and updates its cached forecast
+// On failure the previous forecast is kept
+func (c *CostCollector) refreshProviderForecast(ctx context.Context, p *providerState) {
+	providerName := p.name()
+	start := time.Now()
+
+	result, err := p.forecaster.QueryForecast(ctx)
+	records := result.Records
+	if len(records) > MaxRecordsToCache {
+		records = records[:MaxRecordsToCache]
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err != nil {
+		reason := provider.ErrorReason(err)
+		c.forecast.errorsTotal.With(prometheus.Labels{"provider": string(providerName), "reason": reason}).Inc()
+		c.logger.Error("Failed to refresh cost forecast, keeping previous forecast",
+			"provider", providerName, "reason", reason, "error", err)
+		return
+	}
+
+	p.forecastRecords = records
+	c.logger.Info("Successfully refreshed cost forecast",
+		"provider", providerName,
+		"forecast_records", len(records),
+		"duration_seconds", time.Since(start).Seconds())
+}
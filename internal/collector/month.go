@@ -0,0 +1,154 @@
+package collector
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// monthMetric holds the month total descriptors
+// The totals are cached per provider
+type monthMetric struct {
+	monthToDate   *prometheus.Desc
+	previousMonth *prometheus.Desc
+	errorsTotal   *prometheus.CounterVec // Kept apart from the cost scrape errors
+	labels        []metricLabel          // Cost metric labels
+}
+
+// newMonthMetric creates the month total metrics if they are enabled and supported by any provider
+// Returns nil otherwise
+func newMonthMetric(providers []*providerState, metricLabels []metricLabel) *monthMetric {
+	if !slices.ContainsFunc(providers, func(p *providerState) bool { return p.monthReader != nil }) {
+		return nil
+	}
+
+	return &monthMetric{
+		monthToDate: prometheus.NewDesc(
+			"cloud_cost_month_to_date",
+			"Cloud cost from the first of the current month until today, as reported by the provider",
+			labelNames(metricLabels),
+			nil,
+		),
+		previousMonth: prometheus.NewDesc(
+			"cloud_cost_previous_month_total",
+			"Total cloud cost of the previous calendar month, as reported by the provider",
+			labelNames(metricLabels),
+			nil,
+		),
+		errorsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "cloud_cost_exporter_month_errors_total",
+				Help: "Total number of failed month total refreshes since startup, by failure reason",
+			},
+			[]string{"provider", "reason"},
+		),
+		labels: metricLabels,
+	}
+}
+
+// describe sends the month total descriptors
+func (m *monthMetric) describe(ch chan<- *prometheus.Desc) {
+	ch <- m.monthToDate
+	ch <- m.previousMonth
+	m.errorsTotal.Describe(ch)
+}
+
+// collect exports both month totals of all providers aggregated per label set
+func (m *monthMetric) collect(ch chan<- prometheus.Metric, providers []*providerState) {
+	var monthToDate, previousMonth []provider.CostRecord
+	for _, p := range providers {
+		monthToDate = append(monthToDate, p.monthToDateRecords...)
+		previousMonth = append(previousMonth, p.previousMonthRecords...)
+	}
+	m.collectTotals(ch, m.monthToDate, monthToDate)
+	m.collectTotals(ch, m.previousMonth, previousMonth)
+	m.errorsTotal.Collect(ch)
+}
+
+// collectTotals exports the records summed per label set as the given metric
+func (m *monthMetric) collectTotals(ch chan<- prometheus.Metric, desc *prometheus.Desc, records []provider.CostRecord) {
+	type aggregate struct {
+		labelValues []string
+		cost        float64
+	}
+	totals := make(map[string]aggregate)
+
+	for _, record := range records {
+		labelValues := extractLabelValues(record, m.labels)
+		key := strings.Join(labelValues, "|")
+
+		existing := totals[key]
+		existing.labelValues = labelValues
+		existing.cost += record.Cost
+		totals[key] = existing
+	}
+
+	for _, data := range totals {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, data.cost, data.labelValues...)
+	}
+}
+
+// startMonthRefresh refreshes the month totals now and then on their own schedule until ctx is done
+func (c *CostCollector) startMonthRefresh(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(c.cfg.MonthTotals.RefreshInterval) * time.Second)
+	go func() {
+		defer ticker.Stop()
+		c.refreshMonth(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.refreshMonth(ctx)
+			}
+		}
+	}()
+}
+
+// refreshMonth queries the month totals of every supporting provider and updates the cached totals
+func (c *CostCollector) refreshMonth(ctx context.Context) {
+	for _, p := range c.providers {
+		if p.monthReader != nil {
+			c.refreshProviderMonth(ctx, p)
+		}
+	}
+}
+
+// refreshProviderMonth queries the month totals of a provider and updates its cached totals
+// On failure the previous totals are kept
+func (c *CostCollector) refreshProviderMonth(ctx context.Context, p *providerState) {
+	providerName := p.name()
+	start := time.Now()
+
+	result, err := p.monthReader.QueryMonthCosts(ctx)
+	monthToDate, previousMonth := result.MonthToDate, result.PreviousMonth
+	if len(monthToDate) > MaxRecordsToCache {
+		monthToDate = monthToDate[:MaxRecordsToCache]
+	}
+	if len(previousMonth) > MaxRecordsToCache {
+		previousMonth = previousMonth[:MaxRecordsToCache]
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err != nil {
+		reason := provider.ErrorReason(err)
+		c.month.errorsTotal.With(prometheus.Labels{"provider": string(providerName), "reason": reason}).Inc()
+		c.logger.Error("Failed to refresh month cost totals, keeping previous totals",
+			"provider", providerName, "reason", reason, "error", err)
+		return
+	}
+
+	p.monthToDateRecords = monthToDate
+	p.previousMonthRecords = previousMonth
+	c.logger.Info("Successfully refreshed month cost totals",
+		"provider", providerName,
+		"month_to_date_records", len(monthToDate),
+		"previous_month_records", len(previousMonth),
+		"duration_seconds", time.Since(start).Seconds())
+}
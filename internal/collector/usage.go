@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// Usage labels following the cost metric labels
+var (
+	meterLabel         = metricLabel{name: "meter", value: func(r provider.CostRecord) string { return r.Meter }}
+	unitOfMeasureLabel = metricLabel{name: "unit_of_measure", value: func(r provider.CostRecord) string { return r.UnitOfMeasure }}
+)
+
+// usageMetric holds the usage quantity and unit price descriptors
+// Both are derived from today's cost records, like cloud_cost_daily
+type usageMetric struct {
+	quantity  *prometheus.Desc
+	unitPrice *prometheus.Desc
+	labels    []metricLabel // Cost metric labels followed by meter and unit_of_measure
+}
+
+// newUsageMetric creates the usage metrics if they are enabled
+// Returns nil otherwise
+func newUsageMetric(cfg *config.Config, metricLabels []metricLabel) *usageMetric {
+	if !cfg.Usage.Enabled {
+		return nil
+	}
+
+	labels := append(append([]metricLabel{}, metricLabels...), meterLabel, unitOfMeasureLabel)
+	return &usageMetric{
+		quantity: prometheus.NewDesc(
+			"cloud_usage_quantity_daily",
+			"Current day's consumed quantity per meter in unit_of_measure (live updates). Resets at midnight.",
+			labelNames(labels),
+			nil,
+		),
+		unitPrice: prometheus.NewDesc(
+			"cloud_usage_unit_price",
+			"Effective price per unit_of_measure of the current day's usage (cost divided by usage quantity)",
+			labelNames(labels),
+			nil,
+		),
+		labels: labels,
+	}
+}
+
+// describe sends the usage descriptors
+func (u *usageMetric) describe(ch chan<- *prometheus.Desc) {
+	ch <- u.quantity
+	ch <- u.unitPrice
+}
+
+// collect exports usage quantities and effective unit prices aggregated per label set
+// The unit price is omitted for label sets without usage (e.g. purchases)
+func (u *usageMetric) collect(ch chan<- prometheus.Metric, records []provider.CostRecord) {
+	type aggregate struct {
+		labelValues []string
+		quantity    float64
+		cost        float64
+	}
+	usage := make(map[string]aggregate)
+
+	for _, record := range records {
+		labelValues := extractLabelValues(record, u.labels)
+		key := strings.Join(labelValues, "|")
+
+		existing := usage[key]
+		existing.labelValues = labelValues
+		existing.quantity += record.UsageQuantity
+		existing.cost += record.Cost
+		usage[key] = existing
+	}
+
+	for _, data := range usage {
+		ch <- prometheus.MustNewConstMetric(u.quantity, prometheus.GaugeValue, data.quantity, data.labelValues...)
+		if data.quantity > 0 {
+			ch <- prometheus.MustNewConstMetric(u.unitPrice, prometheus.GaugeValue, data.cost/data.quantity, data.labelValues...)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/logger"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// utilizationLabels are the labels of all commitment utilization metrics
+var utilizationLabels = []string{"provider", "account_name", "account_id", "kind", "order_id", "sku", "date"}
+
+// UtilizationCollector implements prometheus.Collector for reservation and savings plan utilization
+// Utilization is refreshed on its own schedule, independently of the cost data
+type UtilizationCollector struct {
+	reader providerUtilizationReader
+	cfg    *config.Config
+	logger *logger.Logger
+
+	// Metrics
+	utilization *prometheus.Desc
+	usedHours   *prometheus.Desc
+	unusedHours *prometheus.Desc
+	unusedCost  *prometheus.Desc
+	errorsTotal *prometheus.CounterVec
+
+	// State
+	mu          sync.RWMutex
+	commitments []provider.CommitmentUtilization
+}
+
+// providerUtilizationReader is a cloud provider that can report commitment utilization
+type providerUtilizationReader interface {
+	provider.CloudProvider
+	provider.UtilizationReader
+}
+
+// NewUtilizationCollector creates a UtilizationCollector if the provider supports commitment utilization
+// Returns nil if it doesn't
+func NewUtilizationCollector(cloudProvider provider.CloudProvider, cfg *config.Config, log *logger.Logger) *UtilizationCollector {
+	reader, ok := cloudProvider.(providerUtilizationReader)
+	if !ok {
+		return nil
+	}
+
+	return &UtilizationCollector{
+		reader: reader,
+		cfg:    cfg,
+		logger: log,
+		utilization: prometheus.NewDesc(
+			"cloud_commitment_utilization_percent",
+			"Daily utilization of reservations and savings plans in percent, per order and SKU",
+			utilizationLabels, nil,
+		),
+		usedHours: prometheus.NewDesc(
+			"cloud_commitment_used_hours",
+			"Daily reserved hours applied to usage, per reservation order and SKU",
+			utilizationLabels, nil,
+		),
+		unusedHours: prometheus.NewDesc(
+			"cloud_commitment_unused_hours",
+			"Daily reserved hours not applied to any usage, per reservation order and SKU",
+			utilizationLabels, nil,
+		),
+		unusedCost: prometheus.NewDesc(
+			"cloud_commitment_unused_cost",
+			"Daily cost of the unused part of reservations and savings plans, per order and SKU",
+			append(append([]string{}, utilizationLabels...), "currency"), nil,
+		),
+		errorsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "cloud_cost_exporter_utilization_errors_total",
+				Help: "Total number of failed commitment utilization refreshes since startup, by failure reason",
+			},
+			[]string{"provider", "reason"},
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *UtilizationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.utilization
+	ch <- c.usedHours
+	ch <- c.unusedHours
+	ch <- c.unusedCost
+	c.errorsTotal.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *UtilizationCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, u := range c.commitments {
+		labels := []string{u.Provider, u.AccountName, u.AccountID, u.Kind, u.OrderID, u.SKU, u.Date}
+
+		ch <- prometheus.MustNewConstMetric(c.utilization, prometheus.GaugeValue, u.UtilizationPercent, labels...)
+		if u.ReservedHours != nil && u.UsedHours != nil {
+			ch <- prometheus.MustNewConstMetric(c.usedHours, prometheus.GaugeValue, *u.UsedHours, labels...)
+			ch <- prometheus.MustNewConstMetric(c.unusedHours, prometheus.GaugeValue, max(*u.ReservedHours-*u.UsedHours, 0), labels...)
+		}
+		ch <- prometheus.MustNewConstMetric(c.unusedCost, prometheus.GaugeValue, u.UnusedCost, append(labels, u.Currency)...)
+	}
+
+	c.errorsTotal.Collect(ch)
+}
+
+// StartBackgroundRefresh refreshes the utilization now and then periodically until ctx is done
+func (c *UtilizationCollector) StartBackgroundRefresh(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(c.cfg.Utilization.RefreshInterval) * time.Second)
+	go func() {
+		defer ticker.Stop()
+		c.refresh(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.refresh(ctx)
+			}
+		}
+	}()
+}
+
+// refresh queries the commitment utilization and updates the cached utilization
+// On failure the previous utilization is kept
+func (c *UtilizationCollector) refresh(ctx context.Context) {
+	providerName := c.reader.Name()
+	start := time.Now()
+
+	result, err := c.reader.QueryUtilization(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err != nil {
+		reason := provider.ErrorReason(err)
+		c.errorsTotal.With(prometheus.Labels{"provider": string(providerName), "reason": reason}).Inc()
+		c.logger.Error("Failed to refresh commitment utilization, keeping previous utilization",
+			"provider", providerName, "reason", reason, "error", err)
+		return
+	}
+
+	c.commitments = result.Commitments
+	c.logger.Info("Successfully refreshed commitment utilization",
+		"provider", providerName,
+		"commitments", len(result.Commitments),
+		"duration_seconds", time.Since(start).Seconds())
+}
@@ -0,0 +1,87 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// mockUtilizationReader is a cloud provider that also reports commitment utilization
+type mockUtilizationReader struct {
+	mockCloudProvider
+	commitments    []provider.CommitmentUtilization
+	utilizationErr error
+}
+
+func (m *mockUtilizationReader) QueryUtilization(context.Context) (provider.UtilizationResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return provider.UtilizationResult{Commitments: m.commitments}, m.utilizationErr
+}
+
+// TestNewUtilizationCollector_Unsupported tests that providers without commitments get no collector
+func TestNewUtilizationCollector_Unsupported(t *testing.T) {
+	if c := NewUtilizationCollector(&mockCloudProvider{}, &config.Config{}, testLogger()); c != nil {
+		t.Error("Expected nil collector for a provider without utilization support")
+	}
+}
+
+// TestUtilizationCollector_Collect tests utilization metrics and that failed refreshes keep the previous utilization
+func TestUtilizationCollector_Collect(t *testing.T) {
+	reserved, used := 72.0, 60.0
+	mock := &mockUtilizationReader{
+		mockCloudProvider: mockCloudProvider{providerType: provider.ProviderAzure},
+		commitments: []provider.CommitmentUtilization{
+			{
+				Provider: "azure", AccountName: "contoso", AccountID: "12345678", Kind: provider.CommitmentKindReservation,
+				OrderID: "order-1", SKU: "Standard_D4s_v5", Date: "2026-01-14",
+				UtilizationPercent: 83.5, ReservedHours: &reserved, UsedHours: &used, UnusedCost: 4.8, Currency: "EUR",
+			},
+			{
+				Provider: "azure", AccountName: "contoso", AccountID: "12345678", Kind: provider.CommitmentKindSavingsPlan,
+				OrderID: "order-2", SKU: "Compute_Savings_Plan", Date: "2026-01-14",
+				UtilizationPercent: 92.5, UnusedCost: 1.25, Currency: "EUR",
+			},
+		},
+	}
+
+	cfg := &config.Config{Utilization: config.UtilizationConfig{Enabled: true, RefreshInterval: 3600, DaysToQuery: 1}}
+	collector := NewUtilizationCollector(mock, cfg, testLogger())
+	collector.refresh(context.Background())
+
+	want := `
+# HELP cloud_commitment_unused_cost Daily cost of the unused part of reservations and savings plans, per order and SKU
+# TYPE cloud_commitment_unused_cost gauge
+cloud_commitment_unused_cost{account_id="12345678",account_name="contoso",currency="EUR",date="2026-01-14",kind="reservation",order_id="order-1",provider="azure",sku="Standard_D4s_v5"} 4.8
+cloud_commitment_unused_cost{account_id="12345678",account_name="contoso",currency="EUR",date="2026-01-14",kind="savings_plan",order_id="order-2",provider="azure",sku="Compute_Savings_Plan"} 1.25
+# HELP cloud_commitment_unused_hours Daily reserved hours not applied to any usage, per reservation order and SKU
+# TYPE cloud_commitment_unused_hours gauge
+cloud_commitment_unused_hours{account_id="12345678",account_name="contoso",date="2026-01-14",kind="reservation",order_id="order-1",provider="azure",sku="Standard_D4s_v5"} 12
+# HELP cloud_commitment_used_hours Daily reserved hours applied to usage, per reservation order and SKU
+# TYPE cloud_commitment_used_hours gauge
+cloud_commitment_used_hours{account_id="12345678",account_name="contoso",date="2026-01-14",kind="reservation",order_id="order-1",provider="azure",sku="Standard_D4s_v5"} 60
+# HELP cloud_commitment_utilization_percent Daily utilization of reservations and savings plans in percent, per order and SKU
+# TYPE cloud_commitment_utilization_percent gauge
+cloud_commitment_utilization_percent{account_id="12345678",account_name="contoso",date="2026-01-14",kind="reservation",order_id="order-1",provider="azure",sku="Standard_D4s_v5"} 83.5
+cloud_commitment_utilization_percent{account_id="12345678",account_name="contoso",date="2026-01-14",kind="savings_plan",order_id="order-2",provider="azure",sku="Compute_Savings_Plan"} 92.5
+`
+	names := []string{"cloud_commitment_utilization_percent", "cloud_commitment_used_hours", "cloud_commitment_unused_hours", "cloud_commitment_unused_cost"}
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want), names...); err != nil {
+		t.Errorf("Unexpected utilization metrics: %v", err)
+	}
+
+	mock.utilizationErr = errors.New("utilization unavailable")
+	collector.refresh(context.Background())
+
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want), names...); err != nil {
+		t.Errorf("Utilization not kept after failed refresh: %v", err)
+	}
+	if got := testutil.ToFloat64(collector.errorsTotal.WithLabelValues("azure", provider.ReasonUnknown)); got != 1 {
+		t.Errorf("utilization_errors_total = %v, want 1", got)
+	}
+}
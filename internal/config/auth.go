@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Supported credential types
+const (
+	AuthTypeDefault           = "default"            // azidentity.DefaultAzureCredential
+	AuthTypeClientSecret      = "client_secret"      // Service principal with a client secret
+	AuthTypeClientCertificate = "client_certificate" // Service principal with a certificate
+	AuthTypeWorkloadIdentity  = "workload_identity"  // Kubernetes workload identity federation
+	AuthTypeManagedIdentity   = "managed_identity"   // System- or user-assigned managed identity
+	AuthTypeAzureCLI          = "azure_cli"          // Logged-in Azure CLI account
+	AuthTypeChained           = "chained"            // Try the credentials in Chain in order
+)
+
+// AuthConfig selects and configures the Azure credential
+type AuthConfig struct {
+	Type     string `yaml:"type"` // One of the AuthType* constants (default: default)
+	TenantID string `yaml:"tenant_id"`
+	ClientID string `yaml:"client_id"`
+
+	// client_secret
+	ClientSecret string `yaml:"client_secret"`
+
+	// client_certificate (PEM or PKCS#12 file)
+	CertificatePath     string `yaml:"certificate_path"`
+	CertificatePassword string `yaml:"certificate_password"`
+
+	// workload_identity (defaults to AZURE_FEDERATED_TOKEN_FILE)
+	TokenFilePath string `yaml:"token_file_path"`
+
+	// managed_identity: user-assigned identity by resource ID (alternative to client_id)
+	ResourceID string `yaml:"resource_id"`
+
+	// chained: credentials tried in order until one returns a token
+	Chain []AuthConfig `yaml:"chain"`
+}
+
+// authTypes lists all credential types in documentation order
+var authTypes = []string{
+	AuthTypeDefault,
+	AuthTypeClientSecret,
+	AuthTypeClientCertificate,
+	AuthTypeWorkloadIdentity,
+	AuthTypeManagedIdentity,
+	AuthTypeAzureCLI,
+	AuthTypeChained,
+}
+
+// applyAuthDefaults sets the default credential type, including on chain entries
+func applyAuthDefaults(auth *AuthConfig) {
+	if auth.Type == "" {
+		auth.Type = AuthTypeDefault
+	}
+	for i := range auth.Chain {
+		applyAuthDefaults(&auth.Chain[i])
+	}
+}
+
+// validateAuth validates the settings required by the selected credential type
+func validateAuth(auth AuthConfig) error {
+	switch auth.Type {
+	case "", AuthTypeDefault, AuthTypeAzureCLI:
+	case AuthTypeClientSecret:
+		if auth.TenantID == "" || auth.ClientID == "" || auth.ClientSecret == "" {
+			return fmt.Errorf("auth type %s requires tenant_id, client_id and client_secret", auth.Type)
+		}
+	case AuthTypeClientCertificate:
+		if auth.TenantID == "" || auth.ClientID == "" || auth.CertificatePath == "" {
+			return fmt.Errorf("auth type %s requires tenant_id, client_id and certificate_path", auth.Type)
+		}
+	case AuthTypeWorkloadIdentity:
+		// tenant_id, client_id and token_file_path fall back to the variables
+		// injected by the workload identity webhook
+	case AuthTypeManagedIdentity:
+		if auth.ClientID != "" && auth.ResourceID != "" {
+			return fmt.Errorf("auth type %s accepts either client_id or resource_id, not both", auth.Type)
+		}
+	case AuthTypeChained:
+		if len(auth.Chain) == 0 {
+			return fmt.Errorf("auth type %s requires at least one chain entry", auth.Type)
+		}
+		for i, entry := range auth.Chain {
+			if entry.Type == AuthTypeChained {
+				return fmt.Errorf("auth chain entry %d: chained credentials cannot be nested", i)
+			}
+			if err := validateAuth(entry); err != nil {
+				return fmt.Errorf("auth chain entry %d: %w", i, err)
+			}
+		}
+	default:
+		return fmt.Errorf("auth type must be one of %s, got %q", strings.Join(authTypes, ", "), auth.Type)
+	}
+
+	if auth.Type != AuthTypeChained && len(auth.Chain) > 0 {
+		return fmt.Errorf("auth chain is only valid for auth type %s", AuthTypeChained)
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// AWS Cost Explorer settings
+const (
+	DefaultAWSRegion = "us-east-1" // Cost Explorer is served from us-east-1 only
+
+	// MaxAWSGroupBy is the number of group definitions Cost Explorer accepts per query
+	MaxAWSGroupBy = 2
+
+	// AWSTagPrefix marks group_by entries that group by a cost allocation tag
+	AWSTagPrefix = "tag:"
+
+	// AWSUsageTypeLabel is the metric label of the USAGE_TYPE grouping
+	AWSUsageTypeLabel = "usage_type"
+)
+
+// Cost Explorer dimensions supported as AWS group_by entries
+const (
+	AWSDimensionLinkedAccount = "LINKED_ACCOUNT"
+	AWSDimensionService       = "SERVICE"
+	AWSDimensionRegion        = "REGION"
+	AWSDimensionUsageType     = "USAGE_TYPE"
+	AWSDimensionRecordType    = "RECORD_TYPE"
+	AWSDimensionPurchaseType  = "PURCHASE_TYPE"
+)
+
+// DefaultAWSGroupBy splits costs per member account and service
+var DefaultAWSGroupBy = []string{AWSDimensionLinkedAccount, AWSDimensionService}
+
+// awsDimensions lists the supported group_by dimensions in documentation order
+var awsDimensions = []string{
+	AWSDimensionLinkedAccount,
+	AWSDimensionService,
+	AWSDimensionRegion,
+	AWSDimensionUsageType,
+	AWSDimensionRecordType,
+	AWSDimensionPurchaseType,
+}
+
+// AWSConfig configures the AWS Cost Explorer provider
+// Credentials are resolved by the AWS SDK default chain (environment, shared
+// config, web identity, instance role), optionally assuming RoleARN
+type AWSConfig struct {
+	Region   string `yaml:"region"`   // Cost Explorer region (default: us-east-1)
+	Profile  string `yaml:"profile"`  // Shared config profile (default: AWS_PROFILE or default)
+	RoleARN  string `yaml:"role_arn"` // Role assumed for the queries (optional)
+	Endpoint string `yaml:"endpoint"` // Custom Cost Explorer endpoint, e.g. a VPC endpoint or proxy
+
+	// Account the queries run in (usually the management account); rows are
+	// attributed to it unless they are grouped by LINKED_ACCOUNT
+	AccountID   string `yaml:"account_id"`
+	AccountName string `yaml:"account_name"` // Friendly name (defaults to account_id)
+
+	CostType string   `yaml:"cost_type"` // actual (UnblendedCost), amortized (AmortizedCost) or both
+	GroupBy  []string `yaml:"group_by"`  // Up to two dimensions or tag:<key> entries
+}
+
+// TagKeys returns the cost allocation tags grouped by
+func (a AWSConfig) TagKeys() []string {
+	var keys []string
+	for _, g := range a.GroupBy {
+		if key, ok := strings.CutPrefix(g, AWSTagPrefix); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// CostTypes returns the individual cost types to query ("both" expands to actual and amortized)
+func (a AWSConfig) CostTypes() []string {
+	return expandCostType(a.CostType)
+}
+
+// applyAWSDefaults sets the default region, cost type, account name and grouping
+func applyAWSDefaults(a *AWSConfig) {
+	if a.Region == "" {
+		a.Region = DefaultAWSRegion
+	}
+	if a.CostType == "" {
+		a.CostType = DefaultCostType
+	}
+	if a.AccountName == "" {
+		a.AccountName = a.AccountID
+	}
+	if len(a.GroupBy) == 0 {
+		a.GroupBy = append([]string{}, DefaultAWSGroupBy...)
+	}
+}
+
+// validateAWS validates the AWS Cost Explorer settings
+func validateAWS(a AWSConfig) error {
+	switch a.CostType {
+	case CostTypeActual, CostTypeAmortized, CostTypeBoth:
+	default:
+		return fmt.Errorf("cost_type must be %s, %s or %s, got %q",
+			CostTypeActual, CostTypeAmortized, CostTypeBoth, a.CostType)
+	}
+
+	if len(a.GroupBy) > MaxAWSGroupBy {
+		return fmt.Errorf("group_by accepts at most %d entries (Cost Explorer limit), got %d", MaxAWSGroupBy, len(a.GroupBy))
+	}
+	seen := make(map[string]bool, len(a.GroupBy))
+	for _, g := range a.GroupBy {
+		if seen[g] {
+			return fmt.Errorf("duplicate group_by entry %q", g)
+		}
+		seen[g] = true
+
+		if key, ok := strings.CutPrefix(g, AWSTagPrefix); ok {
+			if key == "" {
+				return fmt.Errorf("group_by entry %q has an empty tag key", g)
+			}
+			continue
+		}
+		if !isAWSDimension(g) {
+			return fmt.Errorf("group_by entry must be one of %s or %s<key>, got %q",
+				strings.Join(awsDimensions, ", "), AWSTagPrefix, g)
+		}
+	}
+
+	if a.Endpoint != "" {
+		u, err := url.Parse(a.Endpoint)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("endpoint must be an http(s) URL, got %q", a.Endpoint)
+		}
+	}
+
+	return nil
+}
+
+// isAWSDimension reports whether name is a supported group_by dimension
+func isAWSDimension(name string) bool {
+	return slices.Contains(awsDimensions, name)
+}
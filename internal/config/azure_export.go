@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// Cost Management export types, as written to export manifests
+const (
+	AzureExportTypeActual    = "ActualCost"
+	AzureExportTypeAmortized = "AmortizedCost"
+)
+
+// AzureExportConfig makes the azure provider read Cost Management scheduled exports
+// instead of querying the Cost Management API
+// Exports are read from a local directory or a mounted blob container; group_by, date_range,
+// usage, cost_in_usd and currency apply, while the scope and filter are part of the export
+type AzureExportConfig struct {
+	Path string `yaml:"path"` // Export root directory (searched recursively) or glob pattern
+
+	// Cost types exported: actual (ActualCost exports), amortized (AmortizedCost exports) or both
+	// Exports without manifest carry no type; they are taken as ActualCost unless cost_type is amortized
+	CostType string `yaml:"cost_type"`
+}
+
+// CostTypes returns the individual cost types to export ("both" expands to actual and amortized)
+func (e AzureExportConfig) CostTypes() []string {
+	return expandCostType(e.CostType)
+}
+
+// applyAzureExportDefaults sets the default cost type
+func applyAzureExportDefaults(e *AzureExportConfig) {
+	if e.CostType == "" {
+		e.CostType = DefaultCostType
+	}
+}
+
+// validateAzureExport validates the scheduled export settings
+func validateAzureExport(e AzureExportConfig) error {
+	if e.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	if _, err := filepath.Match(e.Path, ""); err != nil {
+		return fmt.Errorf("invalid path pattern %q: %w", e.Path, err)
+	}
+
+	switch e.CostType {
+	case CostTypeActual, CostTypeAmortized, CostTypeBoth:
+	default:
+		return fmt.Errorf("cost_type must be %s, %s or %s, got %q",
+			CostTypeActual, CostTypeAmortized, CostTypeBoth, e.CostType)
+	}
+
+	return nil
+}
+
+// QueriesAzureAPI reports whether the azure provider queries the Cost Management API
+// Azure providers reading scheduled exports need no subscriptions or scopes
+func (c *Config) QueriesAzureAPI() bool {
+	if len(c.Providers) == 0 {
+		return true
+	}
+	for _, p := range c.Providers {
+		if p.Type == provider.ProviderAzure && p.Export == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAzureExportMode rejects Azure settings that are not applied to scheduled exports
+// Export files hold whatever scope and rows the export was defined with, so subscriptions,
+// scopes, discovery and filters must be set up on the export in Azure instead
+func validateAzureExportMode(cfg *Config) error {
+	if !cfg.HasProvider(provider.ProviderAzure) || cfg.QueriesAzureAPI() {
+		return nil
+	}
+
+	if cfg.Filter != nil {
+		return fmt.Errorf("filter is not applied to scheduled exports, filter the export in Azure instead")
+	}
+	if len(cfg.Subscriptions) > 0 || len(cfg.Scopes) > 0 || len(cfg.Tenants) > 0 || cfg.Discovery.Enabled {
+		return fmt.Errorf("subscriptions, scopes, tenants and discovery are not used with scheduled exports, set the scope on the export in Azure instead")
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Supported Azure clouds
+const (
+	CloudAzurePublic     = "AzurePublic"
+	CloudAzureChina      = "AzureChina"
+	CloudAzureGovernment = "AzureGovernment"
+	CloudCustom          = "Custom" // Private or air-gapped cloud with explicit endpoints
+)
+
+// DefaultCloud is the cloud used when none is configured
+const DefaultCloud = CloudAzurePublic
+
+// CloudConfig selects the Azure cloud used by the credential and the Cost Management client
+type CloudConfig struct {
+	Name string `yaml:"name"` // AzurePublic, AzureChina, AzureGovernment or Custom
+
+	// Custom cloud only
+	ResourceManagerEndpoint string `yaml:"resource_manager_endpoint"` // e.g. https://management.contoso.local
+	ResourceManagerAudience string `yaml:"resource_manager_audience"` // Token audience (defaults to the endpoint)
+	AuthorityHost           string `yaml:"authority_host"`            // e.g. https://login.contoso.local
+}
+
+// validateCloud validates the cloud selection and custom endpoints
+func validateCloud(c CloudConfig) error {
+	switch c.Name {
+	case "", CloudAzurePublic, CloudAzureChina, CloudAzureGovernment:
+		if c.ResourceManagerEndpoint != "" || c.AuthorityHost != "" || c.ResourceManagerAudience != "" {
+			return fmt.Errorf("cloud endpoints can only be set for cloud %s", CloudCustom)
+		}
+	case CloudCustom:
+		if c.ResourceManagerEndpoint == "" || c.AuthorityHost == "" {
+			return fmt.Errorf("cloud %s requires resource_manager_endpoint and authority_host", CloudCustom)
+		}
+		for name, value := range map[string]string{
+			"resource_manager_endpoint": c.ResourceManagerEndpoint,
+			"authority_host":            c.AuthorityHost,
+		} {
+			u, err := url.Parse(value)
+			if err != nil || u.Scheme != "https" || u.Host == "" {
+				return fmt.Errorf("cloud %s must be an https URL, got %q", name, value)
+			}
+		}
+	default:
+		return fmt.Errorf("cloud name must be %s, %s, %s or %s, got %q",
+			CloudAzurePublic, CloudAzureChina, CloudAzureGovernment, CloudCustom, c.Name)
+	}
+	return nil
+}
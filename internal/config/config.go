@@ -3,9 +3,12 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
 	"gopkg.in/yaml.v3"
 )
 
@@ -17,50 +20,235 @@ const (
 	MinDaysToQuery     = 1     // Minimum days to query
 
 	// Default values
-	DefaultCurrency        = "€"
-	DefaultEndDateOffset   = 0    // Query from today (two-metric model)
-	DefaultDaysToQuery     = 2    // Query today + yesterday (two-metric model)
-	DefaultRefreshInterval = 1800 // 30 minutes in seconds (user requested 30min for live data)
-	DefaultHTTPPort        = 8080
-	DefaultLogLevel        = "info"
-	DefaultAPITimeout      = 30 // API timeout in seconds
+	DefaultCurrency            = "€"
+	DefaultEndDateOffset       = 0    // Query from today (two-metric model)
+	DefaultDaysToQuery         = 2    // Query today + yesterday (two-metric model)
+	DefaultRefreshInterval     = 1800 // 30 minutes in seconds (user requested 30min for live data)
+	DefaultHTTPPort            = 8080
+	DefaultLogLevel            = "info"
+	DefaultAPITimeout          = 30 // API timeout in seconds
+	DefaultMaxQueryPages       = 20 // Maximum result pages fetched per cost query
+	DefaultMaxConcurrent       = 4  // Subscriptions queried in parallel
+	DefaultCostType            = CostTypeActual
+	DefaultDiscoveryInterval   = 3600  // Re-run subscription discovery hourly
+	DefaultForecastInterval    = 21600 // Forecasts change slowly, refresh every 6 hours
+	DefaultBudgetInterval      = 3600  // Budget spend is updated by Azure a few times a day
+	DefaultMonthTotalsInterval = 3600  // Month totals follow the daily cost data
+	DefaultUtilizationInterval = 21600 // Utilization summaries are computed once a day
+	DefaultUtilizationDays     = 3     // Completed days of utilization exported
+)
+
+// DefaultDiscoveryStates are the subscription states included by discovery (Disabled and Deleted are skipped)
+var DefaultDiscoveryStates = []string{"Enabled", "Warned", "PastDue"}
+
+// Supported cost types
+const (
+	CostTypeActual    = "actual"    // Purchases are billed on the purchase day
+	CostTypeAmortized = "amortized" // Reservation and savings plan purchases are spread over their usage
+	CostTypeBoth      = "both"      // Query both and distinguish them with a cost_type label
+)
+
+// labelNamePattern matches valid Prometheus label names
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// reservedLabels are always exported by the collector and cannot be used by group_by entries
+var reservedLabels = map[string]bool{
+	"provider":     true,
+	"account_name": true,
+	"account_id":   true,
+	"service":      true,
+	"currency":     true,
+	"date":         true,
+	"cost_type":    true,
+	"tenant_id":    true,
+	"bound":        true,
+	// Usage metric labels
+	"meter":           true,
+	"unit_of_measure": true,
+	// Label of the AWS USAGE_TYPE grouping
+	AWSUsageTypeLabel: true,
+}
+
+// Supported scope types
+const (
+	ScopeTypeSubscription      = "subscription"
+	ScopeTypeResourceGroup     = "resource_group"
+	ScopeTypeManagementGroup   = "management_group"
+	ScopeTypeBillingAccount    = "billing_account"
+	ScopeTypeBillingProfile    = "billing_profile"
+	ScopeTypeDepartment        = "department"
+	ScopeTypeEnrollmentAccount = "enrollment_account"
+)
+
+// Supported group_by types
+const (
+	GroupTypeDimension = "Dimension" // Built-in Cost Management dimension (ServiceName, ResourceGroup, ...)
+	GroupTypeTagKey    = "TagKey"    // Resource tag (team, env, cost-center, ...)
 )
 
 // Subscription represents an Azure subscription to monitor
 type Subscription struct {
-	ID   string `yaml:"id"`
-	Name string `yaml:"name"`
+	ID     string  `yaml:"id"`
+	Name   string  `yaml:"name"`
+	Filter *Filter `yaml:"filter"` // Overrides the top-level filter
+}
+
+// Scope represents a Cost Management query scope other than a plain subscription
+// Scopes above subscription level report costs per subscription they contain
+type Scope struct {
+	Type             string  `yaml:"type"`
+	ID               string  `yaml:"id"`                 // Scope identifier (management group ID, billing account ID, resource group name, ...)
+	Name             string  `yaml:"name"`               // Friendly name (defaults to ID)
+	SubscriptionID   string  `yaml:"subscription_id"`    // Required for resource_group scopes
+	BillingAccountID string  `yaml:"billing_account_id"` // Required for billing_profile, department and enrollment_account scopes
+	Filter           *Filter `yaml:"filter"`             // Overrides the top-level filter
+}
+
+// DiscoveryConfig configures automatic discovery of subscriptions visible to the credential
+type DiscoveryConfig struct {
+	Enabled  bool              `yaml:"enabled"`
+	Interval int               `yaml:"interval"` // Seconds between discovery runs
+	Include  []string          `yaml:"include"`  // Regexes matched against subscription name or ID (empty = all)
+	Exclude  []string          `yaml:"exclude"`  // Regexes matched against subscription name or ID
+	States   []string          `yaml:"states"`   // Subscription states to include
+	Tags     map[string]string `yaml:"tags"`     // Tags a subscription must carry (exact value match)
+}
+
+// ForecastConfig configures the cost forecast for the rest of the billing month
+// The Forecast API doesn't support grouping, so forecasts are per subscription or scope
+// and their group_by labels are empty
+type ForecastConfig struct {
+	Enabled         bool `yaml:"enabled"`
+	RefreshInterval int  `yaml:"refresh_interval"` // Seconds between forecast queries
+}
+
+// UsageConfig configures the usage quantity and unit price metrics
+// Enabling it adds a query of the current day per target, additionally grouped by meter and unit of measure
+type UsageConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// MonthTotalsConfig configures the month-to-date and previous month cost totals
+type MonthTotalsConfig struct {
+	Enabled         bool `yaml:"enabled"`
+	RefreshInterval int  `yaml:"refresh_interval"` // Seconds between month total queries
+}
+
+// UtilizationConfig configures the reservation and savings plan utilization metrics
+// Utilization is reported for the billing_account and billing_profile scopes
+type UtilizationConfig struct {
+	Enabled         bool `yaml:"enabled"`
+	RefreshInterval int  `yaml:"refresh_interval"` // Seconds between utilization queries
+	DaysToQuery     int  `yaml:"days_to_query"`    // Completed days exported, ending yesterday
+}
+
+// BudgetsConfig configures export of the budgets defined on the monitored scopes
+type BudgetsConfig struct {
+	Enabled         bool `yaml:"enabled"`
+	RefreshInterval int  `yaml:"refresh_interval"` // Seconds between budget queries
 }
 
 // GroupBy represents grouping configuration for cost queries
+// Type is either "Dimension" or "TagKey"; for tags, Name is the tag key
 type GroupBy struct {
 	Type      string `yaml:"type"`
 	Name      string `yaml:"name"`
 	LabelName string `yaml:"label_name"`
 }
 
+// MetricLabel returns the Prometheus label name this group is exported as
+// Dimensions default to their registered label, tags to "tag_<key>"
+func (g GroupBy) MetricLabel() string {
+	if g.LabelName != "" {
+		return provider.SanitizeLabelName(g.LabelName)
+	}
+	if g.Type == GroupTypeTagKey {
+		return provider.SanitizeLabelName("tag_" + g.Name)
+	}
+	if dim, ok := provider.LookupDimension(g.Name); ok {
+		return dim.Label
+	}
+	return provider.SanitizeLabelName(g.Name)
+}
+
 // GroupByConfig represents the grouping configuration
 type GroupByConfig struct {
 	Enabled bool      `yaml:"enabled"`
 	Groups  []GroupBy `yaml:"groups"`
 }
 
+// TagKeys returns the tag keys configured as TagKey groupings, or nil if grouping is disabled
+func (g GroupByConfig) TagKeys() []string {
+	if !g.Enabled {
+		return nil
+	}
+	var keys []string
+	for _, group := range g.Groups {
+		if group.Type == GroupTypeTagKey {
+			keys = append(keys, group.Name)
+		}
+	}
+	return keys
+}
+
 // DateRange represents the date range configuration
 type DateRange struct {
 	EndDateOffset *int `yaml:"end_date_offset"` // Pointer to distinguish between 0 and unset
 	DaysToQuery   int  `yaml:"days_to_query"`
 }
 
+// Days returns the first and last day of the range ending end_date_offset days before now
+// Both are midnight UTC
+func (d DateRange) Days(now time.Time) (time.Time, time.Time) {
+	endDateOffset := 0
+	if d.EndDateOffset != nil {
+		endDateOffset = *d.EndDateOffset
+	}
+	end := now.UTC().AddDate(0, 0, -endDateOffset)
+	start := end.AddDate(0, 0, -(d.DaysToQuery - 1))
+
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	return start, end
+}
+
+// DayWithin returns the date of the UTC day of t and whether that day lies
+// between start and end, the days returned by Days
+func DayWithin(t, start, end time.Time) (string, bool) {
+	day := t.UTC()
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	if day.Before(start) || day.After(end) {
+		return "", false
+	}
+	return day.Format(time.DateOnly), true
+}
+
 // Config represents the application configuration
 type Config struct {
-	Subscriptions   []Subscription `yaml:"subscriptions"`
-	Currency        string         `yaml:"currency"`
-	DateRange       DateRange      `yaml:"date_range"`
-	GroupBy         GroupByConfig  `yaml:"group_by"`
-	RefreshInterval int            `yaml:"refresh_interval"` // seconds
-	HTTPPort        int            `yaml:"http_port"`
-	LogLevel        string         `yaml:"log_level"`
-	APITimeout      int            `yaml:"api_timeout"` // Azure API timeout in seconds
+	Providers            []ProviderConfig  `yaml:"providers"` // Cost providers to run (default: azure only)
+	Subscriptions        []Subscription    `yaml:"subscriptions"`
+	Scopes               []Scope           `yaml:"scopes"`
+	Discovery            DiscoveryConfig   `yaml:"discovery"`
+	Auth                 AuthConfig        `yaml:"auth"`
+	Cloud                CloudConfig       `yaml:"cloud"`
+	Tenants              []Tenant          `yaml:"tenants"`     // Additional tenants with their own credentials
+	Currency             string            `yaml:"currency"`    // Fallback when a response carries no currency
+	CostInUSD            bool              `yaml:"cost_in_usd"` // Query CostUSD/PreTaxCostUSD for a single reporting currency
+	CostType             string            `yaml:"cost_type"`   // actual, amortized or both
+	DateRange            DateRange         `yaml:"date_range"`
+	GroupBy              GroupByConfig     `yaml:"group_by"`
+	Filter               *Filter           `yaml:"filter"` // Server-side query filter
+	Usage                UsageConfig       `yaml:"usage"`
+	MonthTotals          MonthTotalsConfig `yaml:"month_totals"`
+	Forecast             ForecastConfig    `yaml:"forecast"`
+	Budgets              BudgetsConfig     `yaml:"budgets"`
+	Utilization          UtilizationConfig `yaml:"utilization"`
+	RefreshInterval      int               `yaml:"refresh_interval"` // seconds
+	HTTPPort             int               `yaml:"http_port"`
+	LogLevel             string            `yaml:"log_level"`
+	APITimeout           int               `yaml:"api_timeout"`            // Azure API timeout in seconds, per request
+	MaxQueryPages        int               `yaml:"max_query_pages"`        // Maximum NextLink pages fetched per query
+	MaxConcurrentQueries int               `yaml:"max_concurrent_queries"` // Maximum subscriptions/scopes queried in parallel
 }
 
 // Load loads configuration from a YAML file and applies environment variable overrides
@@ -96,6 +284,10 @@ func Load(path string) (*Config, error) {
 
 // applyDefaults sets default values for configuration
 func applyDefaults(cfg *Config) {
+	applyAuthDefaults(&cfg.Auth)
+	if cfg.Cloud.Name == "" {
+		cfg.Cloud.Name = DefaultCloud
+	}
 	if cfg.Currency == "" {
 		cfg.Currency = DefaultCurrency
 	}
@@ -119,6 +311,34 @@ func applyDefaults(cfg *Config) {
 	if cfg.APITimeout == 0 {
 		cfg.APITimeout = DefaultAPITimeout
 	}
+	if cfg.MaxQueryPages == 0 {
+		cfg.MaxQueryPages = DefaultMaxQueryPages
+	}
+	if cfg.MaxConcurrentQueries == 0 {
+		cfg.MaxConcurrentQueries = DefaultMaxConcurrent
+	}
+	if cfg.CostType == "" {
+		cfg.CostType = DefaultCostType
+	}
+	if cfg.Forecast.RefreshInterval == 0 {
+		cfg.Forecast.RefreshInterval = DefaultForecastInterval
+	}
+	if cfg.Budgets.RefreshInterval == 0 {
+		cfg.Budgets.RefreshInterval = DefaultBudgetInterval
+	}
+	if cfg.MonthTotals.RefreshInterval == 0 {
+		cfg.MonthTotals.RefreshInterval = DefaultMonthTotalsInterval
+	}
+	if cfg.Utilization.RefreshInterval == 0 {
+		cfg.Utilization.RefreshInterval = DefaultUtilizationInterval
+	}
+	if cfg.Utilization.DaysToQuery == 0 {
+		cfg.Utilization.DaysToQuery = DefaultUtilizationDays
+	}
+	applyProviderDefaults(cfg)
+	applyDiscoveryDefaults(&cfg.Discovery)
+	applyScopeDefaults(cfg.Scopes)
+	applyTenantDefaults(cfg.Tenants)
 }
 
 // applyEnvOverrides applies environment variable overrides to configuration
@@ -128,6 +348,30 @@ func applyEnvOverrides(cfg *Config) error {
 		cfg.Currency = val
 	}
 
+	// Override USD reporting
+	if val := os.Getenv("AZURE_COST_IN_USD"); val != "" {
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid AZURE_COST_IN_USD: must be a boolean, got %q", val)
+		}
+		cfg.CostInUSD = b
+	}
+
+	// Override cost type
+	if val := os.Getenv("AZURE_COST_COST_TYPE"); val != "" {
+		cfg.CostType = val
+	}
+
+	// Override cloud
+	if val := os.Getenv("AZURE_COST_CLOUD"); val != "" {
+		cfg.Cloud.Name = val
+	}
+
+	// Override client secret (keeps the secret out of the config file)
+	if val := os.Getenv("AZURE_COST_AUTH_CLIENT_SECRET"); val != "" {
+		cfg.Auth.ClientSecret = val
+	}
+
 	// Override refresh interval
 	if val := os.Getenv("AZURE_COST_REFRESH_INTERVAL"); val != "" {
 		i, err := strconv.Atoi(val)
@@ -169,6 +413,15 @@ func applyEnvOverrides(cfg *Config) error {
 		cfg.DateRange.DaysToQuery = i
 	}
 
+	// Override query concurrency
+	if val := os.Getenv("AZURE_COST_MAX_CONCURRENT_QUERIES"); val != "" {
+		i, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid AZURE_COST_MAX_CONCURRENT_QUERIES: must be an integer, got %q", val)
+		}
+		cfg.MaxConcurrentQueries = i
+	}
+
 	// Override subscriptions (comma-separated id:name pairs)
 	// Example: AZURE_COST_SUBSCRIPTIONS="sub1:prod,sub2:dev"
 	if val := os.Getenv("AZURE_COST_SUBSCRIPTIONS"); val != "" {
@@ -198,18 +451,25 @@ func applyEnvOverrides(cfg *Config) error {
 
 // validate validates the configuration
 func validate(cfg *Config) error {
-	if len(cfg.Subscriptions) == 0 {
-		return fmt.Errorf("no subscriptions configured")
+	if err := validateProviders(cfg.Providers); err != nil {
+		return err
 	}
 
-	for i, sub := range cfg.Subscriptions {
-		if sub.ID == "" {
-			return fmt.Errorf("subscription at index %d has empty ID", i)
-		}
-		// Validate subscription name is not empty
-		if sub.Name == "" {
-			return fmt.Errorf("subscription at index %d has empty name", i)
-		}
+	if err := validateAzureExportMode(cfg); err != nil {
+		return err
+	}
+
+	// Azure settings are only required when the Azure provider queries the API
+	if cfg.QueriesAzureAPI() && len(cfg.Subscriptions) == 0 && len(cfg.Scopes) == 0 && !cfg.Discovery.Enabled && len(cfg.Tenants) == 0 {
+		return fmt.Errorf("no subscriptions or scopes configured and discovery is disabled")
+	}
+
+	if err := validateTargets(cfg.Subscriptions, cfg.Scopes, cfg.Discovery); err != nil {
+		return err
+	}
+
+	if err := validateTenants(cfg); err != nil {
+		return err
 	}
 
 	// Check for negative or zero refresh interval
@@ -250,5 +510,175 @@ func validate(cfg *Config) error {
 		return fmt.Errorf("api_timeout should not exceed 300 seconds (5 minutes), got %d", cfg.APITimeout)
 	}
 
+	switch cfg.CostType {
+	case CostTypeActual, CostTypeAmortized, CostTypeBoth:
+	default:
+		return fmt.Errorf("cost_type must be %s, %s or %s, got %q",
+			CostTypeActual, CostTypeAmortized, CostTypeBoth, cfg.CostType)
+	}
+
+	if cfg.MaxQueryPages < 0 {
+		return fmt.Errorf("max_query_pages cannot be negative, got %d", cfg.MaxQueryPages)
+	}
+
+	if cfg.MaxConcurrentQueries < 0 {
+		return fmt.Errorf("max_concurrent_queries cannot be negative, got %d", cfg.MaxConcurrentQueries)
+	}
+
+	if err := validateAuth(cfg.Auth); err != nil {
+		return err
+	}
+
+	if err := validateCloud(cfg.Cloud); err != nil {
+		return err
+	}
+
+	if cfg.GroupBy.Enabled {
+		if err := validateGroupBy(cfg.GroupBy.Groups); err != nil {
+			return err
+		}
+	}
+
+	if err := validateFilters(cfg); err != nil {
+		return err
+	}
+
+	if cfg.Forecast.Enabled && cfg.Forecast.RefreshInterval < MinRefreshInterval {
+		return fmt.Errorf("forecast refresh_interval must be at least %d seconds, got %d",
+			MinRefreshInterval, cfg.Forecast.RefreshInterval)
+	}
+
+	if cfg.MonthTotals.Enabled && cfg.MonthTotals.RefreshInterval < MinRefreshInterval {
+		return fmt.Errorf("month_totals refresh_interval must be at least %d seconds, got %d",
+			MinRefreshInterval, cfg.MonthTotals.RefreshInterval)
+	}
+
+	if cfg.Budgets.Enabled && cfg.Budgets.RefreshInterval < MinRefreshInterval {
+		return fmt.Errorf("budgets refresh_interval must be at least %d seconds, got %d",
+			MinRefreshInterval, cfg.Budgets.RefreshInterval)
+	}
+
+	if cfg.Utilization.Enabled {
+		if err := validateUtilization(cfg); err != nil {
+			return fmt.Errorf("invalid utilization configuration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateDiscovery validates the subscription discovery settings
+func validateDiscovery(d DiscoveryConfig) error {
+	if d.Interval < MinRefreshInterval {
+		return fmt.Errorf("interval must be at least %d seconds, got %d", MinRefreshInterval, d.Interval)
+	}
+	for _, pattern := range append(append([]string{}, d.Include...), d.Exclude...) {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid filter regex %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// validateScope validates a single query scope
+func validateScope(scope Scope) error {
+	if scope.ID == "" {
+		return fmt.Errorf("empty ID")
+	}
+
+	switch scope.Type {
+	case ScopeTypeSubscription, ScopeTypeManagementGroup, ScopeTypeBillingAccount:
+	case ScopeTypeResourceGroup:
+		if scope.SubscriptionID == "" {
+			return fmt.Errorf("resource_group scope %q requires subscription_id", scope.ID)
+		}
+	case ScopeTypeBillingProfile, ScopeTypeDepartment, ScopeTypeEnrollmentAccount:
+		if scope.BillingAccountID == "" {
+			return fmt.Errorf("%s scope %q requires billing_account_id", scope.Type, scope.ID)
+		}
+	default:
+		return fmt.Errorf("unsupported scope type %q", scope.Type)
+	}
+
+	return nil
+}
+
+// IsBillingScope reports whether the scope is a billing account or billing profile
+// Reservation and savings plan utilization is only reported for these scopes
+func (s Scope) IsBillingScope() bool {
+	return s.Type == ScopeTypeBillingAccount || s.Type == ScopeTypeBillingProfile
+}
+
+// validateUtilization validates the utilization settings
+// At least one tenant must have a billing scope to report utilization for
+func validateUtilization(cfg *Config) error {
+	if cfg.Utilization.RefreshInterval < MinRefreshInterval {
+		return fmt.Errorf("refresh_interval must be at least %d seconds, got %d",
+			MinRefreshInterval, cfg.Utilization.RefreshInterval)
+	}
+	if cfg.Utilization.DaysToQuery < MinDaysToQuery {
+		return fmt.Errorf("days_to_query must be at least %d, got %d", MinDaysToQuery, cfg.Utilization.DaysToQuery)
+	}
+
+	for _, t := range cfg.TenantList() {
+		for _, scope := range t.Scopes {
+			if scope.IsBillingScope() {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("requires at least one %s or %s scope", ScopeTypeBillingAccount, ScopeTypeBillingProfile)
+}
+
+// CostTypes returns the individual cost types to query ("both" expands to actual and amortized)
+func (c *Config) CostTypes() []string {
+	return expandCostType(c.CostType)
+}
+
+// expandCostType returns the individual cost types of a cost_type setting
+func expandCostType(costType string) []string {
+	switch costType {
+	case CostTypeBoth:
+		return []string{CostTypeActual, CostTypeAmortized}
+	case CostTypeAmortized:
+		return []string{CostTypeAmortized}
+	default:
+		return []string{CostTypeActual}
+	}
+}
+
+// validateGroupBy validates the group_by entries
+// Every dimension must be registered so that its label is actually populated
+func validateGroupBy(groups []GroupBy) error {
+	seen := make(map[string]string, len(groups))
+	for i, g := range groups {
+		if g.Name == "" {
+			return fmt.Errorf("group_by entry at index %d has empty name", i)
+		}
+		switch g.Type {
+		case GroupTypeDimension:
+			if _, ok := provider.LookupDimension(g.Name); !ok {
+				return fmt.Errorf("group_by dimension %q is not supported (supported: %s)",
+					g.Name, strings.Join(provider.Dimensions(), ", "))
+			}
+		case GroupTypeTagKey:
+		default:
+			return fmt.Errorf("group_by entry %q has unsupported type %q (must be %s or %s)",
+				g.Name, g.Type, GroupTypeDimension, GroupTypeTagKey)
+		}
+
+		if g.LabelName != "" && !labelNamePattern.MatchString(g.LabelName) {
+			return fmt.Errorf("group_by entry %q has invalid label_name %q", g.Name, g.LabelName)
+		}
+
+		label := g.MetricLabel()
+		if reservedLabels[label] {
+			return fmt.Errorf("group_by entry %q uses reserved label name %q", g.Name, label)
+		}
+		if other, ok := seen[label]; ok {
+			return fmt.Errorf("group_by entries %q and %q both use label name %q", other, g.Name, label)
+		}
+		seen[label] = g.Name
+	}
 	return nil
 }
@@ -3,7 +3,12 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
 )
 
 func TestLoad_ValidConfig_Success(t *testing.T) {
@@ -288,6 +293,893 @@ func TestValidate_DaysToQueryTooLow_Error(t *testing.T) {
 	}
 }
 
+func TestValidate_GroupByType(t *testing.T) {
+	tests := []struct {
+		name    string
+		group   GroupBy
+		wantErr bool
+	}{
+		{"dimension", GroupBy{Type: GroupTypeDimension, Name: "ServiceName", LabelName: "service_name"}, false},
+		{"tag key", GroupBy{Type: GroupTypeTagKey, Name: "cost-center"}, false},
+		{"dimension alias", GroupBy{Type: GroupTypeDimension, Name: "ResourceGroupName"}, false},
+		{"unknown type", GroupBy{Type: "Tag", Name: "team"}, true},
+		{"empty name", GroupBy{Type: GroupTypeTagKey, Name: ""}, true},
+		{"unmapped dimension", GroupBy{Type: GroupTypeDimension, Name: "InvoiceId", LabelName: "invoice"}, true},
+		{"reserved label", GroupBy{Type: GroupTypeDimension, Name: "ServiceName", LabelName: "service"}, true},
+		{"reserved usage label", GroupBy{Type: GroupTypeTagKey, Name: "meter", LabelName: "meter"}, true},
+		{"reserved aws usage type label", GroupBy{Type: GroupTypeDimension, Name: "MeterCategory", LabelName: "usage_type"}, true},
+		{"invalid label name", GroupBy{Type: GroupTypeDimension, Name: "ServiceName", LabelName: "service-name"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validTestConfig()
+			cfg.GroupBy = GroupByConfig{Enabled: true, Groups: []GroupBy{tt.group}}
+
+			err := validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_GroupByDuplicateLabel_Error(t *testing.T) {
+	cfg := validTestConfig()
+	cfg.GroupBy = GroupByConfig{
+		Enabled: true,
+		Groups: []GroupBy{
+			{Type: GroupTypeDimension, Name: "ResourceGroup"},
+			{Type: GroupTypeDimension, Name: "ResourceGroupName"},
+		},
+	}
+
+	err := validate(cfg)
+	if err == nil {
+		t.Error("validate() error = nil, want error for duplicate label names")
+	}
+}
+
+func TestValidate_CostType(t *testing.T) {
+	tests := []struct {
+		costType string
+		wantErr  bool
+	}{
+		{CostTypeActual, false},
+		{CostTypeAmortized, false},
+		{CostTypeBoth, false},
+		{"ActualCost", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.costType, func(t *testing.T) {
+			cfg := validTestConfig()
+			cfg.CostType = tt.costType
+
+			err := validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCostTypes(t *testing.T) {
+	cfg := &Config{CostType: CostTypeBoth}
+	types := cfg.CostTypes()
+	if len(types) != 2 || types[0] != CostTypeActual || types[1] != CostTypeAmortized {
+		t.Errorf("CostTypes() = %v, want [actual amortized]", types)
+	}
+
+	cfg.CostType = ""
+	if types := cfg.CostTypes(); len(types) != 1 || types[0] != CostTypeActual {
+		t.Errorf("CostTypes() with empty cost_type = %v, want [actual]", types)
+	}
+}
+
+func TestValidate_Scopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		scope   Scope
+		wantErr bool
+	}{
+		{"management group", Scope{Type: ScopeTypeManagementGroup, ID: "mg-1"}, false},
+		{"billing account", Scope{Type: ScopeTypeBillingAccount, ID: "1234567"}, false},
+		{"resource group", Scope{Type: ScopeTypeResourceGroup, ID: "rg", SubscriptionID: "sub"}, false},
+		{"department", Scope{Type: ScopeTypeDepartment, ID: "42", BillingAccountID: "1234567"}, false},
+		{"resource group without subscription", Scope{Type: ScopeTypeResourceGroup, ID: "rg"}, true},
+		{"enrollment account without billing account", Scope{Type: ScopeTypeEnrollmentAccount, ID: "99"}, true},
+		{"empty id", Scope{Type: ScopeTypeManagementGroup}, true},
+		{"unknown type", Scope{Type: "tenant", ID: "t"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validTestConfig()
+			cfg.Subscriptions = nil
+			cfg.Scopes = []Scope{tt.scope}
+
+			err := validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_ScopesOnly_Success(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+scopes:
+  - type: management_group
+    id: mg-platform
+  - type: billing_profile
+    id: BP-1
+    name: Europe
+    billing_account_id: ba-1
+`
+
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v, want nil", err)
+	}
+
+	if len(cfg.Scopes) != 2 {
+		t.Fatalf("Expected 2 scopes, got %d", len(cfg.Scopes))
+	}
+	if cfg.Scopes[0].Name != "mg-platform" {
+		t.Errorf("Scope name should default to ID, got %q", cfg.Scopes[0].Name)
+	}
+	if cfg.Scopes[1].Name != "Europe" {
+		t.Errorf("Scope name = %q, want Europe", cfg.Scopes[1].Name)
+	}
+}
+
+func TestLoad_MaxConcurrentQueries(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := `subscriptions:
+  - id: "sub-1"
+    name: "prod"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.MaxConcurrentQueries != DefaultMaxConcurrent {
+		t.Errorf("MaxConcurrentQueries = %d, want default %d", cfg.MaxConcurrentQueries, DefaultMaxConcurrent)
+	}
+
+	t.Setenv("AZURE_COST_MAX_CONCURRENT_QUERIES", "8")
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.MaxConcurrentQueries != 8 {
+		t.Errorf("MaxConcurrentQueries = %d, want 8 from environment", cfg.MaxConcurrentQueries)
+	}
+
+	t.Setenv("AZURE_COST_MAX_CONCURRENT_QUERIES", "-1")
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for negative max_concurrent_queries")
+	}
+}
+
+func TestValidate_Auth(t *testing.T) {
+	tests := []struct {
+		name    string
+		auth    AuthConfig
+		wantErr bool
+	}{
+		{"unset", AuthConfig{}, false},
+		{"default", AuthConfig{Type: AuthTypeDefault}, false},
+		{"client secret", AuthConfig{Type: AuthTypeClientSecret, TenantID: "t", ClientID: "c", ClientSecret: "s"}, false},
+		{"client secret missing secret", AuthConfig{Type: AuthTypeClientSecret, TenantID: "t", ClientID: "c"}, true},
+		{"client certificate", AuthConfig{Type: AuthTypeClientCertificate, TenantID: "t", ClientID: "c", CertificatePath: "/cert.pem"}, false},
+		{"client certificate missing path", AuthConfig{Type: AuthTypeClientCertificate, TenantID: "t", ClientID: "c"}, true},
+		{"workload identity from environment", AuthConfig{Type: AuthTypeWorkloadIdentity}, false},
+		{"system-assigned managed identity", AuthConfig{Type: AuthTypeManagedIdentity}, false},
+		{"managed identity with client and resource ID", AuthConfig{Type: AuthTypeManagedIdentity, ClientID: "c", ResourceID: "/r"}, true},
+		{"azure cli", AuthConfig{Type: AuthTypeAzureCLI}, false},
+		{"chained", AuthConfig{Type: AuthTypeChained, Chain: []AuthConfig{{Type: AuthTypeWorkloadIdentity}, {Type: AuthTypeAzureCLI}}}, false},
+		{"chained empty", AuthConfig{Type: AuthTypeChained}, true},
+		{"chained invalid entry", AuthConfig{Type: AuthTypeChained, Chain: []AuthConfig{{Type: AuthTypeClientSecret}}}, true},
+		{"chained nested", AuthConfig{Type: AuthTypeChained, Chain: []AuthConfig{{Type: AuthTypeChained, Chain: []AuthConfig{{}}}}}, true},
+		{"chain without chained type", AuthConfig{Type: AuthTypeAzureCLI, Chain: []AuthConfig{{Type: AuthTypeAzureCLI}}}, true},
+		{"unknown type", AuthConfig{Type: "password"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validTestConfig()
+			cfg.Auth = tt.auth
+
+			err := validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_AuthClientSecretFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `subscriptions:
+  - id: "sub-1"
+    name: "prod"
+auth:
+  type: client_secret
+  tenant_id: "tenant"
+  client_id: "client"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error when client_secret is missing")
+	}
+
+	t.Setenv("AZURE_COST_AUTH_CLIENT_SECRET", "from-env")
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Auth.ClientSecret != "from-env" {
+		t.Errorf("ClientSecret = %q, want value from environment", cfg.Auth.ClientSecret)
+	}
+}
+
+func TestValidate_Cloud(t *testing.T) {
+	tests := []struct {
+		name    string
+		cloud   CloudConfig
+		wantErr bool
+	}{
+		{"unset", CloudConfig{}, false},
+		{"public", CloudConfig{Name: CloudAzurePublic}, false},
+		{"china", CloudConfig{Name: CloudAzureChina}, false},
+		{"government", CloudConfig{Name: CloudAzureGovernment}, false},
+		{"custom", CloudConfig{Name: CloudCustom, ResourceManagerEndpoint: "https://management.contoso.local", AuthorityHost: "https://login.contoso.local"}, false},
+		{"custom missing authority", CloudConfig{Name: CloudCustom, ResourceManagerEndpoint: "https://management.contoso.local"}, true},
+		{"custom plain http", CloudConfig{Name: CloudCustom, ResourceManagerEndpoint: "http://management.contoso.local", AuthorityHost: "https://login.contoso.local"}, true},
+		{"endpoint on built-in cloud", CloudConfig{Name: CloudAzureGovernment, ResourceManagerEndpoint: "https://management.contoso.local"}, true},
+		{"unknown", CloudConfig{Name: "AzureGermany"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validTestConfig()
+			cfg.Cloud = tt.cloud
+
+			err := validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Tenants(t *testing.T) {
+	tenant := func(id string) Tenant {
+		return Tenant{ID: id, Subscriptions: []Subscription{{ID: "sub-" + id, Name: id}}}
+	}
+
+	tests := []struct {
+		name     string
+		topLevel bool   // Keep the top-level subscription
+		tenantID string // Top-level auth.tenant_id
+		tenants  []Tenant
+		wantErr  bool
+	}{
+		{"tenants only", false, "", []Tenant{tenant("a"), tenant("b")}, false},
+		{"top-level with tenant ID", true, "home", []Tenant{tenant("a")}, false},
+		{"top-level without tenant ID", true, "", []Tenant{tenant("a")}, true},
+		{"duplicate tenant", false, "", []Tenant{tenant("a"), tenant("a")}, true},
+		{"tenant duplicates top-level", true, "a", []Tenant{tenant("a")}, true},
+		{"empty tenant ID", false, "", []Tenant{tenant("")}, true},
+		{"tenant without targets", false, "", []Tenant{{ID: "a"}}, true},
+		{"tenant subscription without name", false, "", []Tenant{{ID: "a", Subscriptions: []Subscription{{ID: "sub"}}}}, true},
+		{"tenant with invalid auth", false, "", []Tenant{{ID: "a", Auth: AuthConfig{Type: AuthTypeClientSecret}, Subscriptions: []Subscription{{ID: "s", Name: "s"}}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validTestConfig()
+			if !tt.topLevel {
+				cfg.Subscriptions = nil
+			}
+			cfg.Auth.TenantID = tt.tenantID
+			cfg.Tenants = tt.tenants
+
+			err := validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_Tenants(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `auth:
+  tenant_id: "home"
+subscriptions:
+  - id: "sub-1"
+    name: "prod"
+tenants:
+  - id: "customer"
+    auth:
+      type: client_secret
+      client_id: "client"
+      client_secret: "secret"
+    scopes:
+      - type: management_group
+        id: "mg-root"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.MultiTenant() {
+		t.Error("MultiTenant() = false, want true")
+	}
+
+	tenants := cfg.TenantList()
+	if len(tenants) != 2 {
+		t.Fatalf("TenantList() returned %d tenants, want 2", len(tenants))
+	}
+	if tenants[0].ID != "home" || len(tenants[0].Subscriptions) != 1 {
+		t.Errorf("First tenant = %+v, want top-level tenant home with its subscription", tenants[0])
+	}
+
+	customer := tenants[1]
+	if customer.Name != "customer" {
+		t.Errorf("Name = %q, want default from ID", customer.Name)
+	}
+	if customer.Auth.TenantID != "customer" {
+		t.Errorf("Auth.TenantID = %q, want default from ID", customer.Auth.TenantID)
+	}
+	if customer.Scopes[0].Name != "mg-root" {
+		t.Errorf("Scope name = %q, want default from ID", customer.Scopes[0].Name)
+	}
+	if customer.Discovery.Interval != DefaultDiscoveryInterval {
+		t.Errorf("Discovery.Interval = %d, want default %d", customer.Discovery.Interval, DefaultDiscoveryInterval)
+	}
+}
+
+func TestValidate_Filter(t *testing.T) {
+	dim := func(name string, values ...string) Filter {
+		return Filter{Dimension: &FilterComparison{Name: name, Values: values}}
+	}
+	tag := func(name string, values ...string) Filter {
+		return Filter{Tag: &FilterComparison{Name: name, Values: values}}
+	}
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		groups  []GroupBy
+		wantErr bool
+	}{
+		{"dimension", dim("ChargeType", "Usage"), nil, false},
+		{"alias", dim("Location", "westeurope"), nil, false},
+		{"tag", tag("env", "prod", "staging"), nil, false},
+		{"explicit In", Filter{Dimension: &FilterComparison{Name: "ChargeType", Operator: "In", Values: []string{"Usage"}}}, nil, false},
+		{"and or tree", Filter{And: []Filter{dim("ChargeType", "Usage"), {Or: []Filter{tag("env", "prod"), tag("team", "core")}}}}, nil, false},
+		{"not on grouped dimension", Filter{Not: &Filter{Dimension: &FilterComparison{Name: "ServiceName", Values: []string{"Bandwidth"}}}},
+			[]GroupBy{{Type: GroupTypeDimension, Name: "ServiceName"}}, false},
+		{"not inside top-level and", Filter{And: []Filter{dim("ChargeType", "Usage"), {Not: &Filter{Tag: &FilterComparison{Name: "env", Values: []string{"dev"}}}}}},
+			[]GroupBy{{Type: GroupTypeTagKey, Name: "env"}}, false},
+		{"derived dimension", dim("ResourceName", "vm-1"), nil, true},
+		{"not on grouped derived dimension", Filter{Not: &Filter{Dimension: &FilterComparison{Name: "ResourceName", Values: []string{"vm-1"}}}},
+			[]GroupBy{{Type: GroupTypeDimension, Name: "ResourceName"}}, false},
+		{"not on ungrouped dimension", Filter{Not: &Filter{Dimension: &FilterComparison{Name: "ServiceName", Values: []string{"Bandwidth"}}}}, nil, true},
+		{"not inside or", Filter{Or: []Filter{dim("ChargeType", "Usage"), {Not: &Filter{Tag: &FilterComparison{Name: "env", Values: []string{"dev"}}}}}},
+			[]GroupBy{{Type: GroupTypeTagKey, Name: "env"}}, true},
+		{"empty", Filter{}, nil, true},
+		{"two expressions", Filter{Dimension: &FilterComparison{Name: "ChargeType", Values: []string{"Usage"}}, Tag: &FilterComparison{Name: "env", Values: []string{"prod"}}}, nil, true},
+		{"single and", Filter{And: []Filter{dim("ChargeType", "Usage")}}, nil, true},
+		{"unsupported operator", Filter{Dimension: &FilterComparison{Name: "ChargeType", Operator: "Contains", Values: []string{"Usage"}}}, nil, true},
+		{"no values", dim("ChargeType"), nil, true},
+		{"empty name", tag("", "prod"), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validTestConfig()
+			cfg.Filter = &tt.filter
+			cfg.GroupBy = GroupByConfig{Enabled: len(tt.groups) > 0, Groups: tt.groups}
+
+			err := validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidate_ForecastNegatedFilter tests that filters with a not part are rejected while
+// forecasts are enabled, on the top level as well as on a subscription
+func TestValidate_ForecastNegatedFilter(t *testing.T) {
+	negated := &Filter{Not: &Filter{Dimension: &FilterComparison{Name: "ServiceName", Values: []string{"Bandwidth"}}}}
+	positive := &Filter{Dimension: &FilterComparison{Name: "ChargeType", Values: []string{"Usage"}}}
+
+	tests := []struct {
+		name      string
+		filter    *Filter
+		subFilter *Filter
+		forecast  bool
+		wantErr   bool
+	}{
+		{"negated without forecast", negated, nil, false, false},
+		{"negated with forecast", negated, nil, true, true},
+		{"negated subscription filter with forecast", nil, negated, true, true},
+		{"positive with forecast", positive, positive, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validTestConfig()
+			cfg.Filter = tt.filter
+			cfg.Subscriptions[0].Filter = tt.subFilter
+			cfg.GroupBy = GroupByConfig{Enabled: true, Groups: []GroupBy{{Type: GroupTypeDimension, Name: "ServiceName"}}}
+			cfg.Forecast = ForecastConfig{Enabled: tt.forecast, RefreshInterval: DefaultForecastInterval}
+
+			err := validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_SubscriptionFilter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `subscriptions:
+  - id: "sub-prod"
+    name: "prod"
+    filter:
+      dimension:
+        name: ChargeType
+        values: ["Usage"]
+  - id: "sub-dev"
+    name: "dev"
+filter:
+  or:
+    - tag:
+        name: env
+        values: ["dev"]
+    - tag:
+        name: env
+        values: ["test"]
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	prod := cfg.FilterFor(cfg.Subscriptions[0].Filter)
+	if prod.Dimension == nil || prod.Dimension.Name != "ChargeType" {
+		t.Errorf("Filter for sub-prod = %+v, want its own ChargeType filter", prod)
+	}
+	if dev := cfg.FilterFor(cfg.Subscriptions[1].Filter); dev != cfg.Filter {
+		t.Errorf("Filter for sub-dev = %+v, want top-level filter", dev)
+	}
+
+	// Filters are validated at load time
+	invalid := strings.Replace(content, `values: ["Usage"]`, `values: []`, 1)
+	if err := os.WriteFile(path, []byte(invalid), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for filter without values")
+	}
+}
+
+func TestFilterSplit(t *testing.T) {
+	usage := Filter{Dimension: &FilterComparison{Name: "ChargeType", Values: []string{"Usage"}}}
+	prod := Filter{Tag: &FilterComparison{Name: "env", Values: []string{"prod"}}}
+	notDev := Filter{Not: &Filter{Tag: &FilterComparison{Name: "env", Values: []string{"dev"}}}}
+
+	server, excludes := (&Filter{And: []Filter{usage, notDev}}).Split()
+	if server == nil || server.Dimension == nil || len(excludes) != 1 {
+		t.Errorf("Split(and(usage, not dev)) = %+v, %+v, want usage and one exclusion", server, excludes)
+	}
+
+	server, excludes = (&Filter{And: []Filter{usage, prod, notDev}}).Split()
+	if server == nil || len(server.And) != 2 || len(excludes) != 1 {
+		t.Errorf("Split(and(usage, prod, not dev)) = %+v, %+v, want and(usage, prod) and one exclusion", server, excludes)
+	}
+
+	server, excludes = notDev.Split()
+	if server != nil || len(excludes) != 1 {
+		t.Errorf("Split(not dev) = %+v, %+v, want no server filter and one exclusion", server, excludes)
+	}
+
+	server, excludes = (*Filter)(nil).Split()
+	if server != nil || excludes != nil {
+		t.Errorf("Split(nil) = %+v, %+v, want nil", server, excludes)
+	}
+}
+
+func TestValidate_Forecast(t *testing.T) {
+	tests := []struct {
+		name     string
+		forecast ForecastConfig
+		wantErr  bool
+	}{
+		{"disabled", ForecastConfig{}, false},
+		{"enabled", ForecastConfig{Enabled: true, RefreshInterval: DefaultForecastInterval}, false},
+		{"interval too low", ForecastConfig{Enabled: true, RefreshInterval: 10}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validTestConfig()
+			cfg.Forecast = tt.forecast
+
+			err := validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Budgets(t *testing.T) {
+	cfg := validTestConfig()
+	cfg.Budgets = BudgetsConfig{Enabled: true, RefreshInterval: DefaultBudgetInterval}
+	if err := validate(cfg); err != nil {
+		t.Errorf("validate() error = %v", err)
+	}
+
+	cfg.Budgets.RefreshInterval = 10
+	if err := validate(cfg); err == nil {
+		t.Error("Expected error for budgets refresh_interval below minimum")
+	}
+}
+
+func TestValidate_MonthTotals(t *testing.T) {
+	cfg := validTestConfig()
+	cfg.MonthTotals = MonthTotalsConfig{Enabled: true, RefreshInterval: DefaultMonthTotalsInterval}
+	if err := validate(cfg); err != nil {
+		t.Errorf("validate() error = %v", err)
+	}
+
+	cfg.MonthTotals.RefreshInterval = 10
+	if err := validate(cfg); err == nil {
+		t.Error("Expected error for month_totals refresh_interval below minimum")
+	}
+}
+
+func TestValidate_Utilization(t *testing.T) {
+	billing := Scope{Type: ScopeTypeBillingAccount, ID: "12345678"}
+	tests := []struct {
+		name        string
+		scopes      []Scope
+		tenants     []Tenant
+		utilization UtilizationConfig
+		wantErr     bool
+	}{
+		{"billing account", []Scope{billing}, nil, UtilizationConfig{Enabled: true, RefreshInterval: 3600, DaysToQuery: 3}, false},
+		{"billing scope of another tenant", nil, []Tenant{{ID: "tenant-b", Scopes: []Scope{billing}}}, UtilizationConfig{Enabled: true, RefreshInterval: 3600, DaysToQuery: 3}, false},
+		{"no billing scope", nil, nil, UtilizationConfig{Enabled: true, RefreshInterval: 3600, DaysToQuery: 3}, true},
+		{"interval too low", []Scope{billing}, nil, UtilizationConfig{Enabled: true, RefreshInterval: 10, DaysToQuery: 3}, true},
+		{"no days", []Scope{billing}, nil, UtilizationConfig{Enabled: true, RefreshInterval: 3600}, true},
+		{"disabled", nil, nil, UtilizationConfig{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validTestConfig()
+			cfg.Auth.TenantID = "tenant-a"
+			cfg.Scopes = tt.scopes
+			cfg.Tenants = tt.tenants
+			cfg.Utilization = tt.utilization
+			err := validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAWS(t *testing.T) {
+	tests := []struct {
+		name    string
+		aws     AWSConfig
+		wantErr bool
+	}{
+		{"defaults", AWSConfig{}, false},
+		{"service and tag", AWSConfig{GroupBy: []string{"SERVICE", "tag:team"}}, false},
+		{"too many groups", AWSConfig{GroupBy: []string{"LINKED_ACCOUNT", "SERVICE", "REGION"}}, true},
+		{"unknown dimension", AWSConfig{GroupBy: []string{"ServiceName"}}, true},
+		{"empty tag key", AWSConfig{GroupBy: []string{"tag:"}}, true},
+		{"duplicate group", AWSConfig{GroupBy: []string{"SERVICE", "SERVICE"}}, true},
+		{"invalid cost type", AWSConfig{CostType: "blended"}, true},
+		{"invalid endpoint", AWSConfig{Endpoint: "ce.example.com"}, true},
+		{"custom endpoint", AWSConfig{Endpoint: "https://ce.example.com"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.aws
+			applyAWSDefaults(&a)
+			err := validateAWS(a)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateAWS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateGCP(t *testing.T) {
+	tests := []struct {
+		name    string
+		gcp     GCPConfig
+		wantErr bool
+	}{
+		{"directory", GCPConfig{Path: "/data/billing"}, false},
+		{"glob with format", GCPConfig{Path: "/data/billing/*.gz", Format: GCPFormatJSON}, false},
+		{"missing path", GCPConfig{}, true},
+		{"invalid pattern", GCPConfig{Path: "/data/[billing"}, true},
+		{"invalid format", GCPConfig{Path: "/data/billing", Format: "avro"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateGCP(tt.gcp)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateGCP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateFocus(t *testing.T) {
+	tests := []struct {
+		name    string
+		focus   FocusConfig
+		wantErr bool
+	}{
+		{"directory", FocusConfig{Path: "/data/focus", CostType: CostTypeActual}, false},
+		{"glob with format", FocusConfig{Path: "/data/focus/*.parquet", Format: FocusFormatParquet, CostType: CostTypeBoth}, false},
+		{"missing path", FocusConfig{CostType: CostTypeActual}, true},
+		{"invalid pattern", FocusConfig{Path: "/data/[focus", CostType: CostTypeActual}, true},
+		{"invalid format", FocusConfig{Path: "/data/focus", Format: "json", CostType: CostTypeActual}, true},
+		{"invalid cost type", FocusConfig{Path: "/data/focus", CostType: "net"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFocus(tt.focus)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateFocus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAzureExport(t *testing.T) {
+	tests := []struct {
+		name    string
+		export  AzureExportConfig
+		wantErr bool
+	}{
+		{"directory", AzureExportConfig{Path: "/data/exports", CostType: CostTypeActual}, false},
+		{"glob", AzureExportConfig{Path: "/data/exports/*/*.csv", CostType: CostTypeBoth}, false},
+		{"missing path", AzureExportConfig{CostType: CostTypeActual}, true},
+		{"invalid pattern", AzureExportConfig{Path: "/data/[exports", CostType: CostTypeActual}, true},
+		{"invalid cost type", AzureExportConfig{Path: "/data/exports", CostType: "net"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAzureExport(tt.export)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateAzureExport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDateRange_Days(t *testing.T) {
+	offset := 1
+	d := DateRange{DaysToQuery: 3, EndDateOffset: &offset}
+	start, end := d.Days(time.Date(2026, 3, 1, 23, 30, 0, 0, time.FixedZone("CET", 3600)))
+
+	// 23:30 CET is 22:30 UTC on March 1st
+	if want := time.Date(2026, 2, 26, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("start = %v, want %v", start, want)
+	}
+	if want := time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("end = %v, want %v", end, want)
+	}
+}
+
+func TestDayWithin(t *testing.T) {
+	start := time.Date(2026, 2, 26, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		t      time.Time
+		want   string
+		within bool
+	}{
+		{"first day", start, "2026-02-26", true},
+		{"end of last day", time.Date(2026, 2, 28, 23, 59, 59, 0, time.UTC), "2026-02-28", true},
+		{"UTC day of other zone", time.Date(2026, 3, 1, 0, 30, 0, 0, time.FixedZone("CET", 3600)), "2026-02-28", true},
+		{"before", time.Date(2026, 2, 25, 23, 59, 59, 0, time.UTC), "", false},
+		{"after", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, within := DayWithin(tt.t, start, end)
+			if got != tt.want || within != tt.within {
+				t.Errorf("DayWithin() = %q, %v, want %q, %v", got, within, tt.want, tt.within)
+			}
+		})
+	}
+}
+
+func TestGroupByConfig_TagKeys(t *testing.T) {
+	g := GroupByConfig{
+		Enabled: true,
+		Groups: []GroupBy{
+			{Type: GroupTypeTagKey, Name: "team"},
+			{Type: GroupTypeDimension, Name: "ServiceName"},
+			{Type: GroupTypeTagKey, Name: "env", LabelName: "environment"},
+		},
+	}
+	if got := g.TagKeys(); !slices.Equal(got, []string{"team", "env"}) {
+		t.Errorf("TagKeys() = %v, want [team env]", got)
+	}
+
+	g.Enabled = false
+	if got := g.TagKeys(); got != nil {
+		t.Errorf("TagKeys() = %v, want nil with grouping disabled", got)
+	}
+}
+
+func TestValidateProviders(t *testing.T) {
+	gcp := &GCPConfig{Path: "/data/billing"}
+	tests := []struct {
+		name          string
+		providers     []ProviderConfig
+		subscriptions []Subscription
+		wantErr       bool
+	}{
+		{"azure only", []ProviderConfig{{Type: provider.ProviderAzure}}, []Subscription{{ID: "test", Name: "test"}}, false},
+		{"all providers", []ProviderConfig{{Type: provider.ProviderAzure}, {Type: provider.ProviderAWS, AWS: &AWSConfig{}}, {Type: provider.ProviderGCP, GCP: gcp}}, []Subscription{{ID: "test", Name: "test"}}, false},
+		{"aws without azure settings", []ProviderConfig{{Type: provider.ProviderAWS, AWS: &AWSConfig{}, RefreshInterval: 7200}}, nil, false},
+		{"azure without subscriptions", []ProviderConfig{{Type: provider.ProviderAzure}, {Type: provider.ProviderGCP, GCP: gcp}}, nil, true},
+		{"unknown type", []ProviderConfig{{Type: "oracle"}}, nil, true},
+		{"duplicate type", []ProviderConfig{{Type: provider.ProviderGCP, GCP: gcp}, {Type: provider.ProviderGCP, GCP: gcp}}, nil, true},
+		{"interval too low", []ProviderConfig{{Type: provider.ProviderGCP, GCP: gcp, RefreshInterval: 10}}, nil, true},
+		{"gcp without settings", []ProviderConfig{{Type: provider.ProviderGCP}}, nil, true},
+		{"focus", []ProviderConfig{{Type: provider.ProviderFocus, Focus: &FocusConfig{Path: "/data/focus"}}}, nil, false},
+		{"focus without settings", []ProviderConfig{{Type: provider.ProviderFocus}}, nil, true},
+		{"azure export without subscriptions", []ProviderConfig{{Type: provider.ProviderAzure, Export: &AzureExportConfig{Path: "/data/exports"}}}, nil, false},
+		{"azure export without path", []ProviderConfig{{Type: provider.ProviderAzure, Export: &AzureExportConfig{}}}, nil, true},
+		{"azure export with subscriptions", []ProviderConfig{{Type: provider.ProviderAzure, Export: &AzureExportConfig{Path: "/data/exports"}}}, []Subscription{{ID: "test", Name: "test"}}, true},
+		{"export settings of another type", []ProviderConfig{{Type: provider.ProviderFocus, Focus: &FocusConfig{Path: "/data/focus"}, Export: &AzureExportConfig{Path: "/data/exports"}}}, nil, true},
+		{"settings of another type", []ProviderConfig{{Type: provider.ProviderGCP, GCP: gcp, AWS: &AWSConfig{}}}, nil, true},
+		{"invalid aws settings", []ProviderConfig{{Type: provider.ProviderAWS, AWS: &AWSConfig{CostType: "net"}}}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validTestConfig()
+			cfg.Subscriptions = tt.subscriptions
+			cfg.Providers = tt.providers
+			applyProviderDefaults(cfg)
+			err := validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidate_AzureExportFilter tests that filters are rejected when the azure provider reads exports
+func TestValidate_AzureExportFilter(t *testing.T) {
+	cfg := validTestConfig()
+	cfg.Subscriptions = nil
+	cfg.Providers = []ProviderConfig{{Type: provider.ProviderAzure, Export: &AzureExportConfig{Path: "/data/exports"}}}
+	cfg.Filter = &Filter{Dimension: &FilterComparison{Name: "ChargeType", Values: []string{"Usage"}}}
+	applyProviderDefaults(cfg)
+
+	if err := validate(cfg); err == nil || !strings.Contains(err.Error(), "filter") {
+		t.Errorf("validate() error = %v, want filter rejected", err)
+	}
+
+	// Other providers ignore the Azure settings
+	cfg.Providers = []ProviderConfig{{Type: provider.ProviderAWS, AWS: &AWSConfig{}}}
+	applyProviderDefaults(cfg)
+	if err := validate(cfg); err != nil {
+		t.Errorf("validate() error = %v, want nil without the azure provider", err)
+	}
+}
+
+// TestApplyProviderDefaults tests that Azure runs alone by default and aws providers get default settings
+func TestApplyProviderDefaults(t *testing.T) {
+	cfg := &Config{RefreshInterval: 3600}
+	applyProviderDefaults(cfg)
+	if len(cfg.Providers) != 1 || cfg.Providers[0].Type != provider.ProviderAzure {
+		t.Fatalf("Providers = %+v, want azure only", cfg.Providers)
+	}
+	if got := cfg.ProviderRefreshInterval(cfg.Providers[0]); got != 3600 {
+		t.Errorf("ProviderRefreshInterval() = %d, want top-level 3600", got)
+	}
+
+	cfg = &Config{RefreshInterval: 3600, Providers: []ProviderConfig{{Type: provider.ProviderAWS, RefreshInterval: 21600}}}
+	applyProviderDefaults(cfg)
+	aws := cfg.Providers[0].AWS
+	if aws == nil || aws.Region != DefaultAWSRegion || aws.CostType != CostTypeActual || len(aws.GroupBy) != len(DefaultAWSGroupBy) {
+		t.Errorf("AWS settings = %+v, want defaults", aws)
+	}
+	if got := cfg.ProviderRefreshInterval(cfg.Providers[0]); got != 21600 {
+		t.Errorf("ProviderRefreshInterval() = %d, want 21600", got)
+	}
+	if cfg.HasProvider(provider.ProviderAzure) || !cfg.HasProvider(provider.ProviderAWS) {
+		t.Errorf("HasProvider() does not match providers %+v", cfg.Providers)
+	}
+
+	cfg.Providers[0].AWS.CostType = CostTypeBoth
+	if !cfg.QueriesBothCostTypes() {
+		t.Error("QueriesBothCostTypes() = false with aws cost_type both")
+	}
+}
+
+func TestValidate_Discovery(t *testing.T) {
+	tests := []struct {
+		name      string
+		subs      []Subscription
+		discovery DiscoveryConfig
+		wantErr   bool
+	}{
+		{"discovery only", nil, DiscoveryConfig{Enabled: true, Interval: 3600}, false},
+		{"subscription without name", []Subscription{{ID: "sub-1"}}, DiscoveryConfig{Enabled: true, Interval: 3600}, false},
+		{"interval too low", nil, DiscoveryConfig{Enabled: true, Interval: 10}, true},
+		{"invalid include regex", nil, DiscoveryConfig{Enabled: true, Interval: 3600, Include: []string{"("}}, true},
+		{"invalid exclude regex", nil, DiscoveryConfig{Enabled: true, Interval: 3600, Exclude: []string{"[a-"}}, true},
+		{"nothing to query", nil, DiscoveryConfig{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validTestConfig()
+			cfg.Subscriptions = tt.subs
+			cfg.Discovery = tt.discovery
+
+			err := validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestLoad_MissingFile_Error(t *testing.T) {
 	_, err := Load("/nonexistent/path/config.yaml")
 	if err == nil {
@@ -319,3 +1211,15 @@ subscriptions:
 		t.Error("Load() error = nil, want error for malformed YAML")
 	}
 }
+
+// validTestConfig returns a configuration that passes validation
+func validTestConfig() *Config {
+	return &Config{
+		Subscriptions:   []Subscription{{ID: "test", Name: "test"}},
+		CostType:        CostTypeActual,
+		RefreshInterval: 3600,
+		HTTPPort:        8080,
+		APITimeout:      30,
+		DateRange:       DateRange{DaysToQuery: 7},
+	}
+}
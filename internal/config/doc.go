@@ -11,15 +11,20 @@
 //
 // Supported environment variables:
 //   - AZURE_COST_CURRENCY: Currency symbol for cost values
+//   - AZURE_COST_COST_TYPE: Cost type to query (actual, amortized, both)
+//   - AZURE_COST_AUTH_CLIENT_SECRET: Client secret for auth type client_secret
+//   - AZURE_COST_CLOUD: Azure cloud (AzurePublic, AzureChina, AzureGovernment)
 //   - AZURE_COST_REFRESH_INTERVAL: Refresh interval in seconds (minimum: 60)
 //   - AZURE_COST_HTTP_PORT: HTTP server port (1-65535)
 //   - AZURE_COST_LOG_LEVEL: Log level (debug, info, warn, error)
 //   - AZURE_COST_END_DATE_OFFSET: Days to offset the end date
 //   - AZURE_COST_DAYS_TO_QUERY: Number of days to query (minimum: 1)
+//   - AZURE_COST_MAX_CONCURRENT_QUERIES: Subscriptions queried in parallel
 //   - AZURE_COST_SUBSCRIPTIONS: Comma-separated subscription IDs or id:name pairs
 //
 // The main type is Config, which contains all application settings including:
 //   - Subscriptions: List of Azure subscriptions to monitor
+//   - Tenants: Additional Entra ID tenants with their own credentials
 //   - DateRange: Date range configuration for cost queries
 //   - GroupBy: Grouping configuration for cost queries
 //   - RefreshInterval: How often to refresh cost data
@@ -0,0 +1,271 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// FilterOperatorIn matches rows whose value is one of the listed values
+// It is the only comparison operator supported by the Cost Management Query API
+const FilterOperatorIn = "In"
+
+// Filter is a server-side query filter expression
+// Exactly one of And, Or, Not, Dimension or Tag must be set
+type Filter struct {
+	And       []Filter          `yaml:"and"` // All sub-filters must match (at least 2)
+	Or        []Filter          `yaml:"or"`  // Any sub-filter must match (at least 2)
+	Not       *Filter           `yaml:"not"` // Sub-filter must not match (see Split)
+	Dimension *FilterComparison `yaml:"dimension"`
+	Tag       *FilterComparison `yaml:"tag"`
+}
+
+// FilterComparison compares a dimension or tag against a list of values
+type FilterComparison struct {
+	Name     string   `yaml:"name"`     // Dimension name (or alias) or tag key
+	Operator string   `yaml:"operator"` // Comparison operator (default: In)
+	Values   []string `yaml:"values"`
+}
+
+// DimensionName returns the dimension name sent to the API
+// Aliases of registered dimensions are resolved to their canonical name
+func (c FilterComparison) DimensionName() string {
+	if dim, ok := provider.LookupDimension(c.Name); ok {
+		return dim.Name
+	}
+	return c.Name
+}
+
+// FilterFor returns the filter applied to a subscription or scope
+// An explicit filter takes precedence over the top-level one
+func (c *Config) FilterFor(filter *Filter) *Filter {
+	if filter != nil {
+		return filter
+	}
+	return c.Filter
+}
+
+// Split separates a filter into the part evaluated by the API and negated
+// sub-filters that exclude records after the query
+// The Query API has no negation, so Not is only valid at the root or directly below a root And
+func (f *Filter) Split() (server *Filter, excludes []Filter) {
+	if f == nil {
+		return nil, nil
+	}
+	if f.Not != nil {
+		return nil, []Filter{*f.Not}
+	}
+	if len(f.And) == 0 {
+		return f, nil
+	}
+
+	var kept []Filter
+	for _, sub := range f.And {
+		if sub.Not != nil {
+			excludes = append(excludes, *sub.Not)
+			continue
+		}
+		kept = append(kept, sub)
+	}
+
+	switch len(kept) {
+	case 0:
+		return nil, excludes
+	case 1:
+		return &kept[0], excludes
+	default:
+		return &Filter{And: kept}, excludes
+	}
+}
+
+// validateFilter validates a filter tree
+// Negated sub-filters are evaluated on the exported records, so they may only
+// reference dimensions and tags that are grouped by
+func validateFilter(f Filter, groups []GroupBy) error {
+	if err := validateFilterNode(f, 0, false); err != nil {
+		return err
+	}
+
+	server, excludes := f.Split()
+	if server != nil {
+		if err := validateServerFilter(*server); err != nil {
+			return err
+		}
+	}
+	for _, ex := range excludes {
+		if err := validateNegatedFilter(ex, groups); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateServerFilter checks that the part of a filter evaluated by the API uses no derived dimensions
+func validateServerFilter(f Filter) error {
+	for _, child := range append(append([]Filter{}, f.And...), f.Or...) {
+		if err := validateServerFilter(child); err != nil {
+			return err
+		}
+	}
+	if f.Dimension != nil {
+		if dim, ok := provider.LookupDimension(f.Dimension.Name); ok && dim.Derived() {
+			return fmt.Errorf("dimension %q is derived from %s and can only be used below not, filter on %s instead",
+				f.Dimension.Name, dim.QueryName(), dim.QueryName())
+		}
+	}
+	return nil
+}
+
+// validateFilterNode validates a single filter node and its children
+// depth is 0 for the root; parentAnd is true for direct children of a root And
+func validateFilterNode(f Filter, depth int, parentAnd bool) error {
+	set := 0
+	for _, present := range []bool{len(f.And) > 0, len(f.Or) > 0, f.Not != nil, f.Dimension != nil, f.Tag != nil} {
+		if present {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("filter must have exactly one of and, or, not, dimension or tag")
+	}
+
+	switch {
+	case len(f.And) > 0, len(f.Or) > 0:
+		op, children := "and", f.And
+		if len(f.Or) > 0 {
+			op, children = "or", f.Or
+		}
+		if len(children) < 2 {
+			return fmt.Errorf("filter %s requires at least 2 expressions, got %d", op, len(children))
+		}
+		for i, child := range children {
+			if err := validateFilterNode(child, depth+1, op == "and" && depth == 0); err != nil {
+				return fmt.Errorf("%s[%d]: %w", op, i, err)
+			}
+		}
+	case f.Not != nil:
+		if depth > 0 && !parentAnd {
+			return fmt.Errorf("filter not is only supported at the top level or directly inside a top-level and")
+		}
+		if err := validateFilterNode(*f.Not, depth+2, false); err != nil {
+			return fmt.Errorf("not: %w", err)
+		}
+	case f.Dimension != nil:
+		if err := validateFilterComparison(*f.Dimension); err != nil {
+			return fmt.Errorf("dimension: %w", err)
+		}
+	case f.Tag != nil:
+		if err := validateFilterComparison(*f.Tag); err != nil {
+			return fmt.Errorf("tag: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateFilterComparison validates a dimension or tag comparison
+func validateFilterComparison(c FilterComparison) error {
+	if c.Name == "" {
+		return fmt.Errorf("empty name")
+	}
+	if c.Operator != "" && c.Operator != FilterOperatorIn {
+		return fmt.Errorf("%q: operator must be %s, got %q", c.Name, FilterOperatorIn, c.Operator)
+	}
+	if len(c.Values) == 0 {
+		return fmt.Errorf("%q: at least one value is required", c.Name)
+	}
+	return nil
+}
+
+// validateNegatedFilter checks that every comparison below a not is grouped by
+func validateNegatedFilter(f Filter, groups []GroupBy) error {
+	for _, child := range append(append([]Filter{}, f.And...), f.Or...) {
+		if err := validateNegatedFilter(child, groups); err != nil {
+			return err
+		}
+	}
+	if f.Dimension != nil {
+		dim, ok := provider.LookupDimension(f.Dimension.Name)
+		if !ok || !groupedBy(groups, GroupTypeDimension, dim.Name) {
+			return fmt.Errorf("not: dimension %q must be a group_by dimension (supported: %s)",
+				f.Dimension.Name, strings.Join(provider.Dimensions(), ", "))
+		}
+	}
+	if f.Tag != nil && !groupedBy(groups, GroupTypeTagKey, f.Tag.Name) {
+		return fmt.Errorf("not: tag %q must be a group_by tag", f.Tag.Name)
+	}
+	return nil
+}
+
+// groupedBy reports whether a dimension or tag is part of the grouping
+func groupedBy(groups []GroupBy, groupType, name string) bool {
+	for _, g := range groups {
+		if g.Type != groupType {
+			continue
+		}
+		if groupType == GroupTypeTagKey && g.Name == name {
+			return true
+		}
+		if groupType == GroupTypeDimension {
+			if dim, ok := provider.LookupDimension(g.Name); ok && dim.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateFilters validates the top-level filter and the filters of all subscriptions and scopes
+func validateFilters(cfg *Config) error {
+	var groups []GroupBy
+	if cfg.GroupBy.Enabled {
+		groups = cfg.GroupBy.Groups
+	}
+
+	if cfg.Filter != nil {
+		if err := validateFilter(*cfg.Filter, groups); err != nil {
+			return fmt.Errorf("invalid filter: %w", err)
+		}
+		if err := validateForecastFilter(cfg, cfg.Filter); err != nil {
+			return fmt.Errorf("invalid filter: %w", err)
+		}
+	}
+
+	for _, t := range cfg.TenantList() {
+		for _, sub := range t.Subscriptions {
+			if sub.Filter == nil {
+				continue
+			}
+			if err := validateFilter(*sub.Filter, groups); err != nil {
+				return fmt.Errorf("subscription %s: invalid filter: %w", sub.ID, err)
+			}
+			if err := validateForecastFilter(cfg, sub.Filter); err != nil {
+				return fmt.Errorf("subscription %s: invalid filter: %w", sub.ID, err)
+			}
+		}
+		for _, scope := range t.Scopes {
+			if scope.Filter == nil {
+				continue
+			}
+			if err := validateFilter(*scope.Filter, groups); err != nil {
+				return fmt.Errorf("scope %s: invalid filter: %w", scope.ID, err)
+			}
+			if err := validateForecastFilter(cfg, scope.Filter); err != nil {
+				return fmt.Errorf("scope %s: invalid filter: %w", scope.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateForecastFilter rejects negated sub-filters while forecasts are enabled
+// Forecast rows are not grouped, so the excluded costs can't be taken out of them
+func validateForecastFilter(cfg *Config, f *Filter) error {
+	if !cfg.Forecast.Enabled {
+		return nil
+	}
+	if _, excludes := f.Split(); len(excludes) > 0 {
+		return fmt.Errorf("not can't be applied to forecasts, remove it or disable forecast")
+	}
+	return nil
+}
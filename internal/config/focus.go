@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Supported FOCUS file formats
+const (
+	FocusFormatCSV     = "csv"     // CSV with a header line of FOCUS column names
+	FocusFormatParquet = "parquet" // Parquet with FOCUS column names
+)
+
+// FocusConfig configures the FOCUS (FinOps Open Cost and Usage Specification) file provider
+// Any vendor export in the FOCUS schema can be read, e.g. Azure, AWS or SaaS cost exports
+type FocusConfig struct {
+	Path   string `yaml:"path"`   // Export file, directory (searched recursively) or glob pattern
+	Format string `yaml:"format"` // csv or parquet (default: by file extension)
+
+	CostType string `yaml:"cost_type"` // actual (BilledCost), amortized (EffectiveCost) or both
+}
+
+// CostTypes returns the individual cost types to export ("both" expands to actual and amortized)
+func (f FocusConfig) CostTypes() []string {
+	return expandCostType(f.CostType)
+}
+
+// applyFocusDefaults sets the default cost type
+func applyFocusDefaults(f *FocusConfig) {
+	if f.CostType == "" {
+		f.CostType = DefaultCostType
+	}
+}
+
+// validateFocus validates the FOCUS file settings
+func validateFocus(f FocusConfig) error {
+	if f.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	if _, err := filepath.Match(f.Path, ""); err != nil {
+		return fmt.Errorf("invalid path pattern %q: %w", f.Path, err)
+	}
+
+	switch f.Format {
+	case "", FocusFormatCSV, FocusFormatParquet:
+	default:
+		return fmt.Errorf("format must be %s or %s, got %q", FocusFormatCSV, FocusFormatParquet, f.Format)
+	}
+
+	switch f.CostType {
+	case CostTypeActual, CostTypeAmortized, CostTypeBoth:
+	default:
+		return fmt.Errorf("cost_type must be %s, %s or %s, got %q",
+			CostTypeActual, CostTypeAmortized, CostTypeBoth, f.CostType)
+	}
+
+	return nil
+}
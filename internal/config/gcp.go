@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Supported GCP billing export file formats
+const (
+	GCPFormatJSON = "json" // Newline-delimited JSON, as written by bq extract
+	GCPFormatCSV  = "csv"  // CSV with flattened column names (service.description or service_description)
+)
+
+// GCPConfig configures the GCP billing export provider
+// The billing data is read from files in the BigQuery billing export schema,
+// e.g. the standard or detailed usage cost table extracted to Cloud Storage
+type GCPConfig struct {
+	// Billing account the export belongs to; rows without a project are attributed to it
+	BillingAccountID   string `yaml:"billing_account_id"`
+	BillingAccountName string `yaml:"billing_account_name"` // Friendly name (defaults to billing_account_id)
+
+	Path   string `yaml:"path"`   // Export file, directory or glob pattern
+	Format string `yaml:"format"` // json or csv (default: by file extension)
+}
+
+// applyGCPDefaults sets the default billing account name
+func applyGCPDefaults(g *GCPConfig) {
+	if g.BillingAccountName == "" {
+		g.BillingAccountName = g.BillingAccountID
+	}
+}
+
+// validateGCP validates the GCP billing export settings
+func validateGCP(g GCPConfig) error {
+	if g.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	if _, err := filepath.Match(g.Path, ""); err != nil {
+		return fmt.Errorf("invalid path pattern %q: %w", g.Path, err)
+	}
+
+	switch g.Format {
+	case "", GCPFormatJSON, GCPFormatCSV:
+	default:
+		return fmt.Errorf("format must be %s or %s, got %q", GCPFormatJSON, GCPFormatCSV, g.Format)
+	}
+
+	return nil
+}
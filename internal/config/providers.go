@@ -0,0 +1,168 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// providerTypes lists the provider types that can be configured, in documentation order
+var providerTypes = []provider.ProviderType{
+	provider.ProviderAzure,
+	provider.ProviderAWS,
+	provider.ProviderGCP,
+	provider.ProviderFocus,
+}
+
+// ProviderConfig configures a cost provider run by the exporter
+// Azure providers use the top-level Azure settings (subscriptions, scopes, auth, ...)
+type ProviderConfig struct {
+	Type            provider.ProviderType `yaml:"type"`             // azure, aws, gcp or focus
+	RefreshInterval int                   `yaml:"refresh_interval"` // Seconds between cost queries (0 = top-level refresh_interval)
+	AWS             *AWSConfig            `yaml:"aws"`              // Settings of the aws provider (all optional)
+	GCP             *GCPConfig            `yaml:"gcp"`              // Required settings of gcp providers
+	Focus           *FocusConfig          `yaml:"focus"`            // Required settings of focus providers
+	Export          *AzureExportConfig    `yaml:"export"`           // Azure only: read scheduled exports instead of querying the API
+}
+
+// applyProviderDefaults runs the Azure provider alone when no providers are configured
+// and applies the defaults of the provider-specific settings
+func applyProviderDefaults(cfg *Config) {
+	if len(cfg.Providers) == 0 {
+		cfg.Providers = []ProviderConfig{{Type: provider.ProviderAzure}}
+	}
+	for i := range cfg.Providers {
+		p := &cfg.Providers[i]
+		if p.Type == provider.ProviderAWS && p.AWS == nil {
+			p.AWS = &AWSConfig{}
+		}
+		if p.AWS != nil {
+			applyAWSDefaults(p.AWS)
+		}
+		if p.GCP != nil {
+			applyGCPDefaults(p.GCP)
+		}
+		if p.Focus != nil {
+			applyFocusDefaults(p.Focus)
+		}
+		if p.Export != nil {
+			applyAzureExportDefaults(p.Export)
+		}
+	}
+}
+
+// validateProviders validates the provider list and the settings of every provider
+// Each type may be configured once, since the provider label tells their metrics apart
+func validateProviders(providers []ProviderConfig) error {
+	seen := make(map[provider.ProviderType]bool, len(providers))
+	for i, p := range providers {
+		if !isProviderType(p.Type) {
+			return fmt.Errorf("provider %d: type must be one of %s, got %q", i, providerTypeList(), p.Type)
+		}
+		if seen[p.Type] {
+			return fmt.Errorf("provider %s is configured more than once", p.Type)
+		}
+		seen[p.Type] = true
+
+		if p.RefreshInterval != 0 && p.RefreshInterval < MinRefreshInterval {
+			return fmt.Errorf("provider %s: refresh_interval must be at least %d seconds, got %d",
+				p.Type, MinRefreshInterval, p.RefreshInterval)
+		}
+
+		if p.AWS != nil && p.Type != provider.ProviderAWS {
+			return fmt.Errorf("provider %s: aws settings are only valid for provider %s", p.Type, provider.ProviderAWS)
+		}
+		if p.GCP != nil && p.Type != provider.ProviderGCP {
+			return fmt.Errorf("provider %s: gcp settings are only valid for provider %s", p.Type, provider.ProviderGCP)
+		}
+		if p.Focus != nil && p.Type != provider.ProviderFocus {
+			return fmt.Errorf("provider %s: focus settings are only valid for provider %s", p.Type, provider.ProviderFocus)
+		}
+		if p.Export != nil && p.Type != provider.ProviderAzure {
+			return fmt.Errorf("provider %s: export settings are only valid for provider %s", p.Type, provider.ProviderAzure)
+		}
+
+		switch p.Type {
+		case provider.ProviderAzure:
+			if p.Export != nil {
+				if err := validateAzureExport(*p.Export); err != nil {
+					return fmt.Errorf("provider %s: export: %w", p.Type, err)
+				}
+			}
+		case provider.ProviderAWS:
+			if err := validateAWS(*p.AWS); err != nil {
+				return fmt.Errorf("provider %s: %w", p.Type, err)
+			}
+		case provider.ProviderGCP:
+			if p.GCP == nil {
+				return fmt.Errorf("provider %s requires gcp settings", p.Type)
+			}
+			if err := validateGCP(*p.GCP); err != nil {
+				return fmt.Errorf("provider %s: %w", p.Type, err)
+			}
+		case provider.ProviderFocus:
+			if p.Focus == nil {
+				return fmt.Errorf("provider %s requires focus settings", p.Type)
+			}
+			if err := validateFocus(*p.Focus); err != nil {
+				return fmt.Errorf("provider %s: %w", p.Type, err)
+			}
+		}
+	}
+	return nil
+}
+
+// isProviderType reports whether t is a configurable provider type
+func isProviderType(t provider.ProviderType) bool {
+	return slices.Contains(providerTypes, t)
+}
+
+// providerTypeList returns the configurable provider types as a comma-separated list
+func providerTypeList() string {
+	names := make([]string, len(providerTypes))
+	for i, t := range providerTypes {
+		names[i] = string(t)
+	}
+	return strings.Join(names, ", ")
+}
+
+// HasProvider reports whether a provider of the given type is configured
+func (c *Config) HasProvider(t provider.ProviderType) bool {
+	for _, p := range c.Providers {
+		if p.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// ProviderRefreshInterval returns the refresh interval of a provider in seconds
+// Providers without their own interval use the top-level refresh_interval
+func (c *Config) ProviderRefreshInterval(p ProviderConfig) int {
+	if p.RefreshInterval > 0 {
+		return p.RefreshInterval
+	}
+	return c.RefreshInterval
+}
+
+// QueriesBothCostTypes reports whether any provider queries both actual and amortized costs
+// The collector then distinguishes them with a cost_type label
+func (c *Config) QueriesBothCostTypes() bool {
+	if c.CostType == CostTypeBoth {
+		return true
+	}
+	for _, p := range c.Providers {
+		if p.AWS != nil && p.AWS.CostType == CostTypeBoth {
+			return true
+		}
+		if p.Focus != nil && p.Focus.CostType == CostTypeBoth {
+			return true
+		}
+		if p.Export != nil && p.Export.CostType == CostTypeBoth {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package config
+
+import "fmt"
+
+// Tenant is an Entra ID tenant with its own credential and subscriptions
+// Subscriptions, scopes and discovery at the top level of the configuration
+// form an implicit tenant that uses the top-level auth settings
+type Tenant struct {
+	ID            string          `yaml:"id"`   // Tenant ID
+	Name          string          `yaml:"name"` // Friendly name (defaults to ID)
+	Auth          AuthConfig      `yaml:"auth"` // Credential for this tenant (tenant_id defaults to ID)
+	Subscriptions []Subscription  `yaml:"subscriptions"`
+	Scopes        []Scope         `yaml:"scopes"`
+	Discovery     DiscoveryConfig `yaml:"discovery"`
+}
+
+// hasTargets reports whether the tenant has anything to query
+func (t Tenant) hasTargets() bool {
+	return len(t.Subscriptions) > 0 || len(t.Scopes) > 0 || t.Discovery.Enabled
+}
+
+// MultiTenant reports whether additional tenants are configured
+// Cost records then carry a tenant_id label
+func (c *Config) MultiTenant() bool {
+	return len(c.Tenants) > 0
+}
+
+// TenantList returns all tenants to query: the implicit top-level tenant (if it
+// has subscriptions, scopes or discovery configured) followed by the tenants section
+func (c *Config) TenantList() []Tenant {
+	var tenants []Tenant
+
+	top := Tenant{
+		ID:            c.Auth.TenantID,
+		Name:          c.Auth.TenantID,
+		Auth:          c.Auth,
+		Subscriptions: c.Subscriptions,
+		Scopes:        c.Scopes,
+		Discovery:     c.Discovery,
+	}
+	if top.hasTargets() {
+		tenants = append(tenants, top)
+	}
+
+	return append(tenants, c.Tenants...)
+}
+
+// applyDiscoveryDefaults sets the default discovery interval and states
+func applyDiscoveryDefaults(d *DiscoveryConfig) {
+	if d.Interval == 0 {
+		d.Interval = DefaultDiscoveryInterval
+	}
+	if len(d.States) == 0 {
+		d.States = DefaultDiscoveryStates
+	}
+}
+
+// applyScopeDefaults names scopes after their ID unless named explicitly
+func applyScopeDefaults(scopes []Scope) {
+	for i := range scopes {
+		if scopes[i].Name == "" {
+			scopes[i].Name = scopes[i].ID
+		}
+	}
+}
+
+// applyTenantDefaults fills in tenant names, credential tenant IDs and nested defaults
+func applyTenantDefaults(tenants []Tenant) {
+	for i := range tenants {
+		t := &tenants[i]
+		if t.Name == "" {
+			t.Name = t.ID
+		}
+		if t.Auth.TenantID == "" {
+			t.Auth.TenantID = t.ID
+		}
+		applyAuthDefaults(&t.Auth)
+		applyDiscoveryDefaults(&t.Discovery)
+		applyScopeDefaults(t.Scopes)
+	}
+}
+
+// validateTargets validates the subscriptions, scopes and discovery settings of a tenant
+func validateTargets(subs []Subscription, scopes []Scope, discovery DiscoveryConfig) error {
+	for i, sub := range subs {
+		if sub.ID == "" {
+			return fmt.Errorf("subscription at index %d has empty ID", i)
+		}
+		// Validate subscription name is not empty (discovery fills in display names)
+		if sub.Name == "" && !discovery.Enabled {
+			return fmt.Errorf("subscription at index %d has empty name", i)
+		}
+	}
+
+	if discovery.Enabled {
+		if err := validateDiscovery(discovery); err != nil {
+			return fmt.Errorf("invalid discovery configuration: %w", err)
+		}
+	}
+
+	for i, scope := range scopes {
+		if err := validateScope(scope); err != nil {
+			return fmt.Errorf("scope at index %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// validateTenants validates the tenants section
+// Top-level targets form a tenant of their own, which then needs an explicit
+// auth.tenant_id so its records can be told apart by the tenant_id label
+func validateTenants(cfg *Config) error {
+	if len(cfg.Tenants) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(cfg.Tenants)+1)
+	if top := cfg.TenantList(); len(top) > len(cfg.Tenants) {
+		if cfg.Auth.TenantID == "" {
+			return fmt.Errorf("auth.tenant_id is required for top-level subscriptions and scopes when tenants are configured")
+		}
+		seen[cfg.Auth.TenantID] = true
+	}
+
+	for i, t := range cfg.Tenants {
+		if t.ID == "" {
+			return fmt.Errorf("tenant at index %d has empty ID", i)
+		}
+		if seen[t.ID] {
+			return fmt.Errorf("tenant %s is configured more than once", t.ID)
+		}
+		seen[t.ID] = true
+
+		if !t.hasTargets() {
+			return fmt.Errorf("tenant %s: no subscriptions or scopes configured and discovery is disabled", t.ID)
+		}
+		if err := validateTargets(t.Subscriptions, t.Scopes, t.Discovery); err != nil {
+			return fmt.Errorf("tenant %s: %w", t.ID, err)
+		}
+		if err := validateAuth(t.Auth); err != nil {
+			return fmt.Errorf("tenant %s: %w", t.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+// Package filecache caches what was read from a set of files, so that
+// repeated scrapes only read the files that are new or have changed.
+package filecache
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache holds the values read from files, by path
+// A file is read again when its size or modification time changes; files that
+// are no longer passed to Load are forgotten. The zero value is ready to use.
+type Cache[T any] struct {
+	mu    sync.Mutex
+	files map[string]entry[T]
+}
+
+// entry is the value of a file together with the state it was read at
+type entry[T any] struct {
+	size    int64
+	modTime time.Time
+	value   T
+}
+
+// ReadFunc returns the value of a file
+// cached is the value stored for the file if the file is unchanged since, so that
+// it can be returned as is or adjusted; it is nil for new and changed files
+type ReadFunc[T any] func(path string, cached *T) (T, error)
+
+// Load returns the values of the given files in order
+// read is called for every file, with the cached value of unchanged files
+func (c *Cache[T]) Load(ctx context.Context, paths []string, read ReadFunc[T]) ([]T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	files := make(map[string]entry[T], len(paths))
+	values := make([]T, 0, len(paths))
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var cached *T
+		if e, ok := c.files[path]; ok && e.size == info.Size() && e.modTime.Equal(info.ModTime()) {
+			cached = &e.value
+		}
+		value, err := read(path, cached)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		files[path] = entry[T]{size: info.Size(), modTime: info.ModTime(), value: value}
+		values = append(values, value)
+	}
+
+	// Files that are no longer passed are dropped together with their values
+	c.files = files
+	return values, nil
+}
+
+// Get returns the cached value of a file
+func (c *Cache[T]) Get(path string) (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.files[path]
+	return e.value, ok
+}
+
+// Len returns the number of cached files
+func (c *Cache[T]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.files)
+}
+
+// Open opens a file for reading
+// Files ending in .gz are decompressed transparently
+func Open(path string) (io.ReadCloser, error) {
+	// #nosec G304 -- Paths are provided by administrator via config file
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return f, nil
+	}
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &gzipFile{Reader: gz, file: f}, nil
+}
+
+// gzipFile closes both the decompressor and the file it reads
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+// Close implements io.Closer
+func (g *gzipFile) Close() error {
+	err := g.Reader.Close()
+	if ferr := g.file.Close(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// CSVHeader reads the header line of a CSV file
+// Column names are trimmed and lower-cased; a leading byte order mark is ignored
+func CSVHeader(r *csv.Reader) ([]string, error) {
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make([]string, len(header))
+	for i, name := range header {
+		columns[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	}
+	return columns, nil
+}
@@ -0,0 +1,155 @@
+package filecache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestCache_Load tests that unchanged files are served from the cache, and
+// changed and removed files are noticed
+func TestCache_Load(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+	writeFile(t, a, "alpha")
+	writeFile(t, b, "beta")
+
+	var cache Cache[string]
+	reads := 0
+	load := func(paths ...string) []string {
+		t.Helper()
+		values, err := cache.Load(context.Background(), paths, func(path string, cached *string) (string, error) {
+			if cached != nil {
+				return *cached, nil
+			}
+			reads++
+			data, err := os.ReadFile(path)
+			return string(data), err
+		})
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		return values
+	}
+
+	if got := load(a, b); !slices.Equal(got, []string{"alpha", "beta"}) || reads != 2 {
+		t.Errorf("Load() = %v after %d reads, want [alpha beta] after 2", got, reads)
+	}
+
+	// Unchanged files are not read again: content of the same size and modification time is not noticed
+	info, err := os.Stat(a)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	writeFile(t, a, "ALPHA")
+	if err := os.Chtimes(a, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+	if got := load(a, b); got[0] != "alpha" || reads != 2 {
+		t.Errorf("Load() = %v after %d reads, want the cached values", got, reads)
+	}
+
+	// Changed files are read again, files no longer passed are forgotten
+	writeFile(t, a, "alpha, changed")
+	if got := load(a); !slices.Equal(got, []string{"alpha, changed"}) || reads != 3 {
+		t.Errorf("Load() = %v after %d reads, want [alpha, changed] after 3", got, reads)
+	}
+	if cache.Len() != 1 {
+		t.Errorf("Cache holds %d files, want 1", cache.Len())
+	}
+	if _, ok := cache.Get(b); ok {
+		t.Error("Removed file should be forgotten")
+	}
+}
+
+func TestCache_LoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	writeFile(t, path, "alpha")
+
+	var cache Cache[string]
+	failing := func(string, *string) (string, error) { return "", io.ErrUnexpectedEOF }
+	if _, err := cache.Load(context.Background(), []string{path}, failing); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("Load() error = %v, want the read error naming the file", err)
+	}
+	if _, err := cache.Load(context.Background(), []string{filepath.Join(dir, "missing.txt")}, failing); err == nil {
+		t.Error("Load() should fail for a missing file")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cache.Load(ctx, []string{path}, failing); err != context.Canceled {
+		t.Errorf("Load() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "export.csv")
+	writeFile(t, plain, "a,b\n")
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte("a,b\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	compressed := filepath.Join(dir, "export.csv.gz")
+	writeFile(t, compressed, buf.String())
+
+	for _, path := range []string{plain, compressed} {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			r, err := Open(path)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			data, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if err := r.Close(); err != nil {
+				t.Errorf("Close() error = %v", err)
+			}
+			if string(data) != "a,b\n" {
+				t.Errorf("Read %q, want %q", data, "a,b\n")
+			}
+		})
+	}
+
+	broken := filepath.Join(dir, "broken.csv.gz")
+	writeFile(t, broken, "not gzip")
+	if _, err := Open(broken); err == nil {
+		t.Error("Open() should fail for a corrupt gzip file")
+	}
+}
+
+func TestCSVHeader(t *testing.T) {
+	reader := csv.NewReader(strings.NewReader("\ufeffDate, CostInBillingCurrency ,ResourceId\n2026-01-15,1.5,x\n"))
+	header, err := CSVHeader(reader)
+	if err != nil {
+		t.Fatalf("CSVHeader() error = %v", err)
+	}
+	if want := []string{"date", "costinbillingcurrency", "resourceid"}; !slices.Equal(header, want) {
+		t.Errorf("CSVHeader() = %v, want %v", header, want)
+	}
+
+	if _, err := CSVHeader(csv.NewReader(strings.NewReader(""))); err == nil {
+		t.Error("CSVHeader() should fail without a header line")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package focus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/clock"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/logger"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// Client reads FOCUS files and implements provider.CloudProvider
+type Client struct {
+	source   Source
+	cfg      *config.Config // Date range, currency fallback, tag and usage settings
+	focusCfg config.FocusConfig
+	logger   *logger.Logger
+	clock    clock.Clock // Time provider for testing
+}
+
+// Verify that Client implements provider.CloudProvider
+var _ provider.CloudProvider = (*Client)(nil)
+
+// NewClient creates a client reading the FOCUS files of the configured path
+func NewClient(cfg *config.Config, focusCfg config.FocusConfig, log *logger.Logger) *Client {
+	return newClient(cfg, focusCfg, log, &FileSource{Path: focusCfg.Path, Format: focusCfg.Format})
+}
+
+// newClient creates a client reading FOCUS rows from source
+func newClient(cfg *config.Config, focusCfg config.FocusConfig, log *logger.Logger, source Source) *Client {
+	return &Client{
+		source:   source,
+		cfg:      cfg,
+		focusCfg: focusCfg,
+		logger:   log,
+		clock:    clock.RealClock{},
+	}
+}
+
+// Name returns the provider type
+func (c *Client) Name() provider.ProviderType {
+	return provider.ProviderFocus
+}
+
+// AccountCount returns the number of data sources read
+func (c *Client) AccountCount() int {
+	return 1
+}
+
+// QueryCosts reads the FOCUS rows of the configured date range
+// Rows are attributed to their sub account (subscription, linked account, project);
+// the health of the data source is reported as a single account named after its path
+func (c *Client) QueryCosts(ctx context.Context) (provider.QueryResult, error) {
+	account := provider.AccountResult{
+		AccountID:   c.focusCfg.Path,
+		AccountName: c.focusCfg.Path,
+	}
+
+	start := time.Now()
+	rows, err := c.source.Rows(ctx)
+	account.Duration = time.Since(start)
+	if err != nil {
+		account.Err = err
+		c.logger.Warn("Failed to read FOCUS files",
+			"path", c.focusCfg.Path,
+			"error", err)
+		return provider.QueryResult{Accounts: []provider.AccountResult{account}}, fmt.Errorf("FOCUS read failed: %w", err)
+	}
+
+	startDate, endDate := c.cfg.DateRange.Days(c.clock.Now())
+	tagKeys := c.cfg.GroupBy.TagKeys()
+	costTypes := c.focusCfg.CostTypes()
+
+	var records []provider.CostRecord
+	skipped := 0
+	for _, row := range rows {
+		if row.ChargePeriodStart.IsZero() {
+			skipped++
+			continue
+		}
+		date, ok := config.DayWithin(row.ChargePeriodStart, startDate, endDate)
+		if !ok {
+			continue
+		}
+		for _, costType := range costTypes {
+			records = append(records, c.rowRecord(row, date, costType, tagKeys))
+		}
+	}
+	if skipped > 0 {
+		c.logger.Debug("Skipped FOCUS rows without ChargePeriodStart", "rows", skipped)
+	}
+
+	account.Rows = len(records)
+	c.logger.Debug("Read FOCUS files",
+		"path", c.focusCfg.Path,
+		"rows", len(rows),
+		"records", len(records),
+		"start_date", startDate.Format(time.DateOnly),
+		"end_date", endDate.Format(time.DateOnly))
+
+	return provider.QueryResult{Records: records, Accounts: []provider.AccountResult{account}}, nil
+}
+
+// rowRecord maps a FOCUS row onto a cost record of the given cost type
+// Actual costs are the BilledCost, amortized costs the EffectiveCost
+func (c *Client) rowRecord(row Row, date, costType string, tagKeys []string) provider.CostRecord {
+	record := provider.CostRecord{
+		Date:             date,
+		Provider:         string(provider.ProviderFocus),
+		AccountID:        row.SubAccountID,
+		AccountName:      row.SubAccountName,
+		Service:          row.ServiceName,
+		Cost:             row.BilledCost,
+		Currency:         row.BillingCurrency,
+		CostType:         costType,
+		ResourceType:     row.ResourceType,
+		ResourceLocation: row.RegionID,
+		ResourceID:       row.ResourceID,
+		ResourceName:     row.ResourceName,
+		ChargeType:       row.ChargeCategory,
+		PricingModel:     row.PricingCategory,
+	}
+	if costType == config.CostTypeAmortized {
+		record.Cost = row.EffectiveCost
+	}
+
+	// Charges without a sub account (taxes, account level purchases) belong to the billing account
+	if record.AccountID == "" {
+		record.AccountID = row.BillingAccountID
+		record.AccountName = row.BillingAccountName
+	}
+	if record.AccountName == "" {
+		record.AccountName = record.AccountID
+	}
+	if record.Service == "" {
+		record.Service = "Unknown"
+	}
+	if record.ResourceLocation == "" {
+		record.ResourceLocation = row.RegionName
+	}
+	if record.Currency == "" {
+		record.Currency = c.cfg.Currency
+	}
+	if c.cfg.Usage.Enabled {
+		record.Meter = row.ChargeDescription
+		record.UnitOfMeasure = row.ConsumedUnit
+		record.UsageQuantity = row.ConsumedQuantity
+	}
+	if len(tagKeys) > 0 {
+		record.Tags = make(map[string]string, len(tagKeys))
+		for _, key := range tagKeys {
+			record.Tags[key] = row.Tags[key]
+		}
+	}
+
+	return record
+}
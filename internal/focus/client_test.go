@@ -0,0 +1,129 @@
+package focus
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/clock"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/logger"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// newTestClient creates a client reading a testdata file as the given cost type
+// It queries 2026-01-14 and 2026-01-15 with usage metrics, grouped by the team tag
+func newTestClient(t *testing.T, file, costType string) *Client {
+	t.Helper()
+	offset := 0
+	cfg := &config.Config{
+		Currency:  "USD",
+		DateRange: config.DateRange{DaysToQuery: 2, EndDateOffset: &offset},
+		GroupBy: config.GroupByConfig{
+			Enabled: true,
+			Groups:  []config.GroupBy{{Type: config.GroupTypeTagKey, Name: "team"}},
+		},
+		Usage: config.UsageConfig{Enabled: true},
+	}
+	focusCfg := config.FocusConfig{Path: filepath.Join("testdata", file), CostType: costType}
+	client := NewClient(cfg, focusCfg, logger.New("error"))
+	client.clock = clock.Fixed(time.Date(2026, 1, 15, 18, 0, 0, 0, time.UTC))
+	return client
+}
+
+func TestQueryCosts(t *testing.T) {
+	client := newTestClient(t, "focus.csv", config.CostTypeActual)
+
+	result, err := client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+
+	// The row of 2026-01-10 is outside the date range
+	vm := "/subscriptions/sub-1/resourcegroups/shop/providers/microsoft.compute/virtualmachines/"
+	want := []provider.CostRecord{
+		{Date: "2026-01-15", AccountID: "sub-1", AccountName: "prod", Service: "Virtual Machines", Cost: 12.5,
+			ResourceLocation: "westeurope", ResourceID: vm + "web-1", ChargeType: "Usage", PricingModel: "Standard",
+			Meter: "D2s v3 running in West Europe", UnitOfMeasure: "Hours", UsageQuantity: 24, Tags: map[string]string{"team": "checkout"}},
+		{Date: "2026-01-15", AccountID: "sub-1", AccountName: "prod", Service: "Virtual Machines", Cost: 0,
+			ResourceLocation: "westeurope", ResourceID: vm + "db-1", ChargeType: "Usage", PricingModel: "Committed",
+			Meter: "D4s v3 running in West Europe", UnitOfMeasure: "Hours", UsageQuantity: 24, Tags: map[string]string{"team": ""}},
+		{Date: "2026-01-14", AccountID: "sub-1", AccountName: "prod", Service: "Virtual Machines", Cost: 100,
+			ChargeType: "Purchase", PricingModel: "Committed", Meter: "Reserved VM instance", Tags: map[string]string{"team": ""}},
+		{Date: "2026-01-15", AccountID: "ba-1", AccountName: "Contoso", Service: "Unknown", Cost: 2,
+			ChargeType: "Tax", Meter: "VAT", Tags: map[string]string{"team": ""}},
+	}
+
+	if len(result.Records) != len(want) {
+		t.Fatalf("Got %d records, want %d: %+v", len(result.Records), len(want), result.Records)
+	}
+	for i, got := range result.Records {
+		w := want[i]
+		if got.Provider != "focus" || got.Currency != "EUR" || got.CostType != config.CostTypeActual {
+			t.Errorf("Record %d: provider/currency/cost type = %s/%s/%s", i, got.Provider, got.Currency, got.CostType)
+		}
+		if got.Date != w.Date || got.AccountID != w.AccountID || got.AccountName != w.AccountName ||
+			got.Service != w.Service || got.Cost != w.Cost || got.ResourceLocation != w.ResourceLocation ||
+			got.ResourceID != w.ResourceID || got.ChargeType != w.ChargeType || got.PricingModel != w.PricingModel ||
+			got.Meter != w.Meter || got.UnitOfMeasure != w.UnitOfMeasure || got.UsageQuantity != w.UsageQuantity ||
+			got.Tags["team"] != w.Tags["team"] {
+			t.Errorf("Record %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	if len(result.Accounts) != 1 {
+		t.Fatalf("Got %d account results, want 1", len(result.Accounts))
+	}
+	account := result.Accounts[0]
+	if account.AccountID != client.focusCfg.Path || account.Err != nil || account.Rows != 4 {
+		t.Errorf("Account result = %+v", account)
+	}
+}
+
+// TestQueryCosts_CostTypes tests that amortized costs come from EffectiveCost
+// and that both cost types yield a record each
+func TestQueryCosts_CostTypes(t *testing.T) {
+	result, err := newTestClient(t, "focus.parquet", config.CostTypeBoth).QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+
+	if len(result.Records) != 6 {
+		t.Fatalf("Got %d records, want 6: %+v", len(result.Records), result.Records)
+	}
+	wantCosts := []struct {
+		costType string
+		cost     float64
+	}{
+		{config.CostTypeActual, 4.25}, {config.CostTypeAmortized, 4.25},
+		{config.CostTypeActual, 0}, {config.CostTypeAmortized, 1.875},
+		{config.CostTypeActual, 1.5}, {config.CostTypeAmortized, 1.5},
+	}
+	for i, w := range wantCosts {
+		got := result.Records[i]
+		if got.CostType != w.costType || got.Cost != w.cost {
+			t.Errorf("Record %d: cost type/cost = %s/%v, want %s/%v", i, got.CostType, got.Cost, w.costType, w.cost)
+		}
+	}
+
+	storage, tax := result.Records[0], result.Records[4]
+	if storage.AccountID != "210987654321" || storage.AccountName != "shop" || storage.Currency != "USD" || storage.Tags["team"] != "checkout" {
+		t.Errorf("Storage record = %+v", storage)
+	}
+	if tax.AccountID != "123456789012" || tax.AccountName != "123456789012" || tax.Service != "Tax" {
+		t.Errorf("Tax record = %+v", tax)
+	}
+}
+
+func TestQueryCosts_SourceError(t *testing.T) {
+	client := newTestClient(t, "missing.csv", config.CostTypeActual)
+
+	result, err := client.QueryCosts(context.Background())
+	if err == nil {
+		t.Fatal("QueryCosts() error = nil, want source error")
+	}
+	if len(result.Accounts) != 1 || result.Accounts[0].Err == nil {
+		t.Errorf("Account results = %+v, want one failed account", result.Accounts)
+	}
+}
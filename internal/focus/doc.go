@@ -0,0 +1,25 @@
+// Package focus provides the FOCUS file cost provider.
+//
+// FOCUS (FinOps Open Cost and Usage Specification) is a vendor-neutral schema
+// for billing data; Azure, AWS, GCP and a growing number of SaaS vendors can
+// export their costs in it. The Client reads FOCUS rows from a Source;
+// FileSource reads CSV (optionally gzip-compressed) and Parquet files from a
+// file, a directory or a glob pattern. Files are cached, so every refresh only
+// reads the files added or changed since the last one.
+//
+// Rows of the configured date range are mapped onto provider.CostRecord:
+//   - ChargePeriodStart becomes the date
+//   - BilledCost becomes the actual and EffectiveCost the amortized cost
+//   - SubAccountId and SubAccountName become the account; rows without a sub
+//     account belong to the billing account
+//   - ServiceName becomes the service and RegionId (or RegionName) the location
+//   - ChargeCategory becomes the charge type and PricingCategory the pricing model
+//   - Tags fill the configured TagKey groupings
+//
+// Example usage:
+//
+//	focusCfg := config.FocusConfig{Path: "/data/focus", CostType: config.CostTypeBoth}
+//
+//	client := focus.NewClient(cfg, focusCfg, log)
+//	result, err := client.QueryCosts(ctx)
+package focus
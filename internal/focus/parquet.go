@@ -0,0 +1,204 @@
+package focus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/deprecated"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// parquetBatchSize is the number of rows read from a parquet file at once
+const parquetBatchSize = 1024
+
+// julianUnixEpoch is the Julian day of 1970-01-01, the epoch of INT96 timestamps
+const julianUnixEpoch = 2440588
+
+// parquetColumn describes a leaf column of a parquet file
+type parquetColumn struct {
+	name    string              // Lower case name of the top-level column
+	leaf    string              // Lower case name of the leaf (key or value for map columns)
+	logical *format.LogicalType // nil for plain physical types
+}
+
+// readParquet reads the rows of a parquet file
+// Tags may be stored as a map column or as JSON text
+func readParquet(r io.ReaderAt, size int64) ([]Row, error) {
+	file, err := parquet.OpenFile(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := file.Schema()
+	paths := schema.Columns()
+	columns := make([]parquetColumn, len(paths))
+	for _, path := range paths {
+		leaf, ok := schema.Lookup(path...)
+		if !ok {
+			continue
+		}
+		columns[leaf.ColumnIndex] = parquetColumn{
+			name:    strings.ToLower(path[0]),
+			leaf:    strings.ToLower(path[len(path)-1]),
+			logical: leaf.Node.Type().LogicalType(),
+		}
+	}
+
+	reader := parquet.NewReader(file)
+	defer reader.Close()
+
+	rows := make([]Row, 0, file.NumRows())
+	buf := make([]parquet.Row, parquetBatchSize)
+	for {
+		n, err := reader.ReadRows(buf)
+		for _, values := range buf[:n] {
+			row, rowErr := parseParquetRow(values, columns)
+			if rowErr != nil {
+				return nil, fmt.Errorf("row %d: %w", len(rows)+1, rowErr)
+			}
+			rows = append(rows, row)
+		}
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseParquetRow builds a row from the values of a parquet row
+func parseParquetRow(values parquet.Row, columns []parquetColumn) (Row, error) {
+	fields := make(map[string]string)
+	var tagKeys, tagValues []parquet.Value
+	var tagMap bool
+
+	for _, v := range values {
+		col := columns[v.Column()]
+		if col.name == columnTags && col.leaf != columnTags {
+			// Map column: keys and values are separate leaves with one entry per tag
+			tagMap = true
+			switch col.leaf {
+			case "key":
+				tagKeys = append(tagKeys, v)
+			case "value":
+				tagValues = append(tagValues, v)
+			}
+			continue
+		}
+		if v.IsNull() {
+			continue
+		}
+		value, err := formatValue(v, col.logical)
+		if err != nil {
+			return Row{}, fmt.Errorf("column %s: %w", col.name, err)
+		}
+		fields[col.name] = value
+	}
+
+	var tags map[string]string
+	if tagMap {
+		tags = make(map[string]string, len(tagKeys))
+		for i, key := range tagKeys {
+			if key.IsNull() {
+				continue // Empty map
+			}
+			value := ""
+			if i < len(tagValues) && !tagValues[i].IsNull() {
+				value = tagValues[i].String()
+			}
+			tags[key.String()] = value
+		}
+	}
+
+	return parseRow(func(column string) string { return fields[column] }, tags)
+}
+
+// formatValue formats a parquet value as the text parseRow expects
+// Timestamps become RFC 3339 and decimals floating point numbers
+func formatValue(v parquet.Value, logical *format.LogicalType) (string, error) {
+	switch {
+	case logical != nil && logical.Timestamp != nil:
+		t, err := timestampValue(v, logical.Timestamp.Unit)
+		if err != nil {
+			return "", err
+		}
+		return t.Format(time.RFC3339Nano), nil
+	case logical != nil && logical.Date != nil:
+		return time.Unix(int64(v.Int32())*86400, 0).UTC().Format(time.DateOnly), nil
+	case logical != nil && logical.Decimal != nil:
+		return formatDecimal(v, logical.Decimal.Scale)
+	}
+
+	switch v.Kind() {
+	case parquet.Int96:
+		return int96Time(v.Int96()).Format(time.RFC3339Nano), nil
+	case parquet.Float:
+		return strconv.FormatFloat(float64(v.Float()), 'g', -1, 32), nil
+	case parquet.Double:
+		return strconv.FormatFloat(v.Double(), 'g', -1, 64), nil
+	case parquet.Int32:
+		return strconv.FormatInt(int64(v.Int32()), 10), nil
+	case parquet.Int64:
+		return strconv.FormatInt(v.Int64(), 10), nil
+	case parquet.Boolean:
+		return strconv.FormatBool(v.Boolean()), nil
+	default:
+		return string(v.ByteArray()), nil
+	}
+}
+
+// timestampValue converts an INT64 timestamp of the given unit to a time
+func timestampValue(v parquet.Value, unit format.TimeUnit) (time.Time, error) {
+	if v.Kind() == parquet.Int96 {
+		return int96Time(v.Int96()), nil
+	}
+	n := v.Int64()
+	switch {
+	case unit.Millis != nil:
+		return time.UnixMilli(n).UTC(), nil
+	case unit.Micros != nil:
+		return time.UnixMicro(n).UTC(), nil
+	case unit.Nanos != nil:
+		return time.Unix(0, n).UTC(), nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported timestamp unit")
+	}
+}
+
+// int96Time converts a legacy INT96 timestamp (nanoseconds of the day and Julian day) to a time
+func int96Time(i deprecated.Int96) time.Time {
+	nanos := int64(i[1])<<32 | int64(i[0])
+	days := int64(i[2]) - julianUnixEpoch
+	return time.Unix(days*86400, nanos).UTC()
+}
+
+// formatDecimal formats a DECIMAL value of the given scale
+// The unscaled value is an INT32, INT64 or big-endian two's complement byte array
+func formatDecimal(v parquet.Value, scale int32) (string, error) {
+	unscaled := new(big.Int)
+	switch v.Kind() {
+	case parquet.Int32:
+		unscaled.SetInt64(int64(v.Int32()))
+	case parquet.Int64:
+		unscaled.SetInt64(v.Int64())
+	case parquet.ByteArray, parquet.FixedLenByteArray:
+		b := v.ByteArray()
+		unscaled.SetBytes(b)
+		if len(b) > 0 && b[0]&0x80 != 0 {
+			unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+		}
+	default:
+		return "", fmt.Errorf("unsupported decimal type %s", v.Kind())
+	}
+
+	f, _ := new(big.Float).SetInt(unscaled).Float64()
+	return strconv.FormatFloat(f/math.Pow10(int(scale)), 'g', -1, 64), nil
+}
@@ -0,0 +1,153 @@
+package focus
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FOCUS columns mapped onto cost records
+// Column names are matched case-insensitively
+const (
+	columnChargePeriodStart  = "chargeperiodstart"
+	columnBilledCost         = "billedcost"
+	columnEffectiveCost      = "effectivecost"
+	columnBillingCurrency    = "billingcurrency"
+	columnBillingAccountID   = "billingaccountid"
+	columnBillingAccountName = "billingaccountname"
+	columnSubAccountID       = "subaccountid"
+	columnSubAccountName     = "subaccountname"
+	columnServiceName        = "servicename"
+	columnRegionID           = "regionid"
+	columnRegionName         = "regionname"
+	columnChargeCategory     = "chargecategory"
+	columnPricingCategory    = "pricingcategory"
+	columnChargeDescription  = "chargedescription"
+	columnResourceID         = "resourceid"
+	columnResourceName       = "resourcename"
+	columnResourceType       = "resourcetype"
+	columnConsumedQuantity   = "consumedquantity"
+	columnConsumedUnit       = "consumedunit"
+	columnTags               = "tags"
+)
+
+// Row is a charge of a FOCUS dataset
+// Only the columns mapped onto cost records are declared
+type Row struct {
+	ChargePeriodStart  time.Time
+	BilledCost         float64 // Cost as invoiced
+	EffectiveCost      float64 // Cost with commitment purchases amortized over their usage
+	BillingCurrency    string
+	BillingAccountID   string
+	BillingAccountName string
+	SubAccountID       string // Subscription, linked account or project
+	SubAccountName     string
+	ServiceName        string
+	RegionID           string
+	RegionName         string
+	ChargeCategory     string // Usage, Purchase, Tax, Credit or Adjustment
+	PricingCategory    string // Standard, Dynamic, Committed or Other
+	ChargeDescription  string
+	ResourceID         string
+	ResourceName       string
+	ResourceType       string
+	ConsumedQuantity   float64
+	ConsumedUnit       string
+	Tags               map[string]string
+}
+
+// parseRow builds a row from its column values
+// get returns the value of a (lower case) column as text, "" if it is null or missing;
+// tags holds the Tags column if the file stores it as a map, otherwise it is parsed from its JSON text
+func parseRow(get func(column string) string, tags map[string]string) (Row, error) {
+	row := Row{
+		BillingCurrency:    get(columnBillingCurrency),
+		BillingAccountID:   get(columnBillingAccountID),
+		BillingAccountName: get(columnBillingAccountName),
+		SubAccountID:       get(columnSubAccountID),
+		SubAccountName:     get(columnSubAccountName),
+		ServiceName:        get(columnServiceName),
+		RegionID:           get(columnRegionID),
+		RegionName:         get(columnRegionName),
+		ChargeCategory:     get(columnChargeCategory),
+		PricingCategory:    get(columnPricingCategory),
+		ChargeDescription:  get(columnChargeDescription),
+		ResourceID:         get(columnResourceID),
+		ResourceName:       get(columnResourceName),
+		ResourceType:       get(columnResourceType),
+		ConsumedUnit:       get(columnConsumedUnit),
+		Tags:               tags,
+	}
+
+	var err error
+	if row.ChargePeriodStart, err = parseTimestamp(get(columnChargePeriodStart)); err != nil {
+		return Row{}, fmt.Errorf("column ChargePeriodStart: %w", err)
+	}
+
+	numbers := map[string]*float64{
+		columnBilledCost:       &row.BilledCost,
+		columnEffectiveCost:    &row.EffectiveCost,
+		columnConsumedQuantity: &row.ConsumedQuantity,
+	}
+	for column, target := range numbers {
+		if value := get(column); value != "" {
+			if *target, err = strconv.ParseFloat(value, 64); err != nil {
+				return Row{}, fmt.Errorf("column %s: invalid number %q", column, value)
+			}
+		}
+	}
+
+	if row.Tags == nil {
+		if value := get(columnTags); value != "" {
+			if row.Tags, err = parseTags(value); err != nil {
+				return Row{}, fmt.Errorf("column Tags: %w", err)
+			}
+		}
+	}
+
+	return row, nil
+}
+
+// parseTags parses the JSON object of the Tags column
+// Non-string values (numbers, booleans) are kept in their JSON encoding
+func parseTags(value string) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return nil, err
+	}
+	tags := make(map[string]string, len(raw))
+	for key, v := range raw {
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			s = string(v)
+		}
+		tags[key] = s
+	}
+	return tags, nil
+}
+
+// timestampLayouts are the text encodings of ChargePeriodStart seen in FOCUS exports
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	time.DateOnly,
+}
+
+// parseTimestamp parses a FOCUS date/time; values without a zone are UTC
+// Returns the zero time for an empty value
+func parseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+}
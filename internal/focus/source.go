@@ -0,0 +1,175 @@
+package focus
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/filecache"
+)
+
+// Source reads FOCUS rows
+type Source interface {
+	Rows(ctx context.Context) ([]Row, error)
+}
+
+// FileSource reads FOCUS rows from CSV or Parquet files
+// Files are read once and cached; later calls only read files that are new or
+// have changed since, and forget files that were removed. CSV files ending in
+// .gz are decompressed transparently.
+type FileSource struct {
+	Path   string // File, directory (searched recursively) or glob pattern
+	Format string // config.FocusFormatCSV or config.FocusFormatParquet ("" = by file extension)
+
+	files filecache.Cache[[]Row] // Rows of the files read so far, by path
+}
+
+// Verify that FileSource implements Source
+var _ Source = (*FileSource)(nil)
+
+// focusExtensions are the file extensions read from directories, by format
+var focusExtensions = map[string]string{
+	".csv":     config.FocusFormatCSV,
+	".parquet": config.FocusFormatParquet,
+}
+
+// Rows returns the rows of all matching files in lexical file order
+// Only new and changed files are read
+func (s *FileSource) Rows(ctx context.Context) ([]Row, error) {
+	files, err := s.list()
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no FOCUS files found at %s", s.Path)
+	}
+
+	fileRows, err := s.files.Load(ctx, files, func(path string, cached *[]Row) ([]Row, error) {
+		if cached != nil {
+			return *cached, nil
+		}
+		return s.readFile(path)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []Row
+	for _, r := range fileRows {
+		rows = append(rows, r...)
+	}
+	return rows, nil
+}
+
+// list returns the FOCUS files matched by the path
+// A directory matches the FOCUS files anywhere below it
+func (s *FileSource) list() ([]string, error) {
+	info, err := os.Stat(s.Path)
+	if err == nil && info.IsDir() {
+		var files []string
+		err := filepath.WalkDir(s.Path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && fileFormat(d.Name()) != "" {
+				files = append(files, path)
+			}
+			return nil
+		})
+		return files, err
+	}
+	if err == nil {
+		return []string{s.Path}, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	matches, err := filepath.Glob(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// fileFormat returns the format of a file by its extension, or "" if unknown
+func fileFormat(name string) string {
+	return focusExtensions[strings.ToLower(filepath.Ext(strings.TrimSuffix(name, ".gz")))]
+}
+
+// readFile reads the rows of a single FOCUS file
+func (s *FileSource) readFile(path string) ([]Row, error) {
+	format := s.Format
+	if format == "" {
+		format = fileFormat(path)
+	}
+	switch format {
+	case config.FocusFormatParquet:
+		// #nosec G304 -- FOCUS path is provided by administrator via config file
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		return readParquet(f, info.Size())
+	case config.FocusFormatCSV:
+		f, err := filecache.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return readCSV(f)
+	default:
+		return nil, fmt.Errorf("unknown file format, set format to %s or %s", config.FocusFormatCSV, config.FocusFormatParquet)
+	}
+}
+
+// readCSV reads CSV rows with a header line of FOCUS column names
+// A leading byte order mark is ignored
+func readCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	header, err := filecache.CSVHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+
+	var rows []Row
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		get := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+		row, err := parseRow(get, nil)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,188 @@
+package focus
+
+import (
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// copyFixture copies a testdata file into dir, gzip-compressing it if name ends in .gz
+func copyFixture(t *testing.T, fixture, dir, name string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer f.Close()
+
+	if filepath.Ext(name) == ".gz" {
+		gz := gzip.NewWriter(f)
+		if _, err := gz.Write(data); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		return
+	}
+	if _, err := f.Write(data); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+}
+
+func TestFileSource_CSV(t *testing.T) {
+	rows, err := (&FileSource{Path: filepath.Join("testdata", "focus.csv")}).Rows(context.Background())
+	if err != nil {
+		t.Fatalf("Rows() error = %v", err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("Got %d rows, want 5", len(rows))
+	}
+
+	row := rows[0]
+	if row.ServiceName != "Virtual Machines" || row.SubAccountID != "sub-1" || row.RegionID != "westeurope" ||
+		row.ChargeCategory != "Usage" || row.PricingCategory != "Standard" || row.BillingCurrency != "EUR" {
+		t.Errorf("Row = %+v", row)
+	}
+	if !row.ChargePeriodStart.Equal(time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ChargePeriodStart = %v, want 2026-01-15 UTC", row.ChargePeriodStart)
+	}
+	if row.BilledCost != 12.5 || row.ConsumedQuantity != 24 || row.Tags["team"] != "checkout" || row.Tags["env"] != "prod" {
+		t.Errorf("Costs/tags = %v / %v / %v", row.BilledCost, row.ConsumedQuantity, row.Tags)
+	}
+	if rows[1].BilledCost != 0 || rows[1].EffectiveCost != 3.2 || len(rows[1].Tags) != 0 {
+		t.Errorf("Committed row = %+v", rows[1])
+	}
+	if rows[3].SubAccountID != "" || rows[3].Tags != nil {
+		t.Errorf("Tax row = %+v", rows[3])
+	}
+}
+
+// TestFileSource_Parquet tests map tags, decimals, timestamps and null columns of parquet files
+func TestFileSource_Parquet(t *testing.T) {
+	rows, err := (&FileSource{Path: filepath.Join("testdata", "focus.parquet")}).Rows(context.Background())
+	if err != nil {
+		t.Fatalf("Rows() error = %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Got %d rows, want 3", len(rows))
+	}
+
+	row := rows[0]
+	if row.ServiceName != "Amazon Simple Storage Service" || row.SubAccountID != "210987654321" || row.SubAccountName != "shop" ||
+		row.RegionID != "us-east-1" || row.PricingCategory != "Standard" || row.BillingCurrency != "USD" {
+		t.Errorf("Row = %+v", row)
+	}
+	if !row.ChargePeriodStart.Equal(time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ChargePeriodStart = %v, want 2026-01-15 UTC", row.ChargePeriodStart)
+	}
+	if row.BilledCost != 4.25 || row.EffectiveCost != 4.25 || row.ConsumedQuantity != 730 {
+		t.Errorf("Costs = %v / %v / %v, want 4.25 / 4.25 / 730", row.BilledCost, row.EffectiveCost, row.ConsumedQuantity)
+	}
+	if len(row.Tags) != 1 || row.Tags["team"] != "checkout" {
+		t.Errorf("Tags = %v, want team=checkout", row.Tags)
+	}
+	if rows[1].EffectiveCost != 1.875 || len(rows[1].Tags) != 0 {
+		t.Errorf("Committed row = %+v", rows[1])
+	}
+	if rows[2].SubAccountID != "" || rows[2].RegionID != "" || rows[2].ConsumedQuantity != 0 {
+		t.Errorf("Tax row = %+v", rows[2])
+	}
+}
+
+// TestFileSource_Incremental tests that new files are picked up, changed files re-read
+// and removed files forgotten, while unchanged files are served from the cache
+func TestFileSource_Incremental(t *testing.T) {
+	dir := t.TempDir()
+	copyFixture(t, "focus.csv", dir, "2026/01/part-0.csv")
+	source := &FileSource{Path: dir}
+
+	rowCount := func(want int) {
+		t.Helper()
+		rows, err := source.Rows(context.Background())
+		if err != nil {
+			t.Fatalf("Rows() error = %v", err)
+		}
+		if len(rows) != want {
+			t.Errorf("Got %d rows, want %d", len(rows), want)
+		}
+	}
+
+	rowCount(5)
+
+	// New files in new partition folders are picked up
+	copyFixture(t, "focus.parquet", dir, "2026/02/part-0.parquet")
+	copyFixture(t, "focus.csv", dir, "2026/02/part-1.csv.gz")
+	rowCount(13)
+
+	// Unchanged files are not read again: garbage of the same size and modification time is not noticed
+	cached := filepath.Join(dir, "2026", "01", "part-0.csv")
+	info, err := os.Stat(cached)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if err := os.WriteFile(cached, make([]byte, info.Size()), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.Chtimes(cached, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+	rowCount(13)
+
+	// Changed files are read again
+	content := "ChargePeriodStart,BilledCost,ServiceName\n2026-01-15,1,Storage\n"
+	if err := os.WriteFile(cached, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	rowCount(9)
+
+	// Removed files are forgotten
+	if err := os.RemoveAll(filepath.Join(dir, "2026", "02")); err != nil {
+		t.Fatalf("RemoveAll() error = %v", err)
+	}
+	rowCount(1)
+	if source.files.Len() != 1 {
+		t.Errorf("Cache holds %d files, want 1", source.files.Len())
+	}
+}
+
+func TestFileSource_Errors(t *testing.T) {
+	dir := t.TempDir()
+	copyFixture(t, "focus.csv", dir, "export.txt")
+	if err := os.WriteFile(filepath.Join(dir, "broken.parquet"), []byte("not parquet"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.csv"), []byte("ChargePeriodStart,BilledCost\nyesterday,1\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		source *FileSource
+	}{
+		{"no files", &FileSource{Path: filepath.Join(dir, "*.gz")}},
+		{"unknown format", &FileSource{Path: filepath.Join(dir, "export.txt")}},
+		{"invalid parquet", &FileSource{Path: filepath.Join(dir, "broken.parquet")}},
+		{"invalid timestamp", &FileSource{Path: filepath.Join(dir, "bad.csv")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.source.Rows(context.Background()); err == nil {
+				t.Error("Rows() error = nil, want error")
+			}
+		})
+	}
+}
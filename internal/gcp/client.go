@@ -0,0 +1,170 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/clock"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/logger"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// chargeTypeCredit is the charge type of records built from credits
+const chargeTypeCredit = "credit"
+
+// Client reads the GCP billing export and implements provider.CloudProvider
+type Client struct {
+	source Source
+	cfg    *config.Config // Date range, currency fallback, tag and usage settings
+	gcpCfg config.GCPConfig
+	logger *logger.Logger
+	clock  clock.Clock // Time provider for testing
+}
+
+// Verify that Client implements provider.CloudProvider
+var _ provider.CloudProvider = (*Client)(nil)
+
+// NewClient creates a client reading the billing export files of the configured path
+func NewClient(cfg *config.Config, gcpCfg config.GCPConfig, log *logger.Logger) *Client {
+	return newClient(cfg, gcpCfg, log, &FileSource{Path: gcpCfg.Path, Format: gcpCfg.Format})
+}
+
+// newClient creates a client reading billing export rows from source
+func newClient(cfg *config.Config, gcpCfg config.GCPConfig, log *logger.Logger, source Source) *Client {
+	return &Client{
+		source: source,
+		cfg:    cfg,
+		gcpCfg: gcpCfg,
+		logger: log,
+		clock:  clock.RealClock{},
+	}
+}
+
+// Name returns the provider type
+func (c *Client) Name() provider.ProviderType {
+	return provider.ProviderGCP
+}
+
+// AccountCount returns the number of billing accounts read
+func (c *Client) AccountCount() int {
+	return 1
+}
+
+// QueryCosts reads the billing export rows of the configured date range
+// Rows are attributed to their project; each credit becomes a record of its own,
+// so that summing all records yields the net cost
+func (c *Client) QueryCosts(ctx context.Context) (provider.QueryResult, error) {
+	account := provider.AccountResult{
+		AccountID:   c.gcpCfg.BillingAccountID,
+		AccountName: c.gcpCfg.BillingAccountName,
+	}
+
+	startDate, endDate := c.cfg.DateRange.Days(c.clock.Now())
+
+	start := time.Now()
+	rows, err := c.source.Rows(ctx, startDate)
+	account.Duration = time.Since(start)
+	if err != nil {
+		account.Err = err
+		c.logger.Warn("Failed to read GCP billing export",
+			"billing_account_id", account.AccountID,
+			"path", c.gcpCfg.Path,
+			"error", err)
+		return provider.QueryResult{Accounts: []provider.AccountResult{account}}, fmt.Errorf("billing export read failed: %w", err)
+	}
+
+	tagKeys := c.cfg.GroupBy.TagKeys()
+
+	var records []provider.CostRecord
+	skipped := 0
+	for _, row := range rows {
+		if row.UsageStartTime.IsZero() {
+			skipped++
+			continue
+		}
+		date, ok := config.DayWithin(row.UsageStartTime.Time, startDate, endDate)
+		if !ok {
+			continue
+		}
+		records = append(records, c.rowRecords(row, date, tagKeys)...)
+	}
+	if skipped > 0 {
+		c.logger.Debug("Skipped billing export rows without usage_start_time", "rows", skipped)
+	}
+
+	account.Rows = len(records)
+	c.logger.Debug("Read GCP billing export",
+		"path", c.gcpCfg.Path,
+		"rows", len(rows),
+		"records", len(records),
+		"start_date", startDate.Format(time.DateOnly),
+		"end_date", endDate.Format(time.DateOnly))
+
+	return provider.QueryResult{Records: records, Accounts: []provider.AccountResult{account}}, nil
+}
+
+// rowRecords maps a billing export row onto its cost record followed by one record per credit
+func (c *Client) rowRecords(row BillingRow, date string, tagKeys []string) []provider.CostRecord {
+	record := provider.CostRecord{
+		Date:             date,
+		Provider:         string(provider.ProviderGCP),
+		AccountID:        row.Project.ID,
+		AccountName:      row.Project.Name,
+		Service:          row.Service.Description,
+		Cost:             float64(row.Cost),
+		Currency:         row.Currency,
+		CostType:         config.CostTypeActual,
+		ResourceLocation: row.Location.Region,
+		ResourceID:       row.Resource.GlobalName,
+		ResourceName:     row.Resource.Name,
+		ChargeType:       row.CostType,
+		Meter:            row.SKU.Description,
+	}
+
+	// Charges without a project (support, taxes of the account) belong to the billing account
+	if record.AccountID == "" {
+		record.AccountID = row.BillingAccountID
+		if record.AccountID == "" {
+			record.AccountID = c.gcpCfg.BillingAccountID
+		}
+		record.AccountName = c.gcpCfg.BillingAccountName
+	}
+	if record.AccountName == "" {
+		record.AccountName = record.AccountID
+	}
+	if record.Service == "" {
+		record.Service = "Unknown"
+	}
+	if record.ResourceLocation == "" {
+		record.ResourceLocation = row.Location.Location
+	}
+	if record.Currency == "" {
+		record.Currency = c.cfg.Currency
+	}
+	if c.cfg.Usage.Enabled {
+		record.UnitOfMeasure = row.Usage.Unit
+		record.UsageQuantity = float64(row.Usage.Amount)
+	}
+	if len(tagKeys) > 0 {
+		record.Tags = make(map[string]string, len(tagKeys))
+		for _, key := range tagKeys {
+			record.Tags[key] = labelValue(row.Labels, key)
+		}
+	}
+
+	records := make([]provider.CostRecord, 0, 1+len(row.Credits))
+	records = append(records, record)
+
+	for _, credit := range row.Credits {
+		cr := record
+		cr.Cost = float64(credit.Amount)
+		cr.ChargeType = chargeTypeCredit
+		cr.PricingModel = credit.Type
+		cr.UsageQuantity = 0 // The usage is counted by the row's own record
+		records = append(records, cr)
+	}
+
+	return records
+}
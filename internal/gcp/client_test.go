@@ -0,0 +1,139 @@
+package gcp
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/clock"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/logger"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// newTestClient creates a client reading a testdata file
+// It queries 2026-01-14 and 2026-01-15 with usage metrics, grouped by the team label
+func newTestClient(t *testing.T, file string) *Client {
+	t.Helper()
+	offset := 0
+	cfg := &config.Config{
+		Currency:  "USD",
+		DateRange: config.DateRange{DaysToQuery: 2, EndDateOffset: &offset},
+		GroupBy: config.GroupByConfig{
+			Enabled: true,
+			Groups:  []config.GroupBy{{Type: config.GroupTypeTagKey, Name: "team"}},
+		},
+		Usage: config.UsageConfig{Enabled: true},
+	}
+	gcpCfg := config.GCPConfig{
+		BillingAccountID:   "0123AB-CDEF45-678901",
+		BillingAccountName: "main billing",
+		Path:               filepath.Join("testdata", file),
+	}
+	client := NewClient(cfg, gcpCfg, logger.New("error"))
+	client.clock = clock.Fixed(time.Date(2026, 1, 15, 18, 0, 0, 0, time.UTC))
+	return client
+}
+
+func TestQueryCosts(t *testing.T) {
+	client := newTestClient(t, "billing_export.json")
+
+	result, err := client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+
+	// The row of 2026-01-10 is outside the date range
+	want := []provider.CostRecord{
+		{Date: "2026-01-15", AccountID: "shop-prod", AccountName: "Shop Production", Service: "Compute Engine", Cost: 12.5,
+			ResourceLocation: "europe-west3", ChargeType: "regular", Meter: "N1 Predefined Instance Core running in Frankfurt",
+			UnitOfMeasure: "seconds", UsageQuantity: 3600, Tags: map[string]string{"team": "checkout"}},
+		{Date: "2026-01-15", AccountID: "shop-prod", AccountName: "Shop Production", Service: "Compute Engine", Cost: -2.5,
+			ResourceLocation: "europe-west3", ChargeType: "credit", PricingModel: "SUSTAINED_USAGE_DISCOUNT",
+			Meter: "N1 Predefined Instance Core running in Frankfurt", UnitOfMeasure: "seconds", Tags: map[string]string{"team": "checkout"}},
+		{Date: "2026-01-14", AccountID: "shop-prod", AccountName: "Shop Production", Service: "Cloud Storage", Cost: 0.75,
+			ResourceLocation: "europe-west3", ChargeType: "regular", Meter: "Standard Storage Frankfurt",
+			UnitOfMeasure: "gibibyte month", UsageQuantity: 100, Tags: map[string]string{"team": ""}},
+		{Date: "2026-01-15", AccountID: "0123AB-CDEF45-678901", AccountName: "main billing", Service: "Support", Cost: 5,
+			ResourceLocation: "global", ChargeType: "regular", Meter: "Enhanced Support", Tags: map[string]string{"team": ""}},
+	}
+
+	if len(result.Records) != len(want) {
+		t.Fatalf("Got %d records, want %d: %+v", len(result.Records), len(want), result.Records)
+	}
+	for i, got := range result.Records {
+		w := want[i]
+		if got.Provider != "gcp" || got.Currency != "EUR" || got.CostType != config.CostTypeActual {
+			t.Errorf("Record %d: provider/currency/cost type = %s/%s/%s", i, got.Provider, got.Currency, got.CostType)
+		}
+		if got.Date != w.Date || got.AccountID != w.AccountID || got.AccountName != w.AccountName ||
+			got.Service != w.Service || got.Cost != w.Cost || got.ResourceLocation != w.ResourceLocation ||
+			got.ChargeType != w.ChargeType || got.PricingModel != w.PricingModel || got.Meter != w.Meter ||
+			got.UnitOfMeasure != w.UnitOfMeasure || got.UsageQuantity != w.UsageQuantity || got.Tags["team"] != w.Tags["team"] {
+			t.Errorf("Record %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	if len(result.Accounts) != 1 {
+		t.Fatalf("Got %d account results, want 1", len(result.Accounts))
+	}
+	account := result.Accounts[0]
+	if account.AccountID != "0123AB-CDEF45-678901" || account.AccountName != "main billing" || account.Err != nil || account.Rows != 4 {
+		t.Errorf("Account result = %+v", account)
+	}
+}
+
+// TestQueryCosts_CSV tests that CSV extracts map onto the same records as JSON extracts
+func TestQueryCosts_CSV(t *testing.T) {
+	result, err := newTestClient(t, "billing_export.csv").QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+
+	if len(result.Records) != 3 {
+		t.Fatalf("Got %d records, want 3: %+v", len(result.Records), result.Records)
+	}
+	usage, credit, storage := result.Records[0], result.Records[1], result.Records[2]
+	if usage.Cost != 12.5 || usage.Tags["team"] != "checkout" || usage.UsageQuantity != 3600 || usage.Date != "2026-01-15" {
+		t.Errorf("Usage record = %+v", usage)
+	}
+	if credit.Cost != -2.5 || credit.ChargeType != "credit" || credit.PricingModel != "SUSTAINED_USAGE_DISCOUNT" {
+		t.Errorf("Credit record = %+v", credit)
+	}
+	if storage.Service != "Cloud Storage" || storage.Date != "2026-01-14" || storage.AccountName != "Shop Production" {
+		t.Errorf("Storage record = %+v", storage)
+	}
+}
+
+func TestQueryCosts_SourceError(t *testing.T) {
+	client := newTestClient(t, "missing.json")
+
+	result, err := client.QueryCosts(context.Background())
+	if err == nil {
+		t.Fatal("QueryCosts() error = nil, want source error")
+	}
+	if len(result.Accounts) != 1 || result.Accounts[0].Err == nil {
+		t.Errorf("Account results = %+v, want one failed account", result.Accounts)
+	}
+}
+
+// TestQueryCosts_UsageDisabled tests that the SKU fills the meter of every record
+// while the usage unit and quantity are only read with usage metrics enabled
+func TestQueryCosts_UsageDisabled(t *testing.T) {
+	client := newTestClient(t, "billing_export.json")
+	client.cfg.Usage.Enabled = false
+
+	result, err := client.QueryCosts(context.Background())
+	if err != nil {
+		t.Fatalf("QueryCosts() error = %v", err)
+	}
+	if len(result.Records) == 0 {
+		t.Fatal("Got no records")
+	}
+
+	usage := result.Records[0]
+	if usage.Meter != "N1 Predefined Instance Core running in Frankfurt" || usage.UnitOfMeasure != "" || usage.UsageQuantity != 0 {
+		t.Errorf("Record = %+v, want meter without usage", usage)
+	}
+}
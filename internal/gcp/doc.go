@@ -0,0 +1,26 @@
+// Package gcp provides the GCP billing export cost provider.
+//
+// GCP publishes billing data through the BigQuery billing export. The Client
+// reads rows in that schema from a Source; FileSource reads tables extracted
+// as newline-delimited JSON or CSV (optionally gzip-compressed), so neither the
+// exporter nor its tests need BigQuery access.
+//
+// Rows of the configured date range are mapped onto provider.CostRecord:
+//   - project.id and project.name become the account; rows without a project
+//     (support, account level charges) belong to the billing account
+//   - service.description becomes the service and sku.description the meter
+//   - location.region (or location.location) becomes the resource location
+//   - labels fill the configured TagKey groupings
+//   - cost_type (regular, tax, adjustment, rounding_error) becomes the charge type
+//
+// Each credit is exported as an additional record with charge type "credit"
+// and the credit type as pricing model. Credits are negative, so the sum of
+// all records is the net cost shown in the billing console.
+//
+// Example usage:
+//
+//	gcpCfg := config.GCPConfig{BillingAccountID: "0123AB-CDEF45-678901", Path: "/data/billing/*.json.gz"}
+//
+//	client := gcp.NewClient(cfg, gcpCfg, log)
+//	result, err := client.QueryCosts(ctx)
+package gcp
@@ -0,0 +1,148 @@
+package gcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BillingRow is a row of the BigQuery billing export (standard or detailed usage cost)
+// Only the columns mapped onto cost records are declared
+type BillingRow struct {
+	BillingAccountID string    `json:"billing_account_id"`
+	Service          Described `json:"service"`
+	SKU              Described `json:"sku"`
+	UsageStartTime   Timestamp `json:"usage_start_time"`
+	Project          Project   `json:"project"`
+	Labels           []Label   `json:"labels"`
+	Location         Location  `json:"location"`
+	Resource         Resource  `json:"resource"` // Detailed export only
+	Cost             Number    `json:"cost"`
+	Currency         string    `json:"currency"`
+	CostType         string    `json:"cost_type"` // regular, tax, adjustment or rounding_error
+	Usage            Usage     `json:"usage"`
+	Credits          []Credit  `json:"credits"`
+}
+
+// Described is a record with an ID and a human-readable description (service, sku)
+type Described struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+}
+
+// Project identifies the project a cost was incurred in
+type Project struct {
+	ID     string  `json:"id"`
+	Number string  `json:"number"`
+	Name   string  `json:"name"`
+	Labels []Label `json:"labels"`
+}
+
+// Label is a key/value label of a resource or project
+type Label struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Location is where a cost was incurred
+type Location struct {
+	Location string `json:"location"`
+	Country  string `json:"country"`
+	Region   string `json:"region"`
+	Zone     string `json:"zone"`
+}
+
+// Resource identifies the resource of a detailed export row
+type Resource struct {
+	Name       string `json:"name"`
+	GlobalName string `json:"global_name"`
+}
+
+// Usage is the consumed quantity of a row
+type Usage struct {
+	Amount Number `json:"amount"`
+	Unit   string `json:"unit"`
+}
+
+// Credit is a credit (discount, promotion, free tier) applied to a row
+// Amounts are negative
+type Credit struct {
+	Name     string `json:"name"`
+	Amount   Number `json:"amount"`
+	FullName string `json:"full_name"`
+	ID       string `json:"id"`
+	Type     string `json:"type"` // e.g. COMMITTED_USAGE_DISCOUNT, SUSTAINED_USAGE_DISCOUNT, FREE_TIER, PROMOTION
+}
+
+// Number is a numeric column; BigQuery extracts write it as a JSON number or string
+type Number float64
+
+// UnmarshalJSON implements json.Unmarshaler
+func (n *Number) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*n = 0
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %s: %w", data, err)
+	}
+	*n = Number(v)
+	return nil
+}
+
+// timestampLayouts are the timestamp formats written by BigQuery extracts and queries
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999 MST",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05.999999-07:00",
+	"2006-01-02 15:04:05-07:00",
+	"2006-01-02 15:04:05",
+	time.DateOnly,
+}
+
+// Timestamp is a timestamp column
+type Timestamp struct {
+	time.Time
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid timestamp %s: %w", data, err)
+	}
+	parsed, err := parseTimestamp(s)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+// parseTimestamp parses a timestamp in any of the BigQuery layouts
+func parseTimestamp(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+}
+
+// labelValue returns the value of a label, or "" if absent
+func labelValue(labels []Label, key string) string {
+	for _, l := range labels {
+		if l.Key == key {
+			return l.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,270 @@
+package gcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/filecache"
+)
+
+// Source reads billing export rows
+// Implementations may read a BigQuery table directly; FileSource reads extracted files
+type Source interface {
+	// Rows returns the rows of usage starting on or after since, and the rows without usage_start_time
+	Rows(ctx context.Context, since time.Time) ([]BillingRow, error)
+}
+
+// FileSource reads billing export rows from newline-delimited JSON or CSV files
+// Files are read once and cached; later calls only read files that are new or
+// have changed since, and forget files that were removed. Rows before the
+// requested start are dropped while reading. Files ending in .gz are
+// decompressed transparently.
+type FileSource struct {
+	Path   string // File, directory or glob pattern
+	Format string // config.GCPFormatJSON or config.GCPFormatCSV ("" = by file extension)
+
+	files filecache.Cache[cachedRows] // Rows of the files read so far, by path
+}
+
+// cachedRows is the rows of a file read for a start
+type cachedRows struct {
+	since time.Time // Rows before since were dropped
+	rows  []BillingRow
+}
+
+// Verify that FileSource implements Source
+var _ Source = (*FileSource)(nil)
+
+// exportExtensions are the file extensions read from directories, by format
+var exportExtensions = map[string]string{
+	".json":   config.GCPFormatJSON,
+	".ndjson": config.GCPFormatJSON,
+	".jsonl":  config.GCPFormatJSON,
+	".csv":    config.GCPFormatCSV,
+}
+
+// Rows returns the rows of all matching files in lexical file order
+// Only new and changed files are read, and files read for a later start than since
+func (s *FileSource) Rows(ctx context.Context, since time.Time) ([]BillingRow, error) {
+	files, err := s.list()
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no billing export files found at %s", s.Path)
+	}
+
+	fileRows, err := s.files.Load(ctx, files, func(path string, cached *cachedRows) (cachedRows, error) {
+		switch {
+		case cached == nil || since.Before(cached.since):
+			rows, err := s.readFile(path, since)
+			return cachedRows{since: since, rows: rows}, err
+		case since.After(cached.since):
+			// The date range moved on: rows that left it are dropped from the cache
+			rows := slices.DeleteFunc(cached.rows, func(row BillingRow) bool { return !keepRow(row, since) })
+			return cachedRows{since: since, rows: rows}, nil
+		default:
+			return *cached, nil
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []BillingRow
+	for _, r := range fileRows {
+		rows = append(rows, r.rows...)
+	}
+	return rows, nil
+}
+
+// keepRow reports whether a row is read for the given start
+// Rows without usage_start_time are kept, so the client can report them
+func keepRow(row BillingRow, since time.Time) bool {
+	return row.UsageStartTime.IsZero() || !row.UsageStartTime.Before(since)
+}
+
+// list returns the export files matched by the path
+// A directory matches the export files directly inside it
+func (s *FileSource) list() ([]string, error) {
+	info, err := os.Stat(s.Path)
+	if err == nil && info.IsDir() {
+		entries, err := os.ReadDir(s.Path)
+		if err != nil {
+			return nil, err
+		}
+		var files []string
+		for _, e := range entries {
+			if !e.IsDir() && fileFormat(e.Name()) != "" {
+				files = append(files, filepath.Join(s.Path, e.Name()))
+			}
+		}
+		return files, nil
+	}
+	if err == nil {
+		return []string{s.Path}, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	matches, err := filepath.Glob(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// fileFormat returns the format of a file by its extension, or "" if unknown
+func fileFormat(name string) string {
+	return exportExtensions[strings.ToLower(filepath.Ext(strings.TrimSuffix(name, ".gz")))]
+}
+
+// readFile reads the rows of a single export file, dropping rows before since
+func (s *FileSource) readFile(path string, since time.Time) ([]BillingRow, error) {
+	r, err := filecache.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	format := s.Format
+	if format == "" {
+		format = fileFormat(path)
+	}
+	switch format {
+	case config.GCPFormatJSON:
+		return readJSON(r, since)
+	case config.GCPFormatCSV:
+		return readCSV(r, since)
+	default:
+		return nil, fmt.Errorf("unknown file format, set format to %s or %s", config.GCPFormatJSON, config.GCPFormatCSV)
+	}
+}
+
+// readJSON reads newline-delimited JSON rows, dropping rows before since
+func readJSON(r io.Reader, since time.Time) ([]BillingRow, error) {
+	var rows []BillingRow
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := strings.TrimSpace(scanner.Text())
+		if data == "" {
+			continue
+		}
+		var row BillingRow
+		if err := json.Unmarshal([]byte(data), &row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if keepRow(row, since) {
+			rows = append(rows, row)
+		}
+	}
+	return rows, scanner.Err()
+}
+
+// readCSV reads CSV rows with a header line, dropping rows before since
+// Nested columns are flattened (service.description or service_description);
+// repeated columns (labels, project.labels, credits) hold their JSON encoding
+func readCSV(r io.Reader, since time.Time) ([]BillingRow, error) {
+	reader := csv.NewReader(r)
+	header, err := filecache.CSVHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[normalizeColumn(name)] = i
+	}
+
+	var rows []BillingRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		row, err := parseCSVRow(record, columns)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if keepRow(row, since) {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// normalizeColumn maps a CSV column name onto its flattened underscore form
+func normalizeColumn(name string) string {
+	return strings.ReplaceAll(name, ".", "_")
+}
+
+// parseCSVRow builds a billing row from a CSV record
+func parseCSVRow(record []string, columns map[string]int) (BillingRow, error) {
+	get := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	row := BillingRow{
+		BillingAccountID: get("billing_account_id"),
+		Service:          Described{ID: get("service_id"), Description: get("service_description")},
+		SKU:              Described{ID: get("sku_id"), Description: get("sku_description")},
+		Project:          Project{ID: get("project_id"), Number: get("project_number"), Name: get("project_name")},
+		Location: Location{
+			Location: get("location_location"),
+			Country:  get("location_country"),
+			Region:   get("location_region"),
+			Zone:     get("location_zone"),
+		},
+		Resource: Resource{Name: get("resource_name"), GlobalName: get("resource_global_name")},
+		Currency: get("currency"),
+		CostType: get("cost_type"),
+		Usage:    Usage{Unit: get("usage_unit")},
+	}
+
+	var err error
+	if row.UsageStartTime.Time, err = parseTimestamp(get("usage_start_time")); err != nil {
+		return BillingRow{}, err
+	}
+
+	numbers := map[string]*Number{"cost": &row.Cost, "usage_amount": &row.Usage.Amount}
+	for name, target := range numbers {
+		if value := get(name); value != "" {
+			if err := target.UnmarshalJSON([]byte(value)); err != nil {
+				return BillingRow{}, fmt.Errorf("column %s: %w", name, err)
+			}
+		}
+	}
+
+	repeated := map[string]any{"labels": &row.Labels, "project_labels": &row.Project.Labels, "credits": &row.Credits}
+	for name, target := range repeated {
+		if value := get(name); value != "" {
+			if err := json.Unmarshal([]byte(value), target); err != nil {
+				return BillingRow{}, fmt.Errorf("column %s: %w", name, err)
+			}
+		}
+	}
+
+	return row, nil
+}
@@ -0,0 +1,179 @@
+package gcp
+
+import (
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// copyFixture copies a testdata file into dir, gzip-compressing it if name ends in .gz
+func copyFixture(t *testing.T, fixture, dir, name string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer f.Close()
+
+	if filepath.Ext(name) == ".gz" {
+		gz := gzip.NewWriter(f)
+		if _, err := gz.Write(data); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		return
+	}
+	if _, err := f.Write(data); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+}
+
+func TestFileSource_Rows(t *testing.T) {
+	rows, err := (&FileSource{Path: filepath.Join("testdata", "billing_export.json")}).Rows(context.Background(), time.Time{})
+	if err != nil {
+		t.Fatalf("Rows() error = %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("Got %d rows, want 4 (blank lines skipped)", len(rows))
+	}
+
+	row := rows[0]
+	if row.Service.Description != "Compute Engine" || row.Project.ID != "shop-prod" || row.Location.Zone != "europe-west3-a" {
+		t.Errorf("Row = %+v", row)
+	}
+	if !row.UsageStartTime.Equal(time.Date(2026, 1, 15, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("UsageStartTime = %v, want 2026-01-15 08:00 UTC", row.UsageStartTime)
+	}
+	if row.Usage.Amount != 3600 || len(row.Credits) != 1 || row.Credits[0].Amount != -2.5 {
+		t.Errorf("Usage/credits = %+v / %+v", row.Usage, row.Credits)
+	}
+	if labelValue(row.Project.Labels, "env") != "prod" || labelValue(row.Labels, "team") != "checkout" {
+		t.Errorf("Labels = %+v / %+v", row.Labels, row.Project.Labels)
+	}
+	if rows[1].Cost != 0.75 {
+		t.Errorf("String cost = %v, want 0.75", rows[1].Cost)
+	}
+}
+
+// TestFileSource_DirectoryAndGlob tests that directories and globs match all export files
+func TestFileSource_DirectoryAndGlob(t *testing.T) {
+	dir := t.TempDir()
+	copyFixture(t, "billing_export.json", dir, "export-000.json.gz")
+	copyFixture(t, "billing_export.csv", dir, "export-001.csv")
+	copyFixture(t, "billing_export.csv", dir, "README.txt")
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"directory", dir, 6},
+		{"glob", filepath.Join(dir, "export-*.json.gz"), 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := (&FileSource{Path: tt.path}).Rows(context.Background(), time.Time{})
+			if err != nil {
+				t.Fatalf("Rows() error = %v", err)
+			}
+			if len(rows) != tt.want {
+				t.Errorf("Got %d rows, want %d", len(rows), tt.want)
+			}
+		})
+	}
+}
+
+// TestFileSource_Incremental tests that unchanged files are served from the cache, changed
+// and removed files are noticed, and rows before the start are dropped while reading
+func TestFileSource_Incremental(t *testing.T) {
+	dir := t.TempDir()
+	copyFixture(t, "billing_export.json", dir, "export-000.json")
+	source := &FileSource{Path: dir}
+	jan14 := time.Date(2026, 1, 14, 0, 0, 0, 0, time.UTC)
+	jan15 := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	rowCount := func(since time.Time, want int) {
+		t.Helper()
+		rows, err := source.Rows(context.Background(), since)
+		if err != nil {
+			t.Fatalf("Rows() error = %v", err)
+		}
+		if len(rows) != want {
+			t.Errorf("Got %d rows, want %d", len(rows), want)
+		}
+	}
+
+	// The row of 2026-01-10 is dropped while reading
+	rowCount(jan14, 3)
+
+	// Unchanged files are not read again: garbage of the same size and modification time is not noticed
+	cached := filepath.Join(dir, "export-000.json")
+	info, err := os.Stat(cached)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if err := os.WriteFile(cached, make([]byte, info.Size()), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.Chtimes(cached, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+	rowCount(jan14, 3)
+
+	// A later start drops the cached rows that left the date range
+	rowCount(jan15, 2)
+	if got, _ := source.files.Get(cached); len(got.rows) != 2 {
+		t.Errorf("Cache holds %d rows, want 2", len(got.rows))
+	}
+
+	// An earlier start reads the file again
+	copyFixture(t, "billing_export.json", dir, "export-000.json")
+	if err := os.Chtimes(cached, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+	rowCount(time.Time{}, 4)
+
+	// Changed files are read again, removed files are forgotten
+	copyFixture(t, "billing_export.csv", dir, "export-001.csv")
+	rowCount(jan14, 5)
+	if err := os.Remove(cached); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	rowCount(jan14, 2)
+	if source.files.Len() != 1 {
+		t.Errorf("Cache holds %d files, want 1", source.files.Len())
+	}
+}
+
+func TestFileSource_Errors(t *testing.T) {
+	dir := t.TempDir()
+	copyFixture(t, "billing_export.json", dir, "export.txt")
+
+	tests := []struct {
+		name   string
+		source *FileSource
+	}{
+		{"no files", &FileSource{Path: filepath.Join(dir, "*.csv")}},
+		{"unknown format", &FileSource{Path: filepath.Join(dir, "export.txt")}},
+		{"wrong format", &FileSource{Path: filepath.Join(dir, "export.txt"), Format: "csv"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.source.Rows(context.Background(), time.Time{}); err == nil {
+				t.Error("Rows() error = nil, want error")
+			}
+		})
+	}
+}
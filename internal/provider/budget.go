@@ -0,0 +1,41 @@
+package provider
+
+import "context"
+
+// BudgetReader is an optional capability of providers that manage budgets
+type BudgetReader interface {
+	// QueryBudgets lists the budgets defined on all monitored accounts and scopes
+	// The result carries the outcome of every queried account, also when an error is returned
+	QueryBudgets(ctx context.Context) (BudgetResult, error)
+}
+
+// BudgetResult is the outcome of listing the budgets of all accounts of a provider
+type BudgetResult struct {
+	Budgets  []Budget        // Budgets of all successful accounts
+	Accounts []AccountResult // Per-account outcomes, in query order
+}
+
+// Budget is a spending limit defined in the cloud provider
+type Budget struct {
+	Provider    string
+	AccountID   string
+	AccountName string
+	Scope       string // Scope the budget is defined on
+	Name        string
+	TimeGrain   string // Period the amount applies to (e.g. Monthly, Quarterly)
+	Currency    string
+
+	Amount        float64
+	CurrentSpend  float64
+	ForecastSpend *float64 // nil if the provider has no forecast for the budget
+
+	Notifications []BudgetNotification
+}
+
+// BudgetNotification is an alert threshold of a budget
+type BudgetNotification struct {
+	Name          string
+	Threshold     float64 // Percentage of the budget amount
+	Operator      string  // Comparison against the threshold (e.g. GreaterThan)
+	ThresholdType string  // Spend compared against the threshold (Actual or Forecasted)
+}
@@ -0,0 +1,40 @@
+package provider
+
+import "context"
+
+// Kinds of commitment discounts
+const (
+	CommitmentKindReservation = "reservation"
+	CommitmentKindSavingsPlan = "savings_plan"
+)
+
+// UtilizationReader is an optional capability of providers that sell commitment
+// discounts (reservations, savings plans) and report how well they are used
+type UtilizationReader interface {
+	// QueryUtilization retrieves the daily utilization of all commitments of the monitored billing scopes
+	// The result carries the outcome of every queried account, also when an error is returned
+	QueryUtilization(ctx context.Context) (UtilizationResult, error)
+}
+
+// UtilizationResult is the outcome of querying the commitment utilization of all billing scopes of a provider
+type UtilizationResult struct {
+	Commitments []CommitmentUtilization // Utilization of all successful billing scopes
+	Accounts    []AccountResult         // Per-scope outcomes, in query order
+}
+
+// CommitmentUtilization is the utilization of one commitment order and SKU on a single day
+type CommitmentUtilization struct {
+	Provider    string
+	AccountID   string // Billing scope the commitment was reported for
+	AccountName string
+	Kind        string // One of the CommitmentKind* constants
+	OrderID     string // Reservation order or savings plan order
+	SKU         string
+	Date        string // YYYY-MM-DD format
+
+	UtilizationPercent float64  // Share of the commitment that was used
+	ReservedHours      *float64 // Hours covered by the commitment (nil if not reported in hours)
+	UsedHours          *float64 // Hours the commitment was applied to (nil if not reported in hours)
+	UnusedCost         float64  // Cost of the unused part of the commitment
+	Currency           string
+}
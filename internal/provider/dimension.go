@@ -0,0 +1,130 @@
+package provider
+
+import "strings"
+
+// Dimension describes a cost grouping dimension and the CostRecord field it populates
+type Dimension struct {
+	Name    string                  // Canonical dimension name sent to the cloud API (e.g. ResourceGroup)
+	Label   string                  // Default Prometheus label name
+	Aliases []string                // Alternate names accepted in configuration
+	Value   func(CostRecord) string // Extracts the dimension value from a record
+	Source  string                  // Dimension queried instead of Name when the value is derived from it
+}
+
+// QueryName returns the dimension name sent to the cloud API
+// Derived dimensions are queried by the dimension they are derived from
+func (d Dimension) QueryName() string {
+	if d.Source != "" {
+		return d.Source
+	}
+	return d.Name
+}
+
+// Derived reports whether the dimension's value is derived from another dimension
+// The cloud APIs don't know derived dimensions, so they can't be filtered on server-side
+func (d Dimension) Derived() bool {
+	return d.Source != ""
+}
+
+// dimensions is the registry of supported grouping dimensions
+var dimensions = []Dimension{
+	{
+		Name:  "ServiceName",
+		Label: "service_name",
+		Value: func(r CostRecord) string { return r.Service },
+	},
+	{
+		Name:  "ResourceType",
+		Label: "resource_type",
+		Value: func(r CostRecord) string { return r.ResourceType },
+	},
+	{
+		Name:    "ResourceGroup",
+		Label:   "resource_group",
+		Aliases: []string{"ResourceGroupName"},
+		Value:   func(r CostRecord) string { return r.ResourceGroup },
+	},
+	{
+		Name:    "ResourceLocation",
+		Label:   "resource_location",
+		Aliases: []string{"Location"},
+		Value:   func(r CostRecord) string { return r.ResourceLocation },
+	},
+	{
+		Name:  "ResourceId",
+		Label: "resource_id",
+		Value: func(r CostRecord) string { return r.ResourceID },
+	},
+	{
+		// The last segment of the resource ID
+		Name:   "ResourceName",
+		Label:  "resource_name",
+		Value:  func(r CostRecord) string { return r.ResourceName },
+		Source: "ResourceId",
+	},
+	{
+		Name:  "MeterCategory",
+		Label: "meter_category",
+		Value: func(r CostRecord) string { return r.MeterCategory },
+	},
+	{
+		Name:  "MeterSubCategory",
+		Label: "meter_subcategory",
+		Value: func(r CostRecord) string { return r.MeterSubCategory },
+	},
+	{
+		Name:  "ChargeType",
+		Label: "charge_type",
+		Value: func(r CostRecord) string { return r.ChargeType },
+	},
+	{
+		Name:  "PricingModel",
+		Label: "pricing_model",
+		Value: func(r CostRecord) string { return r.PricingModel },
+	},
+}
+
+// LookupDimension finds a registered dimension by its canonical name, default label
+// or one of its aliases (case-insensitive)
+func LookupDimension(name string) (Dimension, bool) {
+	for _, d := range dimensions {
+		if strings.EqualFold(d.Name, name) || strings.EqualFold(d.Label, name) {
+			return d, true
+		}
+		for _, alias := range d.Aliases {
+			if strings.EqualFold(alias, name) {
+				return d, true
+			}
+		}
+	}
+	return Dimension{}, false
+}
+
+// Dimensions returns the canonical names of all registered dimensions
+func Dimensions() []string {
+	names := make([]string, len(dimensions))
+	for i, d := range dimensions {
+		names[i] = d.Name
+	}
+	return names
+}
+
+// SanitizeLabelName converts an arbitrary string (e.g. a tag key like "cost-center")
+// into a valid Prometheus label name
+func SanitizeLabelName(name string) string {
+	var b strings.Builder
+	for i, ch := range name {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch == '_':
+			b.WriteRune(ch)
+		case ch >= '0' && ch <= '9':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(ch)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package provider
+
+import "testing"
+
+func TestLookupDimension(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantName  string
+		wantFound bool
+	}{
+		{"canonical name", "ResourceGroup", "ResourceGroup", true},
+		{"default label", "resource_group", "ResourceGroup", true},
+		{"alias", "ResourceGroupName", "ResourceGroup", true},
+		{"case insensitive", "metercategory", "MeterCategory", true},
+		{"derived", "resource_name", "ResourceName", true},
+		{"unknown", "InvoiceId", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dim, ok := LookupDimension(tt.input)
+			if ok != tt.wantFound {
+				t.Fatalf("LookupDimension(%q) found = %v, want %v", tt.input, ok, tt.wantFound)
+			}
+			if dim.Name != tt.wantName {
+				t.Errorf("LookupDimension(%q) = %q, want %q", tt.input, dim.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestDimensionValues(t *testing.T) {
+	record := CostRecord{
+		Service:          "Storage",
+		ResourceType:     "microsoft.storage/storageaccounts",
+		ResourceGroup:    "prod-rg",
+		ResourceLocation: "westeurope",
+		ResourceID:       "/subscriptions/sub/resourcegroups/prod-rg",
+		ResourceName:     "prod-rg",
+		MeterCategory:    "Storage",
+		MeterSubCategory: "Premium SSD",
+		ChargeType:       "Usage",
+		PricingModel:     "OnDemand",
+	}
+
+	for _, name := range Dimensions() {
+		dim, _ := LookupDimension(name)
+		if dim.Value(record) == "" {
+			t.Errorf("Dimension %s does not map to a populated CostRecord field", name)
+		}
+	}
+}
+
+func TestDimensionQueryName(t *testing.T) {
+	name, _ := LookupDimension("ResourceName")
+	if !name.Derived() || name.QueryName() != "ResourceId" {
+		t.Errorf("ResourceName: derived %v, query name %q, want derived from ResourceId", name.Derived(), name.QueryName())
+	}
+	group, _ := LookupDimension("ResourceGroupName")
+	if group.Derived() || group.QueryName() != "ResourceGroup" {
+		t.Errorf("ResourceGroup: derived %v, query name %q, want queried by its own name", group.Derived(), group.QueryName())
+	}
+}
+
+func TestSanitizeLabelName(t *testing.T) {
+	tests := map[string]string{
+		"team":                   "team",
+		"cost-center":            "cost_center",
+		"app.kubernetes.io/name": "app_kubernetes_io_name",
+		"1password":              "_1password",
+		"Env":                    "Env",
+	}
+
+	for input, want := range tests {
+		if got := SanitizeLabelName(input); got != want {
+			t.Errorf("SanitizeLabelName(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
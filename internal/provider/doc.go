@@ -7,11 +7,27 @@
 // The CloudProvider interface must be implemented by each cloud-specific package:
 //
 //	type CloudProvider interface {
-//		QueryCosts(ctx context.Context) ([]CostRecord, error)
+//		QueryCosts(ctx context.Context) (QueryResult, error)
 //		Name() ProviderType
 //		AccountCount() int
 //	}
 //
+// QueryResult holds the cost records together with one AccountResult per
+// queried account (success, duration, retries, rows), so that a partial
+// failure can be reported per account rather than as a single error.
+//
+// Providers can offer optional capabilities by implementing further interfaces,
+// which the collector detects with a type assertion:
+//   - Forecaster: forecasted costs for the rest of the billing month
+//   - BudgetReader: budgets with their current and forecasted spend
+//   - MonthCostReader: month-to-date and previous month cost totals
+//   - UtilizationReader: utilization of reservations and savings plans
+//
+// API failures are reported as *Error with one of the Reason* categories, so
+// the collector can label scrape errors the same way for every provider.
+// Retrier runs API calls with the shared exponential backoff, each provider
+// supplying its own error classification and throttling hooks.
+//
 // The CostRecord structure is designed to work across all cloud providers,
 // with common fields that all providers must populate and optional fields
 // for provider-specific details:
@@ -31,9 +47,10 @@
 //		config *config.Config
 //	}
 //
-//	func (p *AzureProvider) QueryCosts(ctx context.Context) ([]provider.CostRecord, error) {
+//	func (p *AzureProvider) QueryCosts(ctx context.Context) (provider.QueryResult, error) {
 //		// Azure-specific implementation
 //		// Convert Azure API response to provider.CostRecord
+//		// and record a provider.AccountResult per subscription
 //	}
 //
 //	func (p *AzureProvider) Name() provider.ProviderType {
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Failure reasons reported in the reason label of scrape error metrics
+const (
+	ReasonAuth       = "auth"        // Credential could not authenticate
+	ReasonPermission = "permission"  // Authenticated, but missing a role assignment
+	ReasonBadRequest = "bad_request" // Request rejected as invalid (e.g. unknown grouping dimension)
+	ReasonThrottled  = "throttled"   // Rate limit exceeded
+	ReasonTransient  = "transient"   // Temporary server or network failure
+	ReasonTimeout    = "timeout"     // Request did not complete in time
+	ReasonUnknown    = "unknown"     // Error carries no classification
+)
+
+// ReasonError is implemented by provider errors that carry a failure category
+type ReasonError interface {
+	error
+	Reason() string
+}
+
+// ErrorReason returns the failure category of err, or ReasonUnknown if it has none
+// For joined errors the category of the first classified error is returned
+func ErrorReason(err error) string {
+	var re ReasonError
+	if errors.As(err, &re) {
+		return re.Reason()
+	}
+	return ReasonUnknown
+}
+
+// Error is a classified cloud API failure
+// Each provider maps its SDK errors to an Error, the categories are shared
+type Error struct {
+	Category   string // One of the Reason* categories
+	StatusCode int    // HTTP status code, 0 if no response was received
+	Err        error  // Underlying error
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s error (HTTP %d): %v", e.Category, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("%s error: %v", e.Category, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Reason implements ReasonError
+func (e *Error) Reason() string {
+	return e.Category
+}
+
+// Retryable reports whether retrying the request may succeed
+// Authentication, permission and bad request errors won't go away by retrying
+func (e *Error) Retryable() bool {
+	switch e.Category {
+	case ReasonThrottled, ReasonTransient, ReasonTimeout:
+		return !errors.Is(e.Err, context.Canceled)
+	default:
+		return false
+	}
+}
+
+// StatusReason maps an HTTP status code to a failure category
+func StatusReason(status int) string {
+	switch {
+	case status == http.StatusUnauthorized:
+		return ReasonAuth
+	case status == http.StatusForbidden:
+		return ReasonPermission
+	case status == http.StatusTooManyRequests:
+		return ReasonThrottled
+	case status == http.StatusRequestTimeout, status == http.StatusGatewayTimeout:
+		return ReasonTimeout
+	case status >= 500:
+		return ReasonTransient
+	case status >= 400:
+		return ReasonBadRequest
+	default:
+		return ReasonTransient
+	}
+}
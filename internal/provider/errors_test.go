@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+type reasonError struct{ reason string }
+
+func (e *reasonError) Error() string  { return e.reason + " failure" }
+func (e *reasonError) Reason() string { return e.reason }
+
+func TestErrorReason(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"unclassified", errors.New("boom"), ReasonUnknown},
+		{"classified", &reasonError{ReasonPermission}, ReasonPermission},
+		{"wrapped", fmt.Errorf("subscription prod: %w", &reasonError{ReasonThrottled}), ReasonThrottled},
+		{"joined", errors.Join(errors.New("boom"), &reasonError{ReasonAuth}, &reasonError{ReasonTimeout}), ReasonAuth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorReason(tt.err); got != tt.want {
+				t.Errorf("ErrorReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           *Error
+		wantRetryable bool
+	}{
+		{"throttled", &Error{Category: ReasonThrottled, Err: errors.New("slow down")}, true},
+		{"transient", &Error{Category: ReasonTransient, Err: errors.New("reset")}, true},
+		{"timeout", &Error{Category: ReasonTimeout, Err: context.DeadlineExceeded}, true},
+		{"cancelled", &Error{Category: ReasonTimeout, Err: context.Canceled}, false},
+		{"permission", &Error{Category: ReasonPermission, Err: errors.New("denied")}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Retryable(); got != tt.wantRetryable {
+				t.Errorf("Retryable() = %v, want %v", got, tt.wantRetryable)
+			}
+			if got := ErrorReason(fmt.Errorf("query: %w", tt.err)); got != tt.err.Category {
+				t.Errorf("ErrorReason() = %q, want %q", got, tt.err.Category)
+			}
+			if !errors.Is(tt.err, tt.err.Err) {
+				t.Error("Error should wrap the underlying error")
+			}
+		})
+	}
+}
+
+func TestStatusReason(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{http.StatusUnauthorized, ReasonAuth},
+		{http.StatusForbidden, ReasonPermission},
+		{http.StatusBadRequest, ReasonBadRequest},
+		{http.StatusNotFound, ReasonBadRequest},
+		{http.StatusTooManyRequests, ReasonThrottled},
+		{http.StatusRequestTimeout, ReasonTimeout},
+		{http.StatusGatewayTimeout, ReasonTimeout},
+		{http.StatusInternalServerError, ReasonTransient},
+		{http.StatusServiceUnavailable, ReasonTransient},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			if got := StatusReason(tt.status); got != tt.want {
+				t.Errorf("StatusReason(%d) = %q, want %q", tt.status, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package provider
+
+import "context"
+
+// Confidence bounds of a forecast
+const (
+	ForecastBoundExpected = "expected" // Most likely value
+	ForecastBoundLower    = "lower"    // Lower bound of the confidence interval
+	ForecastBoundUpper    = "upper"    // Upper bound of the confidence interval
+)
+
+// Forecaster is an optional capability of providers that can forecast costs
+// The collector checks for it with a type assertion and refreshes forecasts on
+// their own schedule
+type Forecaster interface {
+	// QueryForecast retrieves forecasted daily costs for the rest of the current billing month
+	// The result carries the outcome of every queried account, also when an error is returned
+	QueryForecast(ctx context.Context) (ForecastResult, error)
+}
+
+// ForecastResult is the outcome of forecasting the costs of all accounts of a provider
+type ForecastResult struct {
+	Records  []ForecastRecord // Forecast records of all successful accounts
+	Accounts []AccountResult  // Per-account outcomes, in query order
+}
+
+// ForecastRecord is the forecasted cost of a single day
+// Date is the forecasted day; Cost is the forecast at the given confidence bound
+type ForecastRecord struct {
+	CostRecord
+	Bound string // One of the ForecastBound* constants
+}
@@ -0,0 +1,20 @@
+package provider
+
+import "context"
+
+// MonthCostReader is an optional capability of providers that can report month-level cost totals
+// The collector checks for it with a type assertion and refreshes the totals on
+// their own schedule
+type MonthCostReader interface {
+	// QueryMonthCosts retrieves the costs of the current month so far and of the previous month
+	// The result carries the outcome of every queried account, also when an error is returned
+	QueryMonthCosts(ctx context.Context) (MonthCostResult, error)
+}
+
+// MonthCostResult is the outcome of querying the month totals of all accounts of a provider
+// Records carry no date; each one is a total over its period
+type MonthCostResult struct {
+	MonthToDate   []CostRecord    // Costs from the first of the current month until today
+	PreviousMonth []CostRecord    // Costs of the previous calendar month
+	Accounts      []AccountResult // Per-account outcomes, in query order
+}
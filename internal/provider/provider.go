@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"time"
 )
 
 // ProviderType represents a cloud provider
@@ -12,20 +13,39 @@ const (
 	ProviderAzure ProviderType = "azure"
 	ProviderAWS   ProviderType = "aws"
 	ProviderGCP   ProviderType = "gcp"
+	ProviderFocus ProviderType = "focus" // FOCUS files of any vendor
 )
 
 // CloudProvider is the interface that all cloud cost providers must implement
 type CloudProvider interface {
 	// QueryCosts retrieves cost data from the cloud provider
-	QueryCosts(ctx context.Context) ([]CostRecord, error)
+	// The result carries the outcome of every queried account, also when an error is returned
+	QueryCosts(ctx context.Context) (QueryResult, error)
 
-	// Name returns the provider name (azure, aws, gcp, etc.)
+	// Name returns the provider name (azure, aws, gcp, focus, etc.)
 	Name() ProviderType
 
 	// AccountCount returns the number of accounts/subscriptions being monitored
 	AccountCount() int
 }
 
+// QueryResult is the outcome of querying all accounts of a provider
+type QueryResult struct {
+	Records  []CostRecord    // Cost records of all successful accounts
+	Usage    []CostRecord    // Usage records per meter if queried apart from Records (nil: usage is taken from Records)
+	Accounts []AccountResult // Per-account outcomes, in query order
+}
+
+// AccountResult is the outcome of querying a single account
+type AccountResult struct {
+	AccountID   string
+	AccountName string
+	Err         error         // nil if the account was queried successfully
+	Duration    time.Duration // Wall time spent on the account, including retries
+	Retries     int           // Number of retried API calls
+	Rows        int           // Number of cost records returned
+}
+
 // CostRecord represents a single cost entry from any cloud provider
 // This is a generic structure that works across all cloud providers
 type CostRecord struct {
@@ -37,6 +57,8 @@ type CostRecord struct {
 	Service     string  // Service name (Storage, Compute, etc.)
 	Cost        float64 // Cost amount
 	Currency    string  // Currency symbol
+	CostType    string  // Cost basis: actual or amortized
+	TenantID    string  // Directory the account belongs to (Azure tenant ID, if known)
 
 	// Optional detailed fields (may be empty for some providers)
 	ResourceType     string // Resource type (microsoft.storage/storageaccounts, etc.)
@@ -50,4 +72,12 @@ type CostRecord struct {
 	MeterSubCategory string // Azure-specific: Meter subcategory
 	ChargeType       string // Usage, Purchase, Refund, etc.
 	PricingModel     string // OnDemand, Reservation, Spot, etc.
+
+	// Usage fields (only populated when usage metrics are enabled)
+	Meter         string  // Meter the usage is billed by (Azure meter, AWS usage type, GCP SKU)
+	UnitOfMeasure string  // Unit of UsageQuantity (e.g. "1 Hour", "10 GB")
+	UsageQuantity float64 // Consumed quantity in UnitOfMeasure
+
+	// Tags holds resource tag values keyed by tag name (only for configured tag groupings)
+	Tags map[string]string
 }
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// API retry constants shared by all providers
+const (
+	// MaxRetryElapsedTime is the maximum time to spend retrying a failed API call
+	MaxRetryElapsedTime = 2 * time.Minute
+
+	// InitialRetryInterval is the initial backoff interval for retries
+	InitialRetryInterval = 1 * time.Second
+
+	// MaxRetryInterval is the maximum backoff interval between retries
+	MaxRetryInterval = 30 * time.Second
+)
+
+// Retrier runs API calls with exponential backoff
+// Only Classify is required, the other hooks let a provider plug in its throttling and logging
+type Retrier struct {
+	Classify   func(error) *Error                    // Maps a failed attempt to its category
+	Wait       func(context.Context) error           // Called before each attempt, e.g. to honour a shared rate limit
+	RetryAfter func(error) time.Duration             // Server-requested delay of a failed attempt
+	OnRetry    func(err *Error, delay time.Duration) // Called for each failure that will be retried
+}
+
+// Retry runs query until it succeeds, counting retried calls in retries
+// Errors that retrying can't fix end the retries immediately
+func (r Retrier) Retry(ctx context.Context, retries *int, query func() error) error {
+	// Configure exponential backoff, stretched to server-requested delays
+	exp := backoff.NewExponentialBackOff()
+	exp.InitialInterval = InitialRetryInterval
+	exp.MaxInterval = MaxRetryInterval
+	exp.MaxElapsedTime = MaxRetryElapsedTime
+	bo := &retryAfterBackOff{BackOff: exp}
+
+	attempts := 0
+	operation := func() error {
+		if attempts > 0 {
+			*retries++
+		}
+		attempts++
+
+		if r.Wait != nil {
+			if err := r.Wait(ctx); err != nil {
+				return backoff.Permanent(r.Classify(err))
+			}
+		}
+
+		if err := query(); err != nil {
+			qerr := r.Classify(err)
+			if !qerr.Retryable() {
+				// Retrying won't fix missing permissions or an invalid query
+				return backoff.Permanent(qerr)
+			}
+
+			if r.RetryAfter != nil {
+				bo.delay = r.RetryAfter(err)
+			}
+			if r.OnRetry != nil {
+				r.OnRetry(qerr, bo.delay)
+			}
+			return qerr
+		}
+		return nil
+	}
+
+	return backoff.Retry(operation, backoff.WithContext(bo, ctx))
+}
+
+// retryAfterBackOff honours server-requested delays on top of a backoff policy
+// The wrapped policy still decides when to give up
+type retryAfterBackOff struct {
+	backoff.BackOff
+	delay time.Duration // Delay requested by the last failed attempt
+}
+
+// NextBackOff returns the larger of the server-requested and the computed delay
+func (b *retryAfterBackOff) NextBackOff() time.Duration {
+	next := b.BackOff.NextBackOff()
+	if next == backoff.Stop {
+		return backoff.Stop
+	}
+	if b.delay > next {
+		next = b.delay
+	}
+	b.delay = 0
+	return next
+}
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// classify is a stand-in for a provider's error classification
+func classify(err error) *Error {
+	var classified *Error
+	if errors.As(err, &classified) {
+		return classified
+	}
+	return &Error{Category: ReasonTransient, Err: err}
+}
+
+func TestRetry(t *testing.T) {
+	attempts, retries, notified := 0, 0, 0
+	r := Retrier{
+		Classify:   classify,
+		RetryAfter: func(error) time.Duration { return time.Millisecond },
+		OnRetry:    func(*Error, time.Duration) { notified++ },
+	}
+
+	err := r.Retry(context.Background(), &retries, func() error {
+		attempts++
+		if attempts < 2 {
+			return errors.New("connection reset")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	if attempts != 2 || retries != 1 || notified != 1 {
+		t.Errorf("attempts = %d, retries = %d, notified = %d, want 2, 1, 1", attempts, retries, notified)
+	}
+}
+
+func TestRetry_PermanentError(t *testing.T) {
+	attempts, retries := 0, 0
+	r := Retrier{Classify: classify}
+
+	err := r.Retry(context.Background(), &retries, func() error {
+		attempts++
+		return &Error{Category: ReasonPermission, Err: errors.New("denied")}
+	})
+	if ErrorReason(err) != ReasonPermission {
+		t.Errorf("ErrorReason() = %q, want %q", ErrorReason(err), ReasonPermission)
+	}
+	if attempts != 1 || retries != 0 {
+		t.Errorf("attempts = %d, retries = %d, want a single attempt", attempts, retries)
+	}
+}
+
+func TestRetry_WaitFailure(t *testing.T) {
+	queried := false
+	r := Retrier{
+		Classify: classify,
+		Wait:     func(context.Context) error { return &Error{Category: ReasonTimeout, Err: context.Canceled} },
+	}
+
+	var retries int
+	err := r.Retry(context.Background(), &retries, func() error {
+		queried = true
+		return nil
+	})
+	if err == nil || queried {
+		t.Errorf("Retry() error = %v, queried = %v, want the wait error before any query", err, queried)
+	}
+}
+
+func TestRetryAfterBackOff(t *testing.T) {
+	bo := &retryAfterBackOff{BackOff: backoff.NewConstantBackOff(time.Second)}
+
+	bo.delay = 5 * time.Second
+	if got := bo.NextBackOff(); got != 5*time.Second {
+		t.Errorf("NextBackOff() = %v, want the server-requested 5s", got)
+	}
+	if got := bo.NextBackOff(); got != time.Second {
+		t.Errorf("NextBackOff() = %v, want the computed 1s once the delay is used", got)
+	}
+
+	stop := &retryAfterBackOff{BackOff: &backoff.StopBackOff{}, delay: time.Second}
+	if got := stop.NextBackOff(); got != backoff.Stop {
+		t.Errorf("NextBackOff() = %v, want Stop from the wrapped policy", got)
+	}
+}
@@ -0,0 +1,11 @@
+// Package registry creates the cost providers configured in the providers list.
+//
+// Every provider type has a Factory. New creates the providers in
+// configuration order, so that the collector can refresh each of them on its
+// own schedule and export their costs side by side:
+//
+//	entries, err := registry.New(ctx, cfg, log)
+//	for _, e := range entries {
+//		log.Info("Provider initialized", "provider", e.Provider.Name())
+//	}
+package registry
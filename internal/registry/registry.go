@@ -0,0 +1,74 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/aws"
+	"github.com/zgpcy/azure-cost-exporter/internal/azure"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/focus"
+	"github.com/zgpcy/azure-cost-exporter/internal/gcp"
+	"github.com/zgpcy/azure-cost-exporter/internal/logger"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+// Factory creates the cost provider described by pc
+// cfg holds the shared settings (date range, grouping, Azure settings, ...)
+type Factory func(ctx context.Context, cfg *config.Config, pc config.ProviderConfig, log *logger.Logger) (provider.CloudProvider, error)
+
+// Factories maps every configurable provider type to its factory
+var Factories = map[provider.ProviderType]Factory{
+	provider.ProviderAzure: newAzure,
+	provider.ProviderAWS:   newAWS,
+	provider.ProviderGCP:   newGCP,
+	provider.ProviderFocus: newFocus,
+}
+
+// Entry is a created provider together with its configuration
+type Entry struct {
+	Config   config.ProviderConfig
+	Provider provider.CloudProvider
+}
+
+// New creates the configured providers in configuration order
+// Fails on the first provider that cannot be created
+func New(ctx context.Context, cfg *config.Config, log *logger.Logger) ([]Entry, error) {
+	entries := make([]Entry, 0, len(cfg.Providers))
+	for _, pc := range cfg.Providers {
+		factory, ok := Factories[pc.Type]
+		if !ok {
+			return nil, fmt.Errorf("unsupported provider type %q", pc.Type)
+		}
+		p, err := factory(ctx, cfg, pc, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s provider: %w", pc.Type, err)
+		}
+		entries = append(entries, Entry{Config: pc, Provider: p})
+	}
+	return entries, nil
+}
+
+// newAzure creates the Azure Cost Management provider from the top-level Azure settings
+// With export settings the provider reads scheduled exports instead of querying the API
+func newAzure(_ context.Context, cfg *config.Config, pc config.ProviderConfig, log *logger.Logger) (provider.CloudProvider, error) {
+	if pc.Export != nil {
+		return azure.NewExportClient(cfg, *pc.Export, log), nil
+	}
+	return azure.NewClient(cfg, log)
+}
+
+// newAWS creates the AWS Cost Explorer provider
+func newAWS(ctx context.Context, cfg *config.Config, pc config.ProviderConfig, log *logger.Logger) (provider.CloudProvider, error) {
+	return aws.NewClient(ctx, cfg, *pc.AWS, log)
+}
+
+// newGCP creates the GCP billing export provider
+func newGCP(_ context.Context, cfg *config.Config, pc config.ProviderConfig, log *logger.Logger) (provider.CloudProvider, error) {
+	return gcp.NewClient(cfg, *pc.GCP, log), nil
+}
+
+// newFocus creates the FOCUS file provider
+func newFocus(_ context.Context, cfg *config.Config, pc config.ProviderConfig, log *logger.Logger) (provider.CloudProvider, error) {
+	return focus.NewClient(cfg, *pc.Focus, log), nil
+}
@@ -0,0 +1,69 @@
+package registry
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/zgpcy/azure-cost-exporter/internal/azure"
+	"github.com/zgpcy/azure-cost-exporter/internal/config"
+	"github.com/zgpcy/azure-cost-exporter/internal/logger"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
+)
+
+func TestNew(t *testing.T) {
+	cfg := &config.Config{
+		Providers: []config.ProviderConfig{
+			{Type: provider.ProviderGCP, GCP: &config.GCPConfig{BillingAccountID: "billing-1", Path: filepath.Join(t.TempDir(), "*.json")}},
+			{Type: provider.ProviderAWS, AWS: &config.AWSConfig{Region: "us-east-1", AccountID: "123456789012"}},
+		},
+	}
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	entries, err := New(context.Background(), cfg, logger.New("error"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Got %d providers, want 2", len(entries))
+	}
+	for i, want := range []provider.ProviderType{provider.ProviderGCP, provider.ProviderAWS} {
+		if entries[i].Provider.Name() != want || entries[i].Config.Type != want {
+			t.Errorf("Provider %d = %s (config %s), want %s", i, entries[i].Provider.Name(), entries[i].Config.Type, want)
+		}
+	}
+}
+
+// TestNew_AzureExport tests that azure providers with export settings read exports instead of the API
+func TestNew_AzureExport(t *testing.T) {
+	cfg := &config.Config{
+		Providers: []config.ProviderConfig{
+			{Type: provider.ProviderAzure, Export: &config.AzureExportConfig{Path: t.TempDir(), CostType: config.CostTypeActual}},
+		},
+	}
+
+	entries, err := New(context.Background(), cfg, logger.New("error"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, ok := entries[0].Provider.(*azure.ExportClient); !ok {
+		t.Errorf("Provider = %T, want *azure.ExportClient", entries[0].Provider)
+	}
+}
+
+func TestNew_UnsupportedType(t *testing.T) {
+	cfg := &config.Config{Providers: []config.ProviderConfig{{Type: "oracle"}}}
+	if _, err := New(context.Background(), cfg, logger.New("error")); err == nil {
+		t.Error("New() error = nil, want unsupported type error")
+	}
+}
+
+// TestFactories tests that every configurable provider type has a factory
+func TestFactories(t *testing.T) {
+	for _, name := range []provider.ProviderType{provider.ProviderAzure, provider.ProviderAWS, provider.ProviderGCP, provider.ProviderFocus} {
+		if Factories[name] == nil {
+			t.Errorf("No factory for provider %s", name)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	_ "embed"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"github.com/zgpcy/azure-cost-exporter/internal/collector"
 	"github.com/zgpcy/azure-cost-exporter/internal/config"
 	"github.com/zgpcy/azure-cost-exporter/internal/logger"
+	"github.com/zgpcy/azure-cost-exporter/internal/provider"
 )
 
 //go:embed templates/index.html
@@ -133,28 +135,48 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// readyResponse is the JSON body of the /ready endpoint
+type readyResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Reason  string `json:"reason,omitempty"` // Failure category (auth, permission, throttled, ...)
+}
+
 // handleReady handles readiness check requests (returns 200 only when data is loaded)
+// With several providers the exporter is ready while any of them is; the errors of
+// the failing ones are still reported
 func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
+	resp := readyResponse{Status: "ready"}
+	if err := s.collector.LastError(); err != nil {
+		resp.Error = err.Error()
+		resp.Reason = provider.ErrorReason(err)
+	}
 
 	if !s.collector.IsReady() {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		if _, err := w.Write([]byte(`{"status":"not ready","message":"waiting for initial data fetch"}`)); err != nil {
-			s.logger.Error("Failed to write ready response", "error", err)
+		resp.Status = "not ready"
+		if resp.Error == "" {
+			resp.Message = "waiting for initial data fetch"
 		}
+		s.writeReady(w, http.StatusServiceUnavailable, resp)
 		return
 	}
 
-	if err := s.collector.LastError(); err != nil {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		if _, writeErr := fmt.Fprintf(w, `{"status":"not ready","error":"%s"}`, err.Error()); writeErr != nil {
-			s.logger.Error("Failed to write ready response", "error", writeErr)
-		}
+	s.writeReady(w, http.StatusOK, resp)
+}
+
+// writeReady writes a readiness response as JSON
+func (s *Server) writeReady(w http.ResponseWriter, status int, resp readyResponse) {
+	body, err := json.Marshal(resp)
+	if err != nil {
+		s.logger.Error("Failed to encode ready response", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	if _, err := w.Write([]byte(`{"status":"ready"}`)); err != nil {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(body); err != nil {
 		s.logger.Error("Failed to write ready response", "error", err)
 	}
 }
@@ -2,7 +2,9 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -34,11 +36,11 @@ type mockCloudProvider struct {
 	accountCount int
 }
 
-func (m *mockCloudProvider) QueryCosts(ctx context.Context) ([]provider.CostRecord, error) {
+func (m *mockCloudProvider) QueryCosts(ctx context.Context) (provider.QueryResult, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.queryCalls++
-	return m.records, m.err
+	return provider.QueryResult{Records: m.records}, m.err
 }
 
 func (m *mockCloudProvider) Name() provider.ProviderType {
@@ -278,6 +280,102 @@ func TestHandleReady_WithError(t *testing.T) {
 	}
 }
 
+// reasonError is an error carrying a failure category
+type reasonError struct{ reason string }
+
+func (e *reasonError) Error() string  { return "missing Cost Management Reader role" }
+func (e *reasonError) Reason() string { return e.reason }
+
+// TestHandleReady_ErrorReason tests that /ready reports the failure reason
+func TestHandleReady_ErrorReason(t *testing.T) {
+	cfg := &config.Config{HTTPPort: 8080, RefreshInterval: 3600}
+	mockClient := &mockCloudProvider{
+		err: fmt.Errorf("all 1 subscriptions failed: %w", &reasonError{reason: provider.ReasonPermission}),
+	}
+	collector := collector.NewCostCollector(mockClient, cfg, testLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	collector.StartBackgroundRefresh(ctx)
+
+	server := NewServer(cfg, collector, testLogger())
+
+	req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+	w := httptest.NewRecorder()
+	server.handleReady(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Status code: got %v, want %v", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+
+	var body struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Response should be valid JSON: %v", err)
+	}
+	if body.Status != "not ready" || body.Reason != provider.ReasonPermission {
+		t.Errorf("Got status %q reason %q, want \"not ready\" with reason %q", body.Status, body.Reason, provider.ReasonPermission)
+	}
+	if !strings.Contains(body.Error, "Cost Management Reader") {
+		t.Errorf("Error should describe the failure, got %q", body.Error)
+	}
+}
+
+// TestHandleReady_ProviderFailure tests that /ready succeeds while one of several providers
+// has data and still reports the error of the failing provider
+func TestHandleReady_ProviderFailure(t *testing.T) {
+	cfg := &config.Config{HTTPPort: 8080, RefreshInterval: 3600}
+	azureMock := &mockCloudProvider{
+		providerType: provider.ProviderAzure,
+		records: []provider.CostRecord{
+			{Date: time.Now().Format("2006-01-02"), AccountName: "test", AccountID: "123", Service: "Storage", Cost: 10.0, Currency: "$"},
+		},
+	}
+	awsMock := &mockCloudProvider{
+		providerType: provider.ProviderAWS,
+		err:          fmt.Errorf("cost query failed: %w", &reasonError{reason: provider.ReasonPermission}),
+	}
+	collector := collector.NewMultiCostCollector([]collector.ProviderSchedule{
+		{Provider: azureMock, RefreshInterval: time.Hour},
+		{Provider: awsMock, RefreshInterval: time.Hour},
+	}, cfg, testLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	collector.StartBackgroundRefresh(ctx)
+
+	server := NewServer(cfg, collector, testLogger())
+
+	req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+	w := httptest.NewRecorder()
+	server.handleReady(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Status code: got %v, want %v", resp.StatusCode, http.StatusOK)
+	}
+
+	var body struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Response should be valid JSON: %v", err)
+	}
+	if body.Status != "ready" || body.Reason != provider.ReasonPermission || !strings.Contains(body.Error, "aws:") {
+		t.Errorf("Got status %q error %q reason %q, want ready with the aws error", body.Status, body.Error, body.Reason)
+	}
+}
+
 // TestHandleIndex_NotReady tests the index page when collector is not ready
 func TestHandleIndex_NotReady(t *testing.T) {
 	cfg := &config.Config{